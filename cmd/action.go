@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// Bulk actions the picker applies to its selection.
+const (
+	actionDismiss = "dismiss"
+	actionSnooze  = "snooze"
+	actionRead    = "read"
+	actionTabs    = "tabs"
+	actionPin     = "pin"
+	actionRaise   = "raise"
+	actionLower   = "lower"
+)
+
+var bulkActions = []string{actionDismiss, actionSnooze, actionRead, actionTabs, actionPin, actionRaise, actionLower}
+
+// bulkHeader is the key help for multi-select, shared by both pickers.
+const bulkHeader = "ctrl-space=select  alt-a=all  alt-d=dismiss  alt-s=snooze  alt-h=snooze 1h\n" +
+	"alt-r=read  alt-t=tabs  alt-p=pin  alt-k/alt-j=raise/lower priority"
+
+// pickerSnoozeFor is how long the picker's timed snooze key hides entries.
+const pickerSnoozeFor = time.Hour
+
+// snoozeSession hides a session until its next event or, when d > 0, for d.
+func snoozeSession(opts Options, sessionID string, d time.Duration) error {
+	return opts.Store.Update(sessionID, func(e *queue.Entry) error {
+		e.Snoozed = true
+		e.SnoozeUntil = time.Time{}
+		if d > 0 {
+			e.SnoozeUntil = opts.TimeNow().Add(d)
+		}
+		return nil
+	})
+}
+
+// setPriority updates a session's priority with fn.
+func setPriority(opts Options, sessionID string, fn func(string) string) error {
+	return opts.Store.Update(sessionID, func(e *queue.Entry) error {
+		e.Priority = fn(e.Priority)
+		return nil
+	})
+}
+
+// applyAction runs a bulk action on each session. snoozeFor limits a snooze
+// to a duration; zero snoozes until the next event. Sessions that left the
+// queue in the meantime are skipped; other failures are collected so one
+// bad entry does not stop the rest.
+func applyAction(opts Options, action string, sessionIDs []string, snoozeFor time.Duration) error {
+	if !slices.Contains(bulkActions, action) {
+		return fmt.Errorf("unknown action %q (use %s)", action, strings.Join(bulkActions, ", "))
+	}
+	var errs []error
+	for _, id := range sessionIDs {
+		sf, err := opts.Store.ReadSession(id)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && sf.Current == nil) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		e := sf.Current
+		queue.Debugf("ACTION %s session=%s", action, id)
+
+		switch action {
+		case actionDismiss:
+			err = opts.Store.Remove(id)
+			if err == nil {
+				opts.Journal.Record(queue.OpRemove, e, queue.ReasonDismiss, opts.TimeNow())
+			}
+		case actionSnooze:
+			err = snoozeSession(opts, id, snoozeFor)
+		case actionRead:
+			err = opts.Store.Update(id, func(e *queue.Entry) error {
+				e.Read = true
+				return nil
+			})
+		case actionPin:
+			err = setPriority(opts, id, func(p string) string {
+				if p == queue.PriorityPinned {
+					return ""
+				}
+				return queue.PriorityPinned
+			})
+		case actionRaise:
+			err = setPriority(opts, id, func(p string) string { return queue.ShiftPriority(p, -1) })
+		case actionLower:
+			err = setPriority(opts, id, func(p string) string { return queue.ShiftPriority(p, 1) })
+		case actionTabs:
+			if e.WindowID == "" {
+				continue
+			}
+			t, pane, paneErr := entryPane(e)
+			if paneErr == nil {
+				paneErr = t.OpenTab(pane, e.CWD)
+			}
+			if paneErr != nil {
+				err = fmt.Errorf("%s: %w", id, paneErr)
+			}
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func newActionCmd(opts Options) *cobra.Command {
+	var snoozeFor string
+	cmd := &cobra.Command{
+		Use:    "_action <action> <session_id>...",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return bulkActions, cobra.ShellCompDirectiveNoFileComp
+			}
+			return sessionIDCompletions(opts.Store, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var d time.Duration
+			if snoozeFor != "" {
+				var err error
+				if d, err = queue.ParseDuration(snoozeFor); err != nil {
+					return err
+				}
+			}
+			return applyAction(opts, args[0], args[1:], d)
+		},
+	}
+	cmd.Flags().StringVar(&snoozeFor, "for", "", "Snooze for a duration instead of until the next event")
+	_ = cmd.RegisterFlagCompletionFunc("for", cobra.NoFileCompletions)
+	return cmd
+}
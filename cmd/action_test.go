@@ -0,0 +1,157 @@
+package cmd_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func TestAction_Dismiss(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	seedEntry(t, "s2", "/tmp/b", "idle_prompt", 2)
+	seedEntry(t, "s3", "/tmp/c", "idle_prompt", 3)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "dismiss", "s1", "s3", "gone"); err != nil {
+		t.Fatalf("_action dismiss: %v", err)
+	}
+	if n := entryCount(t); n != 1 {
+		t.Fatalf("entryCount = %d, want 1", n)
+	}
+
+	records, err := testJournal().Read(queue.JournalFilter{Event: queue.OpRemove})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 remove records, got %d", len(records))
+	}
+	for _, r := range records {
+		if r.Reason != queue.ReasonDismiss {
+			t.Errorf("reason = %q, want %q", r.Reason, queue.ReasonDismiss)
+		}
+	}
+}
+
+func TestAction_SnoozeHidesEntries(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/snoozed", "idle_prompt", 1)
+	seedEntry(t, "s2", "/tmp/visible", "idle_prompt", 2)
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "snooze", "s1"); err != nil {
+		t.Fatalf("_action snooze: %v", err)
+	}
+	if n := entryCount(t); n != 2 {
+		t.Errorf("snoozed entry should stay in the store, entryCount = %d", n)
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	out := stdout.String()
+	if strings.Contains(out, "/tmp/snoozed") || !strings.Contains(out, "/tmp/visible") {
+		t.Errorf("list should hide the snoozed entry:\n%s", out)
+	}
+
+	// The session's next event brings it back.
+	seedEntry(t, "s1", "/tmp/snoozed", "permission_prompt", 1)
+	stdout.Reset()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "/tmp/snoozed") {
+		t.Errorf("new event should unsnooze the entry:\n%s", stdout.String())
+	}
+}
+
+func TestAction_ReadSortsAfterUnread(t *testing.T) {
+	setupQueueDir(t)
+	seedEntryAtTime(t, "old", "/tmp/old", "idle_prompt", 1, -120)
+	seedEntryAtTime(t, "new", "/tmp/new", "idle_prompt", 2, -10)
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "read", "old"); err != nil {
+		t.Fatalf("_action read: %v", err)
+	}
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(lines[1], "/tmp/new") {
+		t.Errorf("unread entry should come first, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "IDLE✓") {
+		t.Errorf("read entry should be marked, got %q", lines[2])
+	}
+}
+
+func TestAction_Unknown(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "explode", "s1")
+	if err == nil || !strings.Contains(err.Error(), `unknown action "explode"`) {
+		t.Fatalf("expected unknown action error, got %v", err)
+	}
+}
+
+func TestFirst_SkipsSnoozed(t *testing.T) {
+	setupQueueDir(t)
+	seedEntryNoWindow(t, "s1", "/tmp/a", "permission_prompt", 1)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "snooze", "s1"); err != nil {
+		t.Fatalf("_action snooze: %v", err)
+	}
+	// Jumping would touch the entry; a skipped entry keeps its timestamp.
+	before, _ := testStore().ReadSession("s1")
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "first"); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	after, _ := testStore().ReadSession("s1")
+	if !after.Current.Timestamp.Equal(before.Current.Timestamp) || !after.Current.Snoozed {
+		t.Errorf("first should ignore snoozed entries: %+v", after.Current)
+	}
+}
+
+func TestAction_TabsKitty(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	opts, _, _ := testOptions()
+	seedEntry(t, "s1", "/home/me/git/api", "idle_prompt", 1)
+	setPane(t, "s1", "", "7", "unix:/tmp/kitty-1")
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "tabs", "s1"); err != nil {
+		t.Fatalf("_action tabs: %v", err)
+	}
+	want := []string{"@ --to unix:/tmp/kitty-1 launch --type=tab --cwd=/home/me/git/api --tab-title=api --match window_id:7"}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("kitty calls = %q, want %q", got, want)
+	}
+}
+
+func TestAction_TabsTmux(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeCLIOutput(t, "tmux", "@2")
+	opts, _, _ := testOptions()
+	seedEntry(t, "s1", "/home/me/git/api", "idle_prompt", 1)
+	setPane(t, "s1", "tmux", "%3", "/tmp/tmux-1000/default")
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "tabs", "s1"); err != nil {
+		t.Fatalf("_action tabs: %v", err)
+	}
+	want := []string{
+		"-S /tmp/tmux-1000/default display-message -p -t %3 #{window_id}",
+		"-S /tmp/tmux-1000/default new-window -a -t @2 -c /home/me/git/api -n api",
+	}
+	if got := calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tmux calls = %q, want %q", got, want)
+	}
+}
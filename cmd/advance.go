@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+// scheduleAdvance starts a background _advance from the window of the
+// session that was just answered, when auto-advance is enabled and
+// do-not-disturb is off.
+func scheduleAdvance(opts Options, fromWID string) {
+	cfg := queue.ReadConfig()
+	if !cfg.AutoAdvance.Enabled || opts.Spawn == nil {
+		return
+	}
+	if cfg.DNDActive(opts.TimeNow()) {
+		queue.Debugf("ADVANCE skip: do not disturb")
+		return
+	}
+	delay := cfg.AutoAdvance.DelayDuration()
+	if err := opts.Spawn("_advance", "--from", fromWID, "--delay", delay.String()); err != nil {
+		queue.Debugf("ADVANCE spawn failed: %v", err)
+	}
+}
+
+// advanceTarget returns the first session needing attention in picker
+// order, other than the one at from.
+func advanceTarget(opts Options, from paneLocation) (*queue.Entry, error) {
+	entries, err := opts.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	var pending []*queue.Entry
+	for _, e := range visibleEntries(entries, opts.TimeNow()) {
+		if queue.NeedsAttention(e.Event) && e.WindowID != "" && !from.holds(e) {
+			pending = append(pending, e)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	sortForPicker(pending, opts.Branches)
+	return pending[0], nil
+}
+
+// focusedAt reports whether the pane at loc still has the focus of its
+// terminal. When that cannot be told, the user may be anywhere, so it
+// reports false.
+func focusedAt(loc paneLocation) bool {
+	t := terminal.ByName(loc.terminal)
+	if t == nil {
+		return false
+	}
+	id, err := t.Focused(loc.addr)
+	if err != nil {
+		queue.Debugf("ADVANCE focus query failed: %v", err)
+		return false
+	}
+	return id == loc.id
+}
+
+func newAdvanceCmd(opts Options) *cobra.Command {
+	var from string
+	var delay time.Duration
+
+	cmd := &cobra.Command{
+		Use:    "_advance",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			time.Sleep(delay)
+
+			// The config may have changed while we waited.
+			cfg := queue.ReadConfig()
+			if !cfg.AutoAdvance.Enabled || cfg.DNDActive(opts.TimeNow()) {
+				queue.Debugf("ADVANCE skip: disabled or do not disturb")
+				return nil
+			}
+			loc := currentLocation(from)
+			if !focusedAt(loc) {
+				queue.Debugf("ADVANCE skip: focus moved away from %s", loc.id)
+				return nil
+			}
+			target, err := advanceTarget(opts, loc)
+			if err != nil || target == nil {
+				return err
+			}
+			queue.Debugf("ADVANCE from=%s session=%s", from, target.SessionID)
+			return jumpToEntry(opts, target)
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Window or pane ID of the session just answered")
+	cmd.Flags().DurationVar(&delay, "delay", 0, "Wait before focusing the next session")
+	_ = cmd.RegisterFlagCompletionFunc("from", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("delay", cobra.NoFileCompletions)
+	return cmd
+}
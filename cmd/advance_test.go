@@ -0,0 +1,127 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// popWithSpawn runs pop for sess-a with auto-advance configured by cfg and
+// returns the arguments of every spawned process.
+func popWithSpawn(t *testing.T, cfg queue.Config) [][]string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KITTY_WINDOW_ID", "42")
+
+	opts, _, _ := testOptionsWithStdin(`{"session_id":"sess-a","cwd":"/tmp/a","hook_event_name":"UserPromptSubmit"}`)
+	var spawned [][]string
+	opts.Spawn = func(args ...string) error {
+		spawned = append(spawned, args)
+		return nil
+	}
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "pop"); err != nil {
+		t.Fatalf("pop: %v", err)
+	}
+	return spawned
+}
+
+func TestPop_AutoAdvanceAfterAnswer(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "sess-a", "/tmp/a", "permission_prompt", 1)
+
+	spawned := popWithSpawn(t, queue.Config{AutoAdvance: queue.AutoAdvance{Enabled: true, Delay: "3s"}})
+	if len(spawned) != 1 {
+		t.Fatalf("expected one spawn, got %q", spawned)
+	}
+	if got := strings.Join(spawned[0], " "); got != "_advance --from 42 --delay 3s" {
+		t.Errorf("spawned %q", got)
+	}
+}
+
+func TestPop_AutoAdvanceSkipped(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		cfg   queue.Config
+	}{
+		{"disabled", "permission_prompt", queue.Config{}},
+		{"do not disturb", "permission_prompt", queue.Config{AutoAdvance: queue.AutoAdvance{Enabled: true}, DND: true}},
+		{"not answering a prompt", "working", queue.Config{AutoAdvance: queue.AutoAdvance{Enabled: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupQueueDir(t)
+			seedEntry(t, "sess-a", "/tmp/a", tt.event, 1)
+			if spawned := popWithSpawn(t, tt.cfg); len(spawned) != 0 {
+				t.Errorf("expected no spawn, got %q", spawned)
+			}
+		})
+	}
+}
+
+func TestAdvance_FocusesNextWaitingSession(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{AutoAdvance: queue.AutoAdvance{Enabled: true}}); err != nil {
+		t.Fatal(err)
+	}
+	calls := fakeCLIOutput(t, "kitty", kittyFocusedLs("1"))
+	seedWindow(t, "answered", "1", "permission_prompt", -600)
+	seedWindow(t, "waiting", "2", "idle_prompt", -300)
+	seedWindow(t, "busy", "3", "working", -900)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_advance", "--from", "1"); err != nil {
+		t.Fatalf("_advance: %v", err)
+	}
+	if got := calls(); len(got) != 2 || got[0] != "@ ls" || !strings.HasSuffix(got[1], "focus-window --match id:2") {
+		t.Errorf("kitty calls = %q, want ls then focus of window 2", got)
+	}
+}
+
+// kittyFocusedLs is kitty @ ls output with window wid focused.
+func kittyFocusedLs(wid string) string {
+	return `[{"is_focused":true,"tabs":[{"is_focused":true,"windows":[{"id":` + wid + `,"is_focused":true}]}]}]`
+}
+
+func TestAdvance_FocusMovedWhileWaiting(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{AutoAdvance: queue.AutoAdvance{Enabled: true}}); err != nil {
+		t.Fatal(err)
+	}
+	calls := fakeCLIOutput(t, "kitty", kittyFocusedLs("5"))
+	seedWindow(t, "answered", "1", "permission_prompt", -600)
+	seedWindow(t, "waiting", "2", "idle_prompt", -300)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_advance", "--from", "1"); err != nil {
+		t.Fatalf("_advance: %v", err)
+	}
+	if got := calls(); len(got) != 1 || got[0] != "@ ls" {
+		t.Errorf("kitty calls = %q, want only ls", got)
+	}
+}
+
+func TestAdvance_DNDTurnedOnWhileWaiting(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{AutoAdvance: queue.AutoAdvance{Enabled: true}, DND: true}); err != nil {
+		t.Fatal(err)
+	}
+	calls := fakeKitty(t)
+	seedWindow(t, "waiting", "2", "idle_prompt", -300)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_advance", "--from", "1"); err != nil {
+		t.Fatalf("_advance: %v", err)
+	}
+	if got := calls(); len(got) != 1 || got[0] != "" {
+		t.Errorf("expected no kitty call, got %q", got)
+	}
+}
@@ -16,7 +16,7 @@ func newCleanCmd(opts Options) *cobra.Command {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			removed, err := queue.CleanStale()
+			removed, err := queue.CleanStale(opts.Store, opts.Journal)
 			if err != nil {
 				return err
 			}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/duboisf/cc-queue/internal/queue"
+
 	"github.com/spf13/cobra"
 )
 
@@ -16,9 +17,14 @@ func newClearCmd(opts Options) *cobra.Command {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := queue.RemoveAll(); err != nil {
+			entries, _ := opts.Store.List()
+			if err := opts.Store.RemoveAll(); err != nil {
 				return err
 			}
+			now := opts.TimeNow()
+			for _, e := range entries {
+				opts.Journal.Record(queue.OpRemove, e, queue.ReasonClear, now)
+			}
 			fmt.Fprintln(opts.Stdout, "Queue cleared")
 			return nil
 		},
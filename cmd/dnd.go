@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// dndNotice describes an active do-not-disturb for the picker header, or
+// returns "" when it is off.
+func dndNotice(cfg queue.Config, now time.Time) string {
+	if !cfg.DNDActive(now) {
+		return ""
+	}
+	notice := "⏾ do not disturb"
+	if !cfg.DNDUntil.IsZero() {
+		notice += " until " + cfg.DNDUntil.Local().Format("15:04")
+	}
+	if len(cfg.DNDAllow) > 0 {
+		notice += " — showing " + strings.Join(cfg.DNDAllow, ", ") + " only"
+	}
+	return notice
+}
+
+func newDNDCmd(opts Options) *cobra.Command {
+	var allow []string
+	var here bool
+
+	cmd := &cobra.Command{
+		Use:   "dnd [on|off|for <duration>]",
+		Short: "Turn do not disturb on or off, or show its state",
+		Long: `Turn do not disturb on or off, or show its state.
+
+While do not disturb is on, notifications and auto-advance are paused, and
+when an allowlist is set, first, next, prev and the picker only show
+sessions whose directory matches it, and only those sessions notify (list
+still shows everything). "for" turns it on for a while (30m, 2h, 1d).
+
+--allow sets the allowlist (cwd globs, ** for any depth) and --here adds
+the current directory and everything below it; without either, the
+previous allowlist is kept.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case len(args) == 0:
+				return nil
+			case args[0] == "on" || args[0] == "off":
+				return cobra.ExactArgs(1)(cmd, args)
+			case args[0] == "for":
+				if len(args) != 2 {
+					return fmt.Errorf("dnd for takes a duration")
+				}
+				return nil
+			}
+			return fmt.Errorf("invalid argument %q (use on, off or for <duration>)", args[0])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch {
+			case len(args) == 0:
+				return []string{"on", "off", "for"}, cobra.ShellCompDirectiveNoFileComp
+			case len(args) == 1 && args[0] == "for":
+				return snoozeDurations, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := opts.TimeNow()
+			setAllow := cmd.Flags().Changed("allow") || here
+			if setAllow && (len(args) == 0 || args[0] == "off") {
+				return fmt.Errorf("--allow and --here need on or for")
+			}
+			if len(args) == 0 {
+				cfg, err := queue.LoadConfig()
+				if err != nil {
+					return err
+				}
+				printDND(opts, cfg, now)
+				return nil
+			}
+
+			cfg, err := queue.UpdateConfig(func(cfg *queue.Config) error {
+				switch args[0] {
+				case "on":
+					cfg.DND = true
+					cfg.DNDUntil = time.Time{}
+				case "off":
+					cfg.DND = false
+					cfg.DNDUntil = time.Time{}
+				case "for":
+					d, err := queue.ParseDuration(args[1])
+					if err != nil {
+						return err
+					}
+					if d <= 0 {
+						return fmt.Errorf("invalid duration %q: must be positive", args[1])
+					}
+					cfg.DND = true
+					cfg.DNDUntil = now.Add(d)
+				}
+
+				if setAllow {
+					cfg.DNDAllow = allow
+					if here {
+						wd, err := os.Getwd()
+						if err != nil {
+							return err
+						}
+						cfg.DNDAllow = append(cfg.DNDAllow, filepath.Join(wd, "**"))
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			printDND(opts, cfg, now)
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&allow, "allow", nil, "Keep sessions in directories matching this glob visible (repeatable)")
+	_ = cmd.RegisterFlagCompletionFunc("allow", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
+	cmd.Flags().BoolVar(&here, "here", false, "Keep sessions in the current directory and below visible")
+	_ = cmd.RegisterFlagCompletionFunc("here", cobra.NoFileCompletions)
+	return cmd
+}
+
+// printDND prints the do-not-disturb state.
+func printDND(opts Options, cfg queue.Config, now time.Time) {
+	if !cfg.DNDActive(now) {
+		fmt.Fprintln(opts.Stdout, "Do not disturb is off")
+		return
+	}
+	if cfg.DNDUntil.IsZero() {
+		fmt.Fprintln(opts.Stdout, "Do not disturb is on")
+	} else {
+		fmt.Fprintf(opts.Stdout, "Do not disturb is on until %s\n", cfg.DNDUntil.Local().Format("Mon 15:04"))
+	}
+	for _, glob := range cfg.DNDAllow {
+		fmt.Fprintf(opts.Stdout, "  showing %s\n", glob)
+	}
+}
@@ -0,0 +1,170 @@
+package cmd_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func TestDND_OnOff(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd"); err != nil {
+		t.Fatalf("dnd: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "off") {
+		t.Errorf("dnd output = %q, want off", out)
+	}
+
+	stdout.Reset()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd", "on", "--allow", "/tmp/infra/**"); err != nil {
+		t.Fatalf("dnd on: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "is on") || !strings.Contains(out, "/tmp/infra/**") {
+		t.Errorf("dnd on output = %q", out)
+	}
+	cfg := queue.ReadConfig()
+	if !cfg.DND || !cfg.DNDUntil.IsZero() || len(cfg.DNDAllow) != 1 {
+		t.Errorf("config after dnd on = %+v", cfg)
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd", "off"); err != nil {
+		t.Fatalf("dnd off: %v", err)
+	}
+	cfg = queue.ReadConfig()
+	if cfg.DND {
+		t.Error("dnd off should turn DND off")
+	}
+	if len(cfg.DNDAllow) != 1 {
+		t.Errorf("dnd off should keep the allowlist, got %q", cfg.DNDAllow)
+	}
+
+	// on without --allow keeps the previous allowlist.
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd", "on"); err != nil {
+		t.Fatalf("dnd on: %v", err)
+	}
+	if cfg := queue.ReadConfig(); len(cfg.DNDAllow) != 1 {
+		t.Errorf("dnd on should keep the allowlist, got %q", cfg.DNDAllow)
+	}
+}
+
+func TestDND_For(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd", "for", "30m", "--here"); err != nil {
+		t.Fatalf("dnd for: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "until") {
+		t.Errorf("dnd for output = %q, want until", out)
+	}
+	cfg := queue.ReadConfig()
+	if want := opts.TimeNow().Add(30 * time.Minute); !cfg.DNDUntil.Equal(want) {
+		t.Errorf("DNDUntil = %v, want %v", cfg.DNDUntil, want)
+	}
+	wd, _ := os.Getwd()
+	if len(cfg.DNDAllow) != 1 || cfg.DNDAllow[0] != wd+"/**" {
+		t.Errorf("DNDAllow = %q, want %s/**", cfg.DNDAllow, wd)
+	}
+
+	// Once it expires, it reports off.
+	opts.TimeNow = func() time.Time { return cfg.DNDUntil.Add(time.Second) }
+	stdout.Reset()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd"); err != nil {
+		t.Fatalf("dnd: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "off") {
+		t.Errorf("dnd output after expiry = %q, want off", out)
+	}
+}
+
+func TestDND_Errors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	opts, _, _ := testOptions()
+
+	for _, args := range [][]string{
+		{"dnd", "maybe"},
+		{"dnd", "on", "now"},
+		{"dnd", "for"},
+		{"dnd", "for", "soon"},
+		{"dnd", "for", "0s"},
+		{"dnd", "off", "--here"},
+		{"dnd", "--allow", "/tmp/**"},
+	} {
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), args...); err == nil {
+			t.Errorf("%q: expected an error", args)
+		}
+	}
+}
+
+func TestDND_KeepsInvalidConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := os.MkdirAll(queue.ConfigDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(queue.ConfigPath(), []byte("{oops"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "dnd", "on"); err == nil {
+		t.Fatal("dnd on with an invalid config should fail")
+	}
+	data, _ := os.ReadFile(queue.ConfigPath())
+	if string(data) != "{oops" {
+		t.Errorf("config was overwritten: %q", data)
+	}
+}
+
+func TestDND_HidesOutsideAllowlist(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	seedWindow(t, "a", "1", "permission_prompt", -300)
+	seedWindow(t, "b", "2", "permission_prompt", -100)
+	opts, stdout, _ := testOptions()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{DND: true, DNDAllow: []string{"/tmp/b/**"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest prompt is outside the allowlist, so first skips it.
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "first"); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if got := calls(); len(got) != 1 || !strings.HasSuffix(got[0], "focus-window --match id:2") {
+		t.Errorf("kitty calls = %q, want focus id:2", got)
+	}
+
+	// list still shows everything.
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "/tmp/a") || !strings.Contains(out, "/tmp/b") {
+		t.Errorf("list output = %q, want both sessions", out)
+	}
+}
+
+func TestDND_ExpiredShowsEverything(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	seedWindow(t, "a", "1", "permission_prompt", -300)
+	seedWindow(t, "b", "2", "permission_prompt", -100)
+	opts, _, _ := testOptions()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := queue.Config{DND: true, DNDUntil: opts.TimeNow().Add(-time.Minute), DNDAllow: []string{"/tmp/b/**"}}
+	if err := queue.WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "first"); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if got := calls(); len(got) != 1 || !strings.HasSuffix(got[0], "focus-window --match id:1") {
+		t.Errorf("kitty calls = %q, want focus id:1", got)
+	}
+}
@@ -20,8 +20,12 @@ func newEndCmd(opts Options) *cobra.Command {
 				return err
 			}
 
-			queue.Debugf("END session=%s", input.SessionID)
-			queue.Remove(input.SessionID)
+			entry := &queue.Entry{SessionID: input.SessionID, CWD: input.CWD, Event: input.HookEventName}
+			input.ApplyTo(entry)
+			queue.Debugf("END session=%s reason=%s", input.SessionID, entry.EndReason)
+			if err := opts.Store.Remove(input.SessionID); err == nil {
+				opts.Journal.Record(queue.OpRemove, entry, queue.ReasonEnd, opts.TimeNow())
+			}
 			return nil
 		},
 	}
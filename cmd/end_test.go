@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
 )
 
 func TestEnd_RemovesSession(t *testing.T) {
@@ -53,3 +54,25 @@ func TestEnd_MalformedJSON(t *testing.T) {
 		t.Fatal("expected error for malformed JSON, got nil")
 	}
 }
+
+func TestEnd_JournalsReason(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "sess-end", "/tmp/project", "idle_prompt", 1001)
+
+	input := `{"session_id":"sess-end","cwd":"/tmp/project","hook_event_name":"SessionEnd","reason":"logout"}`
+	opts, _, _ := testOptionsWithStdin(input)
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "end"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	records, err := testJournal().Read(queue.JournalFilter{Event: queue.OpRemove})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 remove record, got %d", len(records))
+	}
+	if r := records[0]; r.Reason != queue.ReasonEnd || r.Message != "logout" || r.Event != "SessionEnd" {
+		t.Errorf("record = %+v, want end/logout", r)
+	}
+}
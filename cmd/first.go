@@ -22,23 +22,24 @@ func newFirstCmd(opts Options) *cobra.Command {
 				defer restore()
 			}
 
-			entries, err := queue.List()
+			entries, err := opts.Store.List()
 			if err != nil {
 				return err
 			}
 
-			// Filter to entries needing attention (PERM, ASK, IDLE).
+			// Filter to entries needing attention (PERM, ASK, DONE, IDLE)
+			// that have a window to jump to.
 			var pending []*queue.Entry
-			for _, e := range entries {
-				if queue.NeedsAttention(e.Event) {
+			for _, e := range pickerEntries(entries, opts.TimeNow()) {
+				if queue.NeedsAttention(e.Event) && e.WindowID != "" {
 					pending = append(pending, e)
 				}
 			}
 			if len(pending) == 0 {
 				return nil
 			}
-			sortForPicker(pending)
-			return jumpToEntry(pending[0])
+			sortForPicker(pending, opts.Branches)
+			return jumpToEntry(opts, pending[0])
 		},
 	}
 	cmd.Flags().Bool("full-tab", false, "Use stack layout to cover the entire tab, restore on exit")
@@ -30,6 +30,8 @@ func testOptions() (cmd.Options, *bytes.Buffer, *bytes.Buffer) {
 		Stdout:     stdout,
 		Stderr:     stderr,
 		FullTabber: &nopFullTabber{},
+		Store:      testStore(),
+		Journal:    testJournal(),
 	}
 	return opts, stdout, stderr
 }
@@ -46,6 +48,8 @@ func testOptionsWithStdin(stdin string) (cmd.Options, *bytes.Buffer, *bytes.Buff
 		Stdout:     stdout,
 		Stderr:     stderr,
 		FullTabber: &nopFullTabber{},
+		Store:      testStore(),
+		Journal:    testJournal(),
 	}
 	return opts, stdout, stderr
 }
@@ -56,9 +60,31 @@ func setupQueueDir(t *testing.T) string {
 	t.Helper()
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	// Multiplexers and WezTerm win detection; keep a developer's out of the
+	// tests.
+	t.Setenv("TMUX_PANE", "")
+	t.Setenv("ZELLIJ_PANE_ID", "")
+	t.Setenv("WEZTERM_PANE", "")
+	// kitty is reached over its socket before the fake kitty on PATH; keep a
+	// developer's kitty out of the tests too.
+	t.Setenv("KITTY_LISTEN_ON", "")
 	return tmp
 }
 
+// testStore returns a file store rooted at the queue directory, which follows
+// the XDG_STATE_HOME set by setupQueueDir.
+func testStore() queue.Store {
+	s := queue.NewFileStore("")
+	s.Journal = testJournal()
+	return s
+}
+
+// testJournal returns the journal in the queue directory, which follows the
+// XDG_STATE_HOME set by setupQueueDir.
+func testJournal() *queue.Journal {
+	return queue.NewJournal("")
+}
+
 // seedEntry writes a queue entry for testing.
 func seedEntry(t *testing.T, sessionID, cwd, event string, pid int) {
 	t.Helper()
@@ -68,14 +94,14 @@ func seedEntry(t *testing.T, sessionID, cwd, event string, pid int) {
 // seedEntryWithMessage writes a queue entry with a message for testing.
 func seedEntryWithMessage(t *testing.T, sessionID, cwd, event string, pid int, message string) {
 	t.Helper()
-	err := queue.Write(&queue.Entry{
-		Timestamp:     time.Now(),
-		SessionID:     sessionID,
-		KittyWindowID: "42",
-		PID:           pid,
-		CWD:           cwd,
-		Event:         event,
-		Message:       message,
+	err := testStore().Write(&queue.Entry{
+		Timestamp: time.Now(),
+		SessionID: sessionID,
+		WindowID:  "42",
+		PID:       pid,
+		CWD:       cwd,
+		Event:     event,
+		Message:   message,
 	})
 	if err != nil {
 		t.Fatalf("seedEntry: %v", err)
@@ -85,23 +111,23 @@ func seedEntryWithMessage(t *testing.T, sessionID, cwd, event string, pid int, m
 // seedEntryAtTime writes a queue entry with a relative time offset (in seconds) for testing.
 func seedEntryAtTime(t *testing.T, sessionID, cwd, event string, pid int, offsetSec int) {
 	t.Helper()
-	err := queue.Write(&queue.Entry{
-		Timestamp:     time.Now().Add(time.Duration(offsetSec) * time.Second),
-		SessionID:     sessionID,
-		KittyWindowID: "42",
-		PID:           pid,
-		CWD:           cwd,
-		Event:         event,
+	err := testStore().Write(&queue.Entry{
+		Timestamp: time.Now().Add(time.Duration(offsetSec) * time.Second),
+		SessionID: sessionID,
+		WindowID:  "42",
+		PID:       pid,
+		CWD:       cwd,
+		Event:     event,
 	})
 	if err != nil {
 		t.Fatalf("seedEntryAtTime: %v", err)
 	}
 }
 
-// seedEntryNoWindow writes a queue entry without a KittyWindowID for testing.
+// seedEntryNoWindow writes a queue entry without a WindowID for testing.
 func seedEntryNoWindow(t *testing.T, sessionID, cwd, event string, pid int) {
 	t.Helper()
-	err := queue.Write(&queue.Entry{
+	err := testStore().Write(&queue.Entry{
 		Timestamp: time.Now(),
 		SessionID: sessionID,
 		PID:       pid,
@@ -113,6 +139,17 @@ func seedEntryNoWindow(t *testing.T, sessionID, cwd, event string, pid int) {
 	}
 }
 
+// setPane moves a seeded session to a pane of the terminal term.
+func setPane(t *testing.T, sessionID, term, wid, addr string) {
+	t.Helper()
+	if err := testStore().Update(sessionID, func(e *queue.Entry) error {
+		e.Terminal, e.WindowID, e.ListenOn = term, wid, addr
+		return nil
+	}); err != nil {
+		t.Fatalf("setPane: %v", err)
+	}
+}
+
 // executeCommand runs a cobra command with args and captures output.
 func executeCommand(root *cobra.Command, args ...string) (stdout, stderr string, err error) {
 	outBuf := new(bytes.Buffer)
@@ -128,14 +165,14 @@ func executeCommand(root *cobra.Command, args ...string) (stdout, stderr string,
 func seedEntryWithHistory(t *testing.T, sessionID, cwd string, events []string, pid int) {
 	t.Helper()
 	for _, event := range events {
-		err := queue.Write(&queue.Entry{
-			Timestamp:     time.Now(),
-			SessionID:     sessionID,
-			KittyWindowID: "42",
-			PID:           pid,
-			CWD:           cwd,
-			Event:         event,
-			Message:       "msg for " + event,
+		err := testStore().Write(&queue.Entry{
+			Timestamp: time.Now(),
+			SessionID: sessionID,
+			WindowID:  "42",
+			PID:       pid,
+			CWD:       cwd,
+			Event:     event,
+			Message:   "msg for " + event,
 		})
 		if err != nil {
 			t.Fatalf("seedEntryWithHistory: %v", err)
@@ -146,7 +183,7 @@ func seedEntryWithHistory(t *testing.T, sessionID, cwd string, events []string,
 // entryCount returns the number of entries in the queue.
 func entryCount(t *testing.T) int {
 	t.Helper()
-	entries, err := queue.List()
+	entries, err := testStore().List()
 	if err != nil {
 		t.Fatalf("entryCount: %v", err)
 	}
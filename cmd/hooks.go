@@ -44,6 +44,9 @@ func hooksViewRunE(opts Options, project *bool, output *string) func(*cobra.Comm
 			{"UserPromptSubmit", "cc-queue pop", "Clear entry on user response", status.UserPromptSubmit},
 			{"SessionStart", "cc-queue push", "Register new session", status.SessionStart},
 			{"SessionEnd", "cc-queue end", "Clean up finished session", status.SessionEnd},
+			{"PreToolUse", "cc-queue tool", "Record tool call awaiting approval", status.PreToolUse},
+			{"Stop", "cc-queue push", "Queue finished turns right away", status.Stop},
+			{"SubagentStop", "cc-queue push", "Track finished subagents", status.SubagentStop},
 		}
 
 		if *output == "json" {
@@ -140,7 +143,7 @@ func newHooksInstallCmd(opts Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Install cc-queue hooks into Claude Code settings",
-		Long: `Install all four cc-queue hooks into Claude Code settings.
+		Long: `Install all cc-queue hooks into Claude Code settings.
 
 This is idempotent — running it multiple times is safe and will not
 duplicate hooks. Existing hooks from other tools are preserved.
@@ -154,6 +157,12 @@ The following hooks are installed:
                      prompt, clearing the entry from the queue.
   SessionStart       Triggers "cc-queue push" to register new sessions.
   SessionEnd         Triggers "cc-queue end" to clean up finished sessions.
+  PreToolUse         Triggers "cc-queue tool" to record the tool call a
+                     permission prompt is about, shown in the picker.
+  Stop               Triggers "cc-queue push" as soon as a turn finishes,
+                     so the session shows up as DONE without waiting for
+                     the idle notification.
+  SubagentStop       Triggers "cc-queue push" when a subagent finishes.
 
 By default hooks are written to ~/.claude/settings.json (user-level).
 Use --project to write to .claude/settings.json in the current directory.`,
@@ -204,8 +213,9 @@ func newHooksUninstallCmd(opts Options) *cobra.Command {
 		Short: "Remove cc-queue hooks from Claude Code settings",
 		Long: `Remove all cc-queue hooks from Claude Code settings.
 
-This removes the Notification, UserPromptSubmit, SessionStart, and
-SessionEnd hooks that were installed by "cc-queue hooks install".
+This removes the Notification, UserPromptSubmit, SessionStart,
+SessionEnd, PreToolUse, Stop and SubagentStop hooks that were
+installed by "cc-queue hooks install".
 
 Only cc-queue entries are removed — hooks from other tools sharing the
 same event keys are left intact. If a matcher contains both a cc-queue
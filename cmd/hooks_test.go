@@ -27,7 +27,7 @@ func TestHooksInstall_DefaultUser(t *testing.T) {
 		t.Errorf("stdout = %q, want it to contain 'Hooks installed in'", got)
 	}
 
-	// Verify all four hooks are present.
+	// Verify all hooks are present.
 	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
 	data, err := os.ReadFile(settingsPath)
 	if err != nil {
@@ -40,7 +40,7 @@ func TestHooksInstall_DefaultUser(t *testing.T) {
 	}
 
 	hooks, _ := settings["hooks"].(map[string]any)
-	for _, key := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd"} {
+	for _, key := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd", "PreToolUse", "Stop", "SubagentStop"} {
 		if _, ok := hooks[key]; !ok {
 			t.Errorf("missing hook: %s", key)
 		}
@@ -130,8 +130,8 @@ func TestHooks_ShowsStatus(t *testing.T) {
 	}
 
 	got := stdout2.String()
-	// All four should show as installed.
-	for _, hook := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd"} {
+	// All should show as installed.
+	for _, hook := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd", "PreToolUse", "Stop", "SubagentStop"} {
 		expected := "\u2713 " + hook
 		if !strings.Contains(got, expected) {
 			t.Errorf("missing %q in output:\n%s", expected, got)
@@ -176,7 +176,7 @@ func TestHooksUninstall_RemovesHooks(t *testing.T) {
 	json.Unmarshal(data, &settings)
 
 	hooks, _ := settings["hooks"].(map[string]any)
-	for _, key := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd"} {
+	for _, key := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd", "PreToolUse", "Stop", "SubagentStop"} {
 		if _, ok := hooks[key]; ok {
 			t.Errorf("hook %s still present after uninstall", key)
 		}
@@ -301,8 +301,8 @@ func TestHooksView_ShowsStatus(t *testing.T) {
 	}
 
 	got := stdout2.String()
-	// All four should show as installed with their commands.
-	for _, hook := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd"} {
+	// All should show as installed with their commands.
+	for _, hook := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd", "PreToolUse", "Stop", "SubagentStop"} {
 		expected := "\u2713 " + hook
 		if !strings.Contains(got, expected) {
 			t.Errorf("missing %q in output:\n%s", expected, got)
@@ -355,7 +355,7 @@ func TestHooksView_ProjectFlag(t *testing.T) {
 	if !strings.Contains(got, ".claude/settings.json") {
 		t.Errorf("expected project settings path in output:\n%s", got)
 	}
-	for _, hook := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd"} {
+	for _, hook := range []string{"Notification", "UserPromptSubmit", "SessionStart", "SessionEnd", "PreToolUse", "Stop", "SubagentStop"} {
 		expected := "\u2713 " + hook
 		if !strings.Contains(got, expected) {
 			t.Errorf("missing %q in output:\n%s", expected, got)
@@ -398,8 +398,8 @@ func TestHooksView_JSONOutput(t *testing.T) {
 	if !strings.Contains(result.Settings, "settings.json") {
 		t.Errorf("settings path missing: %s", result.Settings)
 	}
-	if len(result.Hooks) != 4 {
-		t.Fatalf("expected 4 hooks, got %d", len(result.Hooks))
+	if len(result.Hooks) != 7 {
+		t.Fatalf("expected 7 hooks, got %d", len(result.Hooks))
 	}
 	for _, h := range result.Hooks {
 		if !h.Installed {
@@ -449,7 +449,7 @@ func TestHooks_DetectsMissing(t *testing.T) {
 	}
 
 	// Others should be missing.
-	for _, hook := range []string{"UserPromptSubmit", "SessionStart", "SessionEnd"} {
+	for _, hook := range []string{"UserPromptSubmit", "SessionStart", "SessionEnd", "PreToolUse", "Stop", "SubagentStop"} {
 		expected := "\u2717 " + hook
 		if !strings.Contains(got, expected) {
 			t.Errorf("missing %q in output:\n%s", expected, got)
@@ -11,7 +11,7 @@ import (
 
 func newInstallCmd(opts Options) *cobra.Command {
 	var user, project, force bool
-	var pickerShortcut, firstShortcut string
+	var pickerShortcut, firstShortcut, nextShortcut, prevShortcut string
 
 	cmd := &cobra.Command{
 		Use:   "install",
@@ -36,6 +36,8 @@ func newInstallCmd(opts Options) *cobra.Command {
 			shortcuts := queue.KittyShortcuts{
 				Picker: pickerShortcut,
 				First:  firstShortcut,
+				Next:   nextShortcut,
+				Prev:   prevShortcut,
 				Shell:  os.Getenv("SHELL"),
 			}
 
@@ -49,6 +51,12 @@ func newInstallCmd(opts Options) *cobra.Command {
 				if !cmd.Flags().Changed("first-shortcut") {
 					shortcuts.First = existing.First
 				}
+				if !cmd.Flags().Changed("next-shortcut") {
+					shortcuts.Next = existing.Next
+				}
+				if !cmd.Flags().Changed("prev-shortcut") {
+					shortcuts.Prev = existing.Prev
+				}
 			}
 
 			// Preview the kitty config content before writing.
@@ -82,11 +90,15 @@ func newInstallCmd(opts Options) *cobra.Command {
 	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing cc-queue.conf (preserves shortcuts unless overridden)")
 	cmd.Flags().StringVar(&pickerShortcut, "picker-shortcut", "", "Kitty shortcut for fzf picker overlay (e.g. 'kitty_mod+shift+q')")
 	cmd.Flags().StringVar(&firstShortcut, "first-shortcut", "", "Kitty shortcut for jump-to-first (e.g. 'kitty_mod+shift+u')")
+	cmd.Flags().StringVar(&nextShortcut, "next-shortcut", "", "Kitty shortcut for cycling to the next waiting session (e.g. 'kitty_mod+shift+j')")
+	cmd.Flags().StringVar(&prevShortcut, "prev-shortcut", "", "Kitty shortcut for cycling to the previous waiting session (e.g. 'kitty_mod+shift+k')")
 	_ = cmd.RegisterFlagCompletionFunc("user", cobra.NoFileCompletions)
 	_ = cmd.RegisterFlagCompletionFunc("project", cobra.NoFileCompletions)
 	_ = cmd.RegisterFlagCompletionFunc("force", cobra.NoFileCompletions)
 	_ = cmd.RegisterFlagCompletionFunc("picker-shortcut", cobra.NoFileCompletions)
 	_ = cmd.RegisterFlagCompletionFunc("first-shortcut", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("next-shortcut", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("prev-shortcut", cobra.NoFileCompletions)
 
 	return cmd
 }
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,22 +13,33 @@ import (
 
 	"github.com/duboisf/cc-queue/internal/conversation"
 	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
-const defaultHeader = "cc-queue — enter=jump  ctrl-i=shell  ctrl-r=refresh"
+const defaultHeader = "cc-queue — enter=jump  ctrl-i=shell  ctrl-r=refresh\n" + bulkHeader
 
 // entryRow holds precomputed display values for a queue entry.
 type entryRow struct {
 	sessionID string
+	priority  string // one-cell priority marker
 	age       string
 	event     string
 	path      string
 	branch    string
+	noJump    string // noJumpMark for sessions without a window
+	tool      string // pending tool call, only for permission prompts
 }
 
-// buildRows precomputes display values and the max path width for alignment.
-func buildRows(entries []*queue.Entry) ([]entryRow, int) {
+// readMark is appended to the event label of entries marked as read.
+const readMark = "✓"
+
+// maxToolLen is the maximum width of the pending tool call shown in a row.
+const maxToolLen = 60
+
+// buildRows precomputes display values and the max path width for alignment,
+// looking up branches in branches.
+func buildRows(entries []*queue.Entry, branches *queue.BranchCache) ([]entryRow, int) {
 	rows := make([]entryRow, len(entries))
 	maxPath := len("PATH") // minimum width = header label
 	for i, e := range entries {
@@ -34,21 +47,41 @@ func buildRows(entries []*queue.Entry) ([]entryRow, int) {
 		if len(p) > maxPath {
 			maxPath = len(p)
 		}
-		branch := queue.GitBranch(e.CWD)
+		branch := branches.Get(e.CWD)
 		if branch == "" {
 			branch = "-"
 		}
 		rows[i] = entryRow{
 			sessionID: e.SessionID,
+			priority:  queue.PriorityMark(e.Priority),
 			age:       queue.FormatAge(e.Timestamp),
 			event:     queue.EventLabel(e.Event),
 			path:      p,
 			branch:    branch,
 		}
+		if e.Read {
+			rows[i].event += readMark
+		}
+		if e.WindowID == "" {
+			rows[i].noJump = noJumpMark
+		}
+		if e.Event == "permission_prompt" && e.Tool != nil {
+			rows[i].tool = "  " + e.Tool.OneLine(maxToolLen)
+		}
 	}
 	return rows, maxPath
 }
 
+// rowsHeader returns the column header matching entryRow.text.
+func rowsHeader(maxPath int) string {
+	return fmt.Sprintf(" %-5s %-5s  %-*s  %s", "AGE", "EVENT", maxPath, "PATH", "BRANCH")
+}
+
+// text formats the row with the path column padded to maxPath.
+func (r entryRow) text(maxPath int) string {
+	return fmt.Sprintf("%s%-5s %-5s  %-*s  %s%s%s", r.priority, r.age, r.event, maxPath, r.path, r.branch, r.noJump, r.tool)
+}
+
 func newListCmd(opts Options) *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
@@ -58,42 +91,67 @@ func newListCmd(opts Options) *cobra.Command {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			entries, err := queue.List()
+			entries, err := opts.Store.List()
 			if err != nil {
 				return err
 			}
+			entries = visibleEntries(entries, opts.TimeNow())
 			if len(entries) == 0 {
 				fmt.Fprintln(opts.Stdout, "No active sessions")
 				return nil
 			}
 
-			sortForPicker(entries)
-			rows, maxPath := buildRows(entries)
-			fmt.Fprintf(opts.Stdout, "%-5s %-5s  %-*s  %s\n", "AGE", "EVENT", maxPath, "PATH", "BRANCH")
+			sortForPicker(entries, opts.Branches)
+			rows, maxPath := buildRows(entries, opts.Branches)
+			fmt.Fprintln(opts.Stdout, rowsHeader(maxPath))
 			for _, r := range rows {
-				fmt.Fprintf(opts.Stdout, "%-5s %-5s  %-*s  %s\n",
-					r.age, r.event, maxPath, r.path, r.branch)
+				fmt.Fprintln(opts.Stdout, r.text(maxPath))
 			}
 			return nil
 		},
 	}
 }
 
+// visibleEntries drops entries snoozed at now, which stay in the store but
+// are hidden from list, first and the picker.
+func visibleEntries(entries []*queue.Entry, now time.Time) []*queue.Entry {
+	var out []*queue.Entry
+	for _, e := range entries {
+		if !e.IsSnoozed(now) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// pickerEntries is visibleEntries minus the sessions do-not-disturb hides,
+// for first, next, prev and the picker.
+func pickerEntries(entries []*queue.Entry, now time.Time) []*queue.Entry {
+	cfg := queue.ReadConfig()
+	var out []*queue.Entry
+	for _, e := range visibleEntries(entries, now) {
+		if !cfg.DNDHides(e.CWD, now) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 // fzfLines outputs fzf-formatted lines for all queue entries.
 // The first line is a column header (pinned via --header-lines=1).
 // Format: _\theader / session_id\tage event  path  branch
-func fzfLines() string {
-	entries, err := queue.List()
+func fzfLines(opts Options, now time.Time) string {
+	entries, err := opts.Store.List()
+	entries = pickerEntries(entries, now)
 	if err != nil || len(entries) == 0 {
 		return ""
 	}
-	sortForPicker(entries)
-	rows, maxPath := buildRows(entries)
+	sortForPicker(entries, opts.Branches)
+	rows, maxPath := buildRows(entries, opts.Branches)
 	var b strings.Builder
-	fmt.Fprintf(&b, "_\t%-5s %-5s  %-*s  %s\n", "AGE", "EVENT", maxPath, "PATH", "BRANCH")
+	fmt.Fprintf(&b, "_\t%s\n", rowsHeader(maxPath))
 	for _, r := range rows {
-		fmt.Fprintf(&b, "%s\t%-5s %-5s  %-*s  %s\n",
-			r.sessionID, r.age, r.event, maxPath, r.path, r.branch)
+		fmt.Fprintf(&b, "%s\t%s\n", r.sessionID, r.text(maxPath))
 	}
 	return b.String()
 }
@@ -107,7 +165,7 @@ func newListFzfCmd(opts Options) *cobra.Command {
 			if opts.CleanStaleWindowsFn != nil {
 				opts.CleanStaleWindowsFn()
 			}
-			fmt.Fprint(cmd.OutOrStdout(), fzfLines())
+			fmt.Fprint(cmd.OutOrStdout(), fzfLines(opts, opts.TimeNow()))
 		},
 	}
 }
@@ -121,101 +179,162 @@ const maxMsgLen = 500
 // msgIndent is the prefix for each line of a conversation message body.
 const msgIndent = "    "
 
+// sessionDetails describes how a session started, why it ended and its
+// permission mode, e.g. "started: resume  mode: plan". Empty if unknown.
+func sessionDetails(e *queue.Entry) string {
+	var parts []string
+	if e.Source != "" {
+		parts = append(parts, "started: "+e.Source)
+	}
+	if e.EndReason != "" {
+		parts = append(parts, "ended: "+e.EndReason)
+	}
+	if e.PermissionMode != "" && e.PermissionMode != "default" {
+		parts = append(parts, "mode: "+e.PermissionMode)
+	}
+	return strings.Join(parts, "  ")
+}
+
 func newPreviewCmd(opts Options) *cobra.Command {
 	return &cobra.Command{
 		Use:    "_preview [session_id]",
 		Hidden: true,
 		Args:   cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			sf, err := queue.ReadSessionByID(args[0])
-			if err != nil || sf.Current == nil {
-				return
-			}
+			writePreview(cmd.OutOrStdout(), opts, args[0])
+		},
+	}
+}
 
-			w := cmd.OutOrStdout()
-			e := sf.Current
-			branch := queue.GitBranch(e.CWD)
-			if branch != "" {
-				fmt.Fprintf(w, "%s  %s  %s  (%s)\n\n",
-					queue.EventLabel(e.Event),
-					queue.FormatAge(e.Timestamp),
-					queue.ShortenPath(e.CWD),
-					branch)
-			} else {
-				fmt.Fprintf(w, "%s  %s  %s\n\n",
-					queue.EventLabel(e.Event),
-					queue.FormatAge(e.Timestamp),
-					queue.ShortenPath(e.CWD))
-			}
-			if e.Message != "" {
-				fmt.Fprintln(w, e.Message)
-			}
+// writePreview renders the preview of a session: header, pending tool call,
+// message and recent conversation. Nothing is written if the session is gone.
+func writePreview(w io.Writer, opts Options, sessionID string) {
+	sf, err := opts.Store.ReadSession(sessionID)
+	if err != nil || sf.Current == nil {
+		return
+	}
 
-			// Show recent conversation from Claude Code JSONL.
-			claudeDir := opts.ClaudeDir
-			if claudeDir == "" {
-				home, _ := os.UserHomeDir()
-				claudeDir = filepath.Join(home, ".claude")
+	e := sf.Current
+	branch := opts.Branches.Get(e.CWD)
+	if branch != "" {
+		fmt.Fprintf(w, "%s  %s  %s  (%s)\n\n",
+			queue.EventLabel(e.Event),
+			queue.FormatAge(e.Timestamp),
+			queue.ShortenPath(e.CWD),
+			branch)
+	} else {
+		fmt.Fprintf(w, "%s  %s  %s\n\n",
+			queue.EventLabel(e.Event),
+			queue.FormatAge(e.Timestamp),
+			queue.ShortenPath(e.CWD))
+	}
+	if details := sessionDetails(e); details != "" {
+		fmt.Fprintln(w, details)
+	}
+	if e.Event == "permission_prompt" && e.Tool != nil {
+		fmt.Fprintf(w, "Waiting for approval: %s\n", e.Tool.Name)
+		for _, line := range strings.Split(e.Tool.Summary, "\n") {
+			fmt.Fprintf(w, "%s%s\n", msgIndent, line)
+		}
+		fmt.Fprintln(w)
+	}
+	if e.Message != "" {
+		fmt.Fprintln(w, e.Message)
+	}
+
+	// Show recent conversation from Claude Code JSONL.
+	jsonlPath := e.TranscriptPath
+	if jsonlPath == "" {
+		claudeDir := opts.ClaudeDir
+		if claudeDir == "" {
+			home, _ := os.UserHomeDir()
+			claudeDir = filepath.Join(home, ".claude")
+		}
+		jsonlPath = conversation.JSONLPath(claudeDir, e.CWD, e.SessionID)
+	}
+	lines, _ := conversation.ReadLines(jsonlPath, maxConversationLines)
+	if len(lines) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "\u2500\u2500 Conversation \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500")
+		// Show earliest message first (chronological order).
+		for i := len(lines) - 1; i >= 0; i-- {
+			l := lines[i]
+			text := l.Text
+			if len(text) > maxMsgLen {
+				text = text[:maxMsgLen-3] + "..."
 			}
-			jsonlPath := conversation.JSONLPath(claudeDir, e.CWD, e.SessionID)
-			lines, _ := conversation.ReadLines(jsonlPath, maxConversationLines)
-			if len(lines) > 0 {
-				fmt.Fprintln(w)
-				fmt.Fprintln(w, "\u2500\u2500 Conversation \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500")
-				// Show earliest message first (chronological order).
-				for i := len(lines) - 1; i >= 0; i-- {
-					l := lines[i]
-					text := l.Text
-					if len(text) > maxMsgLen {
-						text = text[:maxMsgLen-3] + "..."
-					}
-					fmt.Fprintf(w, "%5s %s\n", queue.FormatAge(l.Timestamp), l.Icon)
-					for _, line := range strings.Split(text, "\n") {
-						fmt.Fprintf(w, "%s%s\n", msgIndent, line)
-					}
-				}
+			fmt.Fprintf(w, "%5s %s\n", queue.FormatAge(l.Timestamp), l.Icon)
+			for _, line := range strings.Split(text, "\n") {
+				fmt.Fprintf(w, "%s%s\n", msgIndent, line)
 			}
-		},
+		}
 	}
 }
 
-// jumpToEntry focuses the kitty window for the given entry.
+// jumpToEntry focuses the terminal window or pane of the given entry.
 // Sessions persist — only stale entries (failed focus) are removed.
-// Before jumping, the current session (identified by $KITTY_WINDOW_ID)
+// Before jumping, the current session (the pane this process runs in)
 // is touched to push it to the end of the queue.
-func jumpToEntry(entry *queue.Entry) error {
-	if entry.KittyWindowID == "" {
+func jumpToEntry(opts Options, entry *queue.Entry) error {
+	if entry.WindowID == "" {
 		return nil
 	}
 	// Deprioritize the current session before jumping away.
-	if currentWID := os.Getenv("KITTY_WINDOW_ID"); currentWID != "" {
-		queue.TouchByWindowID(currentWID, time.Now())
+	queue.TouchWhere(opts.Store, opts.Journal, currentLocation("").holds, time.Now())
+	if err := focusEntry(opts, entry); err != nil {
+		return err
 	}
-	kittyArgs := []string{"@"}
-	if entry.KittyListenOn != "" {
-		kittyArgs = append(kittyArgs, "--to", entry.KittyListenOn)
+	now := time.Now()
+	opts.Store.Touch(entry.SessionID, now)
+	opts.Journal.Record(queue.OpJump, entry, "", now)
+	return nil
+}
+
+// entryPane returns the terminal backend and pane of entry's session.
+func entryPane(entry *queue.Entry) (terminal.Terminal, terminal.Pane, error) {
+	t := terminal.ByName(entry.Terminal)
+	if t == nil {
+		return nil, terminal.Pane{}, fmt.Errorf("unknown terminal %q", entry.Terminal)
 	}
-	kittyArgs = append(kittyArgs, "focus-window", "--match", "id:"+entry.KittyWindowID)
-	cmd := exec.Command("kitty", kittyArgs...)
-	if out, err := cmd.CombinedOutput(); err != nil {
-		queue.Remove(entry.SessionID)
-		return fmt.Errorf("kitty focus-window failed: %w\n%s", err, strings.TrimSpace(string(out)))
+	return t, terminal.Pane{ID: entry.WindowID, Addr: entry.ListenOn}, nil
+}
+
+// removedError is a focus error for which the entry was removed from the
+// queue, its window being gone.
+type removedError struct{ err error }
+
+func (e removedError) Error() string { return e.err.Error() }
+func (e removedError) Unwrap() error { return e.err }
+
+// focusEntry focuses the entry's window or pane without touching any entry,
+// removing the entry if its window is gone; the error is then a
+// removedError.
+func focusEntry(opts Options, entry *queue.Entry) error {
+	t, pane, err := entryPane(entry)
+	if err != nil {
+		return err
+	}
+	if err := t.Focus(pane); err != nil {
+		if opts.Store.Remove(entry.SessionID) == nil {
+			opts.Journal.Record(queue.OpRemove, entry, queue.ReasonStaleWindow, time.Now())
+			return removedError{err}
+		}
+		return err
 	}
-	queue.Touch(entry.SessionID, time.Now())
 	return nil
 }
 
-func newJumpInternalCmd() *cobra.Command {
+func newJumpInternalCmd(opts Options) *cobra.Command {
 	return &cobra.Command{
 		Use:    "_jump [session_id]",
 		Hidden: true,
 		Args:   cobra.ExactArgs(1),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-			return sessionIDCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+			return sessionIDCompletions(opts.Store, toComplete), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sessionID := args[0]
-			entries, err := queue.List()
+			entries, err := opts.Store.List()
 			if err != nil {
 				return err
 			}
@@ -229,13 +348,17 @@ func newJumpInternalCmd() *cobra.Command {
 			if target == nil {
 				return nil
 			}
-			return jumpToEntry(target)
+			if target.WindowID == "" {
+				fmt.Fprintln(opts.Stdout, noJumpMessage(opts, target))
+				return nil
+			}
+			return jumpToEntry(opts, target)
 		},
 	}
 }
 
-func sessionIDCompletions(toComplete string) []string {
-	entries, err := queue.List()
+func sessionIDCompletions(store queue.Store, toComplete string) []string {
+	entries, err := store.List()
 	if err != nil {
 		return nil
 	}
@@ -248,10 +371,20 @@ func sessionIDCompletions(toComplete string) []string {
 	return ids
 }
 
-// jumpRunE returns the RunE function for the root command (live fzf picker).
+// jumpRunE returns the RunE function for the root command (live picker,
+// fzf or native).
 func jumpRunE(opts Options) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		queue.CleanStale()
+		uiName, _ := cmd.Flags().GetString("ui")
+		if uiName == "" {
+			uiName = queue.ReadConfig().UI
+		}
+		ui, err := resolveUI(uiName)
+		if err != nil {
+			return err
+		}
+
+		queue.CleanStale(opts.Store, opts.Journal)
 		if opts.CleanStaleWindowsFn != nil {
 			opts.CleanStaleWindowsFn()
 		}
@@ -264,6 +397,10 @@ func jumpRunE(opts Options) func(*cobra.Command, []string) error {
 			defer restore()
 		}
 
+		if ui == uiNative {
+			return runNativePicker(opts)
+		}
+
 		self, err := os.Executable()
 		if err != nil {
 			self = "cc-queue"
@@ -272,41 +409,111 @@ func jumpRunE(opts Options) func(*cobra.Command, []string) error {
 		previewCmd := self + " _preview {1}"
 		jumpCmd := self + " _jump {1}"
 		shellCmd := self + " _shell {1}"
+		actionCmd := self + " _action "
+		header := defaultHeader
+		if notice := dndNotice(queue.ReadConfig(), opts.TimeNow()); notice != "" {
+			header += "\n" + notice
+		}
 
 		fzf := exec.Command("fzf",
 			"--height=100%",
 			"--layout=reverse",
 			"--with-nth=2..",
 			"--delimiter=\t",
-			"--no-multi",
+			"--multi",
 			"--header-first",
-			"--header="+defaultHeader,
+			"--header="+header,
 			"--header-lines=1",
 			"--prompt=Jump to session> ",
 			"--preview="+previewCmd,
 			"--preview-window=down,wrap,70%",
-			"--bind=ctrl-r:change-header("+defaultHeader+")+reload("+reloadCmd+")",
-			`--bind=enter:transform(`+jumpCmd+` >/dev/null 2>&1 && echo abort || { echo 'change-header:`+"⚠ Kitty window closed — entry removed"+`'; echo 'reload:`+reloadCmd+`'; })`,
+			"--bind=ctrl-r:change-header("+header+")+reload("+reloadCmd+")",
+			"--bind=ctrl-space:toggle+down",
+			"--bind=alt-a:toggle-all",
+			"--bind=alt-d:execute-silent("+actionCmd+"dismiss {+1})+reload("+reloadCmd+")",
+			"--bind=alt-s:execute-silent("+actionCmd+"snooze {+1})+reload("+reloadCmd+")",
+			"--bind=alt-h:execute-silent("+actionCmd+"--for="+pickerSnoozeFor.String()+" snooze {+1})+reload("+reloadCmd+")",
+			"--bind=alt-r:execute-silent("+actionCmd+"read {+1})+reload("+reloadCmd+")",
+			"--bind=alt-t:execute-silent("+actionCmd+"tabs {+1})+clear-selection",
+			"--bind=alt-p:execute-silent("+actionCmd+"pin {+1})+reload("+reloadCmd+")",
+			"--bind=alt-k:execute-silent("+actionCmd+"raise {+1})+reload("+reloadCmd+")",
+			"--bind=alt-j:execute-silent("+actionCmd+"lower {+1})+reload("+reloadCmd+")",
+			// _jump prints how to reach sessions it cannot focus.
+			`--bind=enter:transform(out=$(`+jumpCmd+` 2>/dev/null) && { [ -z "$out" ] && echo abort || echo "change-header:$out"; } || { echo 'change-header:`+"⚠ Window closed — entry removed"+`'; echo 'reload:`+reloadCmd+`'; })`,
 			`--bind=ctrl-i:transform(`+shellCmd+` >/dev/null 2>&1 && echo abort || echo 'change-header:`+"⚠ Shell launch failed"+`')`,
 		)
-		fzf.Stdin = strings.NewReader(fzfLines())
+		fzf.Stdin = strings.NewReader(fzfLines(opts, opts.TimeNow()))
 		fzf.Stderr = opts.Stderr
 
+		// Push reloads into fzf while it runs, so new prompts show up and
+		// ages keep ticking without ctrl-r.
+		if srv, err := newFzfServer(); err == nil {
+			defer srv.close()
+			fzf.Args = append(fzf.Args, srv.args()...)
+			fzf.Env = srv.env()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go fzfLiveReload(ctx, srv, reloadCmd)
+		}
+
 		fzf.Run()
 		return nil
 	}
 }
 
-// sortForPicker sorts entries with attention-needed sessions first, then by oldest
-// (longest-waiting at top). This way, jumping to a session and touching its
-// timestamp pushes it to the bottom of the list.
-func sortForPicker(entries []*queue.Entry) {
-	sort.SliceStable(entries, func(i, j int) bool {
-		ai := queue.NeedsAttention(entries[i].Event)
-		aj := queue.NeedsAttention(entries[j].Event)
-		if ai != aj {
-			return ai
+// loadRules compiles the sort rules from the config. Invalid rules are
+// ignored so a typo cannot break the picker; `cc-queue rules test` reports them.
+func loadRules() *queue.RuleSet {
+	rs, err := queue.CompileRules(queue.ReadConfig().Rules)
+	if err != nil {
+		queue.Debugf("RULES ignored: %v", err)
+		return nil
+	}
+	return rs
+}
+
+// rankedEntry is an entry with the rank and name of the rule that matched it.
+type rankedEntry struct {
+	*queue.Entry
+	rank int
+	rule string
+}
+
+// rankEntries evaluates the rules for each entry and sorts the result by
+// priority (pinned, high, normal, low), then by rule rank, then by attention
+// rank (prompts and finished turns, then idle sessions, then the rest),
+// unread before read, then by oldest (longest-waiting at top). This way,
+// jumping to a session and touching its timestamp pushes it to the bottom of
+// its group. Branches for the rules are looked up in branches.
+func rankEntries(entries []*queue.Entry, rules *queue.RuleSet, branches *queue.BranchCache, now time.Time) []rankedEntry {
+	ranked := make([]rankedEntry, len(entries))
+	for i, e := range entries {
+		rank, rule := rules.Rank(e, now, func() string { return branches.Get(e.CWD) })
+		ranked[i] = rankedEntry{Entry: e, rank: rank, rule: rule}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if pa, pb := queue.PriorityRank(a.Priority), queue.PriorityRank(b.Priority); pa != pb {
+			return pa < pb
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if ra, rb := queue.AttentionRank(a.Event), queue.AttentionRank(b.Event); ra != rb {
+			return ra < rb
 		}
-		return entries[i].Timestamp.Before(entries[j].Timestamp)
+		if a.Read != b.Read {
+			return !a.Read
+		}
+		return a.Timestamp.Before(b.Timestamp)
 	})
+	return ranked
+}
+
+// sortForPicker sorts entries in place in the order of rankEntries, using
+// the rules from the config.
+func sortForPicker(entries []*queue.Entry, branches *queue.BranchCache) {
+	for i, r := range rankEntries(entries, loadRules(), branches, time.Now()) {
+		entries[i] = r.Entry
+	}
 }
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/duboisf/cc-queue/cmd"
 	"github.com/duboisf/cc-queue/internal/conversation"
@@ -182,6 +183,30 @@ func TestList_OldestFirstWithinGroup(t *testing.T) {
 	}
 }
 
+func TestList_DoneBeforeIdle(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+
+	// A long-idle session and a turn that just finished.
+	seedEntryAtTime(t, "sess-idle", "/home/user/project-idle", "idle_prompt", 1001, -600)
+	seedEntryAtTime(t, "sess-done", "/home/user/project-done", "Stop", 1002, -5)
+	seedEntryAtTime(t, "sess-work", "/home/user/project-work", "working", 1003, -900)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(stdout.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d: %q", len(lines), stdout.String())
+	}
+	for i, want := range []string{"DONE", "IDLE", "WORK"} {
+		if !strings.Contains(lines[i+1], want) {
+			t.Errorf("line %d = %q, want %s", i+1, lines[i+1], want)
+		}
+	}
+}
+
 func TestListFzf_Empty(t *testing.T) {
 	setupQueueDir(t)
 	opts, _, _ := testOptions()
@@ -260,6 +285,59 @@ func TestPreview_ExistingEntry(t *testing.T) {
 	}
 }
 
+func TestPreview_ShowsSessionDetails(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+
+	if err := testStore().Write(&queue.Entry{
+		Timestamp:      time.Now(),
+		SessionID:      "sess-det",
+		WindowID:       "1",
+		CWD:            "/home/user/proj",
+		Event:          "SessionStart",
+		Source:         "resume",
+		PermissionMode: "plan",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := executeCommand(cmd.NewRootCmd(opts), "_preview", "sess-det")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout, "started: resume  mode: plan") {
+		t.Errorf("output missing session details:\n%s", stdout)
+	}
+}
+
+func TestPreview_UsesTranscriptPath(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+	opts.ClaudeDir = t.TempDir()
+
+	transcript := filepath.Join(t.TempDir(), "elsewhere.jsonl")
+	os.WriteFile(transcript, []byte(`{"type":"user","timestamp":"2026-03-13T10:00:00Z","message":{"content":[{"type":"text","text":"from transcript path"}]}}
+`), 0644)
+	if err := testStore().Write(&queue.Entry{
+		Timestamp:      time.Now(),
+		SessionID:      "sess-tp",
+		WindowID:       "1",
+		CWD:            "/home/user/proj",
+		Event:          "idle_prompt",
+		TranscriptPath: transcript,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := executeCommand(cmd.NewRootCmd(opts), "_preview", "sess-tp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout, "from transcript path") {
+		t.Errorf("output missing conversation from transcript_path:\n%s", stdout)
+	}
+}
+
 func TestPreview_WorkingEntry(t *testing.T) {
 	setupQueueDir(t)
 	opts, _, _ := testOptions()
@@ -453,7 +531,7 @@ func TestJumpInternal_KeepsEntry(t *testing.T) {
 	setupQueueDir(t)
 	opts, _, _ := testOptions()
 
-	// Entry with no KittyWindowID — jump should succeed without removing.
+	// Entry with no WindowID — jump should succeed without removing.
 	seedEntryNoWindow(t, "sess-jump", "/home/user/proj", "permission_prompt", 1001)
 
 	root := cmd.NewRootCmd(opts)
@@ -467,6 +545,56 @@ func TestJumpInternal_KeepsEntry(t *testing.T) {
 	}
 }
 
+func TestJumpInternal_TouchesOnlyCurrentPane(t *testing.T) {
+	setupQueueDir(t)
+	fakeKitty(t)
+	seedWindow(t, "here", "3", "idle_prompt", -300)
+	seedWindow(t, "tmux", "3", "idle_prompt", -300)
+	setPane(t, "tmux", "tmux", "3", "/tmp/tmux-1000/default")
+	seedWindow(t, "target", "5", "permission_prompt", -200)
+	t.Setenv("KITTY_WINDOW_ID", "3")
+	opts, _, _ := testOptions()
+	before := map[string]time.Time{}
+	entries, err := testStore().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		before[e.SessionID] = e.Timestamp
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_jump", "target"); err != nil {
+		t.Fatalf("_jump: %v", err)
+	}
+	after, err := testStore().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range after {
+		touched := !e.Timestamp.Equal(before[e.SessionID])
+		if want := e.SessionID != "tmux"; touched != want {
+			t.Errorf("session %s touched = %v, want %v", e.SessionID, touched, want)
+		}
+	}
+}
+
+func TestList_NoJumpMark(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	seedEntry(t, "in-kitty", "/tmp/a", "permission_prompt", 1001)
+	seedEntryNoWindow(t, "over-ssh", "/tmp/b", "permission_prompt", 1002)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		marked := strings.Contains(line, "no jump")
+		if strings.Contains(line, "/tmp/b") != marked {
+			t.Errorf("line %q: no jump mark = %v", line, marked)
+		}
+	}
+}
+
 func TestJumpInternal_MissingEntry(t *testing.T) {
 	setupQueueDir(t)
 	opts, _, _ := testOptions()
@@ -659,3 +787,19 @@ func TestPreview_ConversationMultiline(t *testing.T) {
 		t.Errorf("icon should be on separate line from text:\n%s", stdout)
 	}
 }
+
+func TestList_InMemoryStore(t *testing.T) {
+	opts, stdout, _ := testOptions()
+	store := queue.NewMemStore()
+	opts.Store = store
+
+	store.Write(&queue.Entry{SessionID: "m1", CWD: "/tmp/mem-project", Event: "permission_prompt", Timestamp: time.Now()})
+
+	root := cmd.NewRootCmd(opts)
+	if _, _, err := executeCommand(root, "list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stdout.String(); !strings.Contains(got, "/tmp/mem-project") {
+		t.Errorf("output missing in-memory entry:\n%s", got)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// parseTimeFlag parses a point in time given either as a duration before now
+// ("90m", "2h", "7d") or as an absolute RFC 3339 timestamp or date.
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := queue.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use a duration like 2h or 7d, or a date like 2006-01-02)", s)
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	return p
+}
+
+func newLogCmd(opts Options) *cobra.Command {
+	var session, cwd, event, since, until, output string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the journal of queue operations",
+		Long: `Show the journal of queue operations.
+
+Every push, pop, touch, jump and removal is appended to a rotating
+journal (journal.jsonl in the queue directory), so the history of a
+session can be reconstructed after it has left the queue.
+
+--since and --until accept a duration before now (90m, 2h, 7d) or a
+date (2006-01-02, 2006-01-02T15:04, RFC 3339). --event matches the
+operation (push, pop, touch, jump, remove), the event type
+(permission_prompt) or its label (PERM).`,
+		Args: cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := opts.TimeNow()
+			filter := queue.JournalFilter{
+				SessionID: session,
+				Event:     event,
+			}
+			if cwd != "" {
+				abs, err := filepath.Abs(expandHome(cwd))
+				if err != nil {
+					return err
+				}
+				filter.CWD = abs
+			}
+			var err error
+			if filter.Since, err = parseTimeFlag(since, now); err != nil {
+				return err
+			}
+			if filter.Until, err = parseTimeFlag(until, now); err != nil {
+				return err
+			}
+
+			records, err := opts.Journal.Read(filter)
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(records) > limit {
+				records = records[len(records)-limit:]
+			}
+
+			if output == "json" {
+				enc := json.NewEncoder(opts.Stdout)
+				for _, r := range records {
+					if err := enc.Encode(r); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if len(records) == 0 {
+				fmt.Fprintln(opts.Stdout, "No journal records")
+				return nil
+			}
+			for _, r := range records {
+				label := "-"
+				if r.Event != "" {
+					label = queue.EventLabel(r.Event)
+				}
+				detail := r.Message
+				if r.Reason != "" {
+					detail = strings.TrimSpace("(" + r.Reason + ") " + r.Message)
+				}
+				fmt.Fprintf(opts.Stdout, "%s  %-6s %-5s  %-8.8s  %s  %s\n",
+					r.Time.Local().Format("2006-01-02 15:04:05"),
+					r.Op, label, r.SessionID, queue.ShortenPath(r.CWD), detail)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "Only records for this session ID (prefix match)")
+	cmd.Flags().StringVar(&cwd, "cwd", "", "Only records in this directory or below")
+	cmd.Flags().StringVar(&event, "event", "", "Only records for this operation, event type or label")
+	cmd.Flags().StringVar(&since, "since", "", "Only records at or after this time (e.g. 2h, 2006-01-02)")
+	cmd.Flags().StringVar(&until, "until", "", "Only records before this time (e.g. 30m, 2006-01-02)")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Show only the last N records (0 = all)")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", `Output format: "text" or "json"`)
+	_ = cmd.RegisterFlagCompletionFunc("session", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sessionIDCompletions(opts.Store, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("cwd", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
+	_ = cmd.RegisterFlagCompletionFunc("event", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{queue.OpPush, queue.OpPop, queue.OpTouch, queue.OpJump, queue.OpRemove, "PERM", "ASK", "DONE", "IDLE"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("since", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("until", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("limit", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
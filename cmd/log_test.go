@@ -0,0 +1,88 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func TestLog_Empty(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	root := cmd.NewRootCmd(opts)
+
+	if _, _, err := executeCommand(root, "log"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stdout.String(); got != "No journal records\n" {
+		t.Errorf("output = %q, want %q", got, "No journal records\n")
+	}
+}
+
+func TestLog_RecordsPushPopAndEnd(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("KITTY_WINDOW_ID", "42")
+
+	for _, step := range []struct{ cmd, input string }{
+		{"push", `{"session_id":"s1","cwd":"/tmp/proj","hook_event_name":"Notification","notification_type":"permission_prompt","message":"Allow?"}`},
+		{"pop", `{"session_id":"s1","cwd":"/tmp/proj"}`},
+		{"end", `{"session_id":"s1","cwd":"/tmp/proj"}`},
+	} {
+		opts, _, _ := testOptionsWithStdin(step.input)
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), step.cmd); err != nil {
+			t.Fatalf("%s: %v", step.cmd, err)
+		}
+	}
+
+	opts, stdout, _ := testOptions()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "log", "--session", "s1"); err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(stdout.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %d:\n%s", len(lines), stdout)
+	}
+	for i, want := range []string{"push", "pop", "remove"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[0], "Allow?") {
+		t.Errorf("push line missing message: %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "(end)") {
+		t.Errorf("remove line missing reason: %q", lines[2])
+	}
+}
+
+func TestLog_FiltersAndJSON(t *testing.T) {
+	setupQueueDir(t)
+	testJournal().Append(queue.JournalRecord{Op: queue.OpPush, SessionID: "a", CWD: "/tmp/one", Event: "idle_prompt"})
+	testJournal().Append(queue.JournalRecord{Op: queue.OpPush, SessionID: "b", CWD: "/tmp/two", Event: "permission_prompt"})
+
+	opts, stdout, _ := testOptions()
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "log", "--event", "PERM", "--cwd", "/tmp/two", "-o", "json")
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	var r queue.JournalRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout.String())), &r); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", stdout, err)
+	}
+	if r.SessionID != "b" {
+		t.Errorf("SessionID = %q, want b", r.SessionID)
+	}
+}
+
+func TestLog_InvalidSince(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "log", "--since", "yesterday-ish")
+	if err == nil {
+		t.Fatal("expected error for invalid --since")
+	}
+}
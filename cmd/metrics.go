@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// collectMetrics snapshots the queue and the journal's running totals.
+func collectMetrics(opts Options) (queue.Metrics, error) {
+	entries, err := opts.Store.List()
+	if err != nil {
+		return queue.Metrics{}, err
+	}
+	counts, err := opts.Journal.Counts()
+	if err != nil {
+		return queue.Metrics{}, err
+	}
+	return queue.CollectMetrics(entries, counts, opts.TimeNow()), nil
+}
+
+// metricsHandler serves a fresh snapshot on every scrape, in OpenMetrics
+// format when the scraper asks for it and in the classic text format otherwise.
+func metricsHandler(opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, err := collectMetrics(opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		var buf bytes.Buffer
+		if err := queue.WriteMetrics(&buf, m, openMetrics); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if openMetrics {
+			w.Header().Set("Content-Type", queue.ContentTypeOpenMetrics)
+		} else {
+			w.Header().Set("Content-Type", queue.ContentTypeText)
+		}
+		w.Write(buf.Bytes())
+	})
+}
+
+// serveMetrics serves /metrics on addr until ctx is cancelled.
+func serveMetrics(ctx context.Context, opts Options, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler(opts))
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	fmt.Fprintf(opts.Stderr, "Serving metrics on http://%s/metrics\n", ln.Addr())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func newMetricsCmd(opts Options) *cobra.Command {
+	var listen, textfile string
+
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Export queue metrics for Prometheus",
+		Long: `Export queue metrics for Prometheus.
+
+Reports gauges for pending entries by event label, the age of the oldest
+entry waiting for you and sessions per terminal socket, plus counters for
+pushes, pops, jumps and stale cleanups kept with the journal (see
+"cc-queue log"). Counters keep counting when the journal rotates.
+
+By default the metrics are printed once in OpenMetrics format.
+
+  --listen :9464             serve /metrics until interrupted
+  --textfile /path/cc.prom   write a file for the node_exporter textfile
+                             collector (run it from a timer or cron)`,
+		Args: cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listen != "" && textfile != "" {
+				return fmt.Errorf("--listen and --textfile are mutually exclusive")
+			}
+			if listen != "" {
+				return serveMetrics(cmd.Context(), opts, listen)
+			}
+
+			m, err := collectMetrics(opts)
+			if err != nil {
+				return err
+			}
+			if textfile != "" {
+				return queue.WriteMetricsFile(expandHome(textfile), m)
+			}
+			return queue.WriteMetrics(opts.Stdout, m, true)
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "", "Serve /metrics on this address (e.g. :9464)")
+	cmd.Flags().StringVar(&textfile, "textfile", "", "Write metrics to this file for the node_exporter textfile collector")
+	_ = cmd.RegisterFlagCompletionFunc("listen", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("textfile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"prom"}, cobra.ShellCompDirectiveFilterFileExt
+	})
+
+	return cmd
+}
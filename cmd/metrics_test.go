@@ -0,0 +1,75 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func TestMetrics_Stdout(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+
+	seedEntry(t, "s1", "/tmp/a", "permission_prompt", 0)
+	seedEntry(t, "s2", "/tmp/b", "idle_prompt", 0)
+	for _, op := range []string{queue.OpPush, queue.OpPush, queue.OpJump} {
+		if err := testJournal().Append(queue.JournalRecord{Op: op, SessionID: "s1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "metrics"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	for _, want := range []string{
+		`cc_queue_pending_entries{event="PERM"} 1`,
+		`cc_queue_pending_entries{event="IDLE"} 1`,
+		"cc_queue_pushes_total 2\n",
+		"cc_queue_jumps_total 1\n",
+		"cc_queue_pops_total 0\n",
+		"# EOF\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestMetrics_Textfile(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 0)
+	path := filepath.Join(t.TempDir(), "cc-queue.prom")
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "metrics", "--textfile", path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no stdout, got %q", stdout.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `cc_queue_pending_entries{event="IDLE"} 1`) {
+		t.Errorf("textfile content:\n%s", data)
+	}
+	if strings.Contains(string(data), "# EOF") {
+		t.Errorf("textfile should use the Prometheus text format:\n%s", data)
+	}
+}
+
+func TestMetrics_ListenAndTextfileExclusive(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "metrics", "--listen", ":0", "--textfile", "/tmp/x.prom")
+	if err == nil {
+		t.Fatal("expected error for --listen with --textfile")
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+// paneLocation is a window or pane. IDs are only unique within a terminal
+// and its socket, so a kitty window 3 and a tmux pane %3 never match.
+type paneLocation struct {
+	terminal, addr, id string
+}
+
+// currentLocation returns the location of the pane this process runs in,
+// with its ID replaced by id when set. Outside a known terminal, id is
+// taken as a kitty window of $KITTY_LISTEN_ON: kitty mappings launch
+// cc-queue in the background, with the socket but no window.
+func currentLocation(id string) paneLocation {
+	loc := paneLocation{terminal: terminal.KittyName, addr: os.Getenv("KITTY_LISTEN_ON"), id: id}
+	if t, p, ok := terminal.Detect(); ok {
+		loc = paneLocation{terminal: t.Name(), addr: p.Addr, id: p.ID}
+		if id != "" {
+			loc.id = id
+		}
+	}
+	return loc
+}
+
+// holds reports whether the session of e runs at l. Entries queued before
+// the terminal was recorded ran in kitty.
+func (l paneLocation) holds(e *queue.Entry) bool {
+	term := e.Terminal
+	if term == "" {
+		term = terminal.KittyName
+	}
+	return l.id != "" && e.WindowID == l.id && e.ListenOn == l.addr && term == l.terminal
+}
+
+// stepEntry returns the session step places away from the one at from,
+// among entries in picker order, wrapping around. When from is not in the
+// list, next starts at the first entry and prev at the last.
+// It returns nil when there is nowhere else to go.
+func stepEntry(entries []*queue.Entry, from paneLocation, step int) *queue.Entry {
+	n := len(entries)
+	cur := -1
+	for i, e := range entries {
+		if from.holds(e) {
+			cur = i
+			break
+		}
+	}
+	switch {
+	case cur >= 0 && n == 1:
+		return nil
+	case cur >= 0:
+		return entries[((cur+step)%n+n)%n]
+	case n == 0:
+		return nil
+	case step > 0:
+		return entries[0]
+	default:
+		return entries[n-1]
+	}
+}
+
+// stepRunE jumps to the next (step 1) or previous (step -1) session needing
+// attention. Unlike first, it touches no entry, so the order stays stable
+// while cycling.
+func stepRunE(opts Options, step int) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		fromID, _ := cmd.Flags().GetString("from")
+		from := currentLocation(fromID)
+
+		entries, err := opts.Store.List()
+		if err != nil {
+			return err
+		}
+		var pending []*queue.Entry
+		for _, e := range pickerEntries(entries, opts.TimeNow()) {
+			if queue.NeedsAttention(e.Event) && e.WindowID != "" {
+				pending = append(pending, e)
+			}
+		}
+		sortForPicker(pending, opts.Branches)
+
+		target := stepEntry(pending, from, step)
+		if target == nil {
+			return nil
+		}
+		queue.Debugf("STEP from=%s step=%d session=%s", from.id, step, target.SessionID)
+		if err := focusEntry(opts, target); err != nil {
+			return err
+		}
+		opts.Journal.Record(queue.OpJump, target, "", time.Now())
+		return nil
+	}
+}
+
+func newStepCmd(opts Options, use, short string, step int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: stepRunE(opts, step),
+	}
+	cmd.Flags().String("from", "", "Window or pane ID to step from (default: the current one)")
+	_ = cmd.RegisterFlagCompletionFunc("from", cobra.NoFileCompletions)
+	return cmd
+}
+
+func newNextCmd(opts Options) *cobra.Command {
+	return newStepCmd(opts, "next", "Jump to the next session needing attention", 1)
+}
+
+func newPrevCmd(opts Options) *cobra.Command {
+	return newStepCmd(opts, "prev", "Jump to the previous session needing attention", -1)
+}
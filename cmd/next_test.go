@@ -0,0 +1,170 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// fakeKitty puts a kitty stub first in PATH that logs its arguments and
+// succeeds. It returns a function reading the logged invocations.
+func fakeKitty(t *testing.T) func() []string {
+	t.Helper()
+	return fakeCLI(t, "kitty")
+}
+
+// fakeCLI is fakeKitty for any command name.
+func fakeCLI(t *testing.T, name string) func() []string {
+	t.Helper()
+	return fakeCLIOutput(t, name, "")
+}
+
+// fakeCLIOutput is fakeCLI with a stub that prints output on every call.
+func fakeCLIOutput(t *testing.T, name, output string) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\n"
+	if output != "" {
+		script += "echo '" + output + "'\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return func() []string {
+		data, _ := os.ReadFile(logPath)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+func seedWindow(t *testing.T, sessionID, wid, event string, offsetSec int) {
+	t.Helper()
+	seedEntryAtTime(t, sessionID, "/tmp/"+sessionID, event, 1, offsetSec)
+	if err := testStore().Update(sessionID, func(e *queue.Entry) error {
+		e.WindowID = wid
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNextPrev_Cycle(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	seedWindow(t, "a", "1", "permission_prompt", -300)
+	seedWindow(t, "b", "2", "permission_prompt", -200)
+	seedWindow(t, "c", "3", "idle_prompt", -100)
+	seedWindow(t, "busy", "4", "working", -50)
+	opts, _, _ := testOptions()
+	before := map[string]time.Time{}
+	entries, err := testStore().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		before[e.SessionID] = e.Timestamp
+	}
+
+	steps := []struct {
+		cmd, from, want string
+	}{
+		{"next", "1", "id:2"},
+		{"next", "2", "id:3"},
+		{"next", "3", "id:1"}, // wraps around
+		{"prev", "1", "id:3"}, // wraps around
+		{"prev", "3", "id:2"},
+		{"next", "9", "id:1"}, // not in the queue: start at the top
+		{"prev", "4", "id:3"}, // busy sessions are not in the cycle
+	}
+	for i, st := range steps {
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), st.cmd, "--from", st.from); err != nil {
+			t.Fatalf("%s --from %s: %v", st.cmd, st.from, err)
+		}
+		got := calls()
+		if len(got) != i+1 || !strings.HasSuffix(got[i], "focus-window --match "+st.want) {
+			t.Fatalf("%s --from %s: kitty calls = %q, want focus %s", st.cmd, st.from, got, st.want)
+		}
+	}
+
+	// Cycling touches nothing, so the order stays stable.
+	after, err := testStore().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range after {
+		if !e.Timestamp.Equal(before[e.SessionID]) {
+			t.Errorf("session %s was touched by next/prev", e.SessionID)
+		}
+	}
+}
+
+func TestNext_OnlyCurrentSession(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	seedWindow(t, "a", "1", "permission_prompt", -300)
+	t.Setenv("KITTY_WINDOW_ID", "1")
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "next"); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if got := calls(); len(got) != 1 || got[0] != "" {
+		t.Errorf("expected no kitty call, got %q", got)
+	}
+}
+
+func TestNext_SameIDInAnotherTerminal(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	seedWindow(t, "a", "1", "permission_prompt", -300)
+	seedWindow(t, "b", "2", "permission_prompt", -200)
+	seedWindow(t, "c", "1", "permission_prompt", -400)
+	setPane(t, "c", "wezterm", "1", "")
+	t.Setenv("KITTY_WINDOW_ID", "2")
+	opts, _, _ := testOptions()
+
+	// The user is in kitty window 2; WezTerm pane 1 is not kitty window 1.
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "next", "--from", "1"); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if got := calls(); len(got) != 1 || !strings.HasSuffix(got[0], "focus-window --match id:2") {
+		t.Errorf("kitty calls = %q, want focus id:2 after kitty window 1", got)
+	}
+}
+
+func TestNext_FromKittyMapping(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	const sock = "unix:/nonexistent/kitty-1"
+	for _, s := range []struct {
+		id, wid string
+		offset  int
+	}{{"a", "1", -300}, {"b", "2", -200}, {"c", "3", -100}} {
+		seedWindow(t, s.id, s.wid, "permission_prompt", s.offset)
+		setPane(t, s.id, "kitty", s.wid, sock)
+	}
+	// launch --type=background passes the socket but no window.
+	t.Setenv("KITTY_LISTEN_ON", sock)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "next", "--from", "2"); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if got := calls(); len(got) != 1 || !strings.HasSuffix(got[0], "focus-window --match id:3") {
+		t.Errorf("kitty calls = %q, want focus id:3 after window 2", got)
+	}
+}
+
+func TestNext_EmptyQueue(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "next"); err != nil {
+		t.Fatalf("next with empty queue: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/notify"
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+// notifyWait bounds how long _notify waits for the user to act on a
+// notification before giving up.
+const notifyWait = 30 * time.Minute
+
+// actionFocus is the notification button that jumps to the session.
+const actionFocus = "focus"
+
+// scheduleNotify queues a notification for entry, which just entered its
+// event, when notifications are enabled for it, and spawns a background
+// _notify to send it.
+func scheduleNotify(opts Options, entry *queue.Entry) {
+	if opts.Spawn == nil {
+		return
+	}
+	cfg := queue.ReadConfig()
+	if !cfg.Notify.Wants(entry.Event) {
+		return
+	}
+	if cfg.DNDMutes(entry.CWD, opts.TimeNow()) {
+		queue.Debugf("NOTIFY skip: do not disturb session=%s", entry.SessionID)
+		return
+	}
+	if err := notify.Enqueue(queue.Dir(), entry.SessionID); err != nil {
+		queue.Debugf("NOTIFY enqueue failed: %v", err)
+		return
+	}
+	if err := opts.Spawn("_notify"); err != nil {
+		queue.Debugf("NOTIFY spawn failed: %v", err)
+	}
+}
+
+// notifyEntries returns the entries of sessionIDs that still call for a
+// notification, in picker order.
+func notifyEntries(opts Options, cfg queue.Config, sessionIDs []string) []*queue.Entry {
+	now := opts.TimeNow()
+	var entries []*queue.Entry
+	for _, id := range sessionIDs {
+		sf, err := opts.Store.ReadSession(id)
+		if err != nil || sf.Current == nil {
+			continue
+		}
+		e := sf.Current
+		if !cfg.Notify.Wants(e.Event) || e.IsSnoozed(now) || cfg.DNDMutes(e.CWD, now) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sortForPicker(entries, opts.Branches)
+	return entries
+}
+
+// buildNotification describes entries in one notification, with a focus
+// action for the first of them.
+func buildNotification(entries []*queue.Entry) notify.Notification {
+	n := notify.Notification{
+		Actions: []notify.Action{
+			// Clicking the notification itself; servers showing it as a
+			// button would otherwise show Focus twice.
+			{Key: notify.ActionDefault, Label: ""},
+			{Key: actionFocus, Label: "Focus"},
+		},
+	}
+	if len(entries) == 1 {
+		e := entries[0]
+		n.Summary = queue.EventLabel(e.Event) + "  " + queue.ShortenPath(e.CWD)
+		n.Body = e.Message
+		if e.Event == "permission_prompt" && e.Tool != nil {
+			n.Body = e.Tool.OneLine(200)
+		}
+		return n
+	}
+	n.Summary = fmt.Sprintf("%d sessions need you", len(entries))
+	var lines []string
+	for _, e := range entries {
+		lines = append(lines, queue.EventLabel(e.Event)+"  "+queue.ShortenPath(e.CWD))
+	}
+	n.Body = strings.Join(lines, "\n")
+	return n
+}
+
+func newNotifyCmd(opts Options) *cobra.Command {
+	return &cobra.Command{
+		Use:    "_notify",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Notifier == nil {
+				return nil
+			}
+			cfg := queue.ReadConfig()
+			// Let the rest of a burst queue up; the first process to wake
+			// claims it all and the others find nothing left.
+			time.Sleep(cfg.Notify.CoalesceDuration())
+			ids, err := notify.Claim(queue.Dir())
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+
+			// The config may have changed while we waited.
+			cfg = queue.ReadConfig()
+			entries := notifyEntries(opts, cfg, ids)
+			if len(entries) == 0 {
+				return nil
+			}
+			queue.Debugf("NOTIFY sessions=%d first=%s", len(entries), entries[0].SessionID)
+
+			return notifyAndFocus(cmd.Context(), opts, buildNotification(entries), entries[0].SessionID)
+		},
+	}
+}
+
+// notifyAndFocus shows n and jumps to the session sessionID when the user
+// clicks it, waiting at most notifyWait.
+func notifyAndFocus(ctx context.Context, opts Options, n notify.Notification, sessionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyWait)
+	defer cancel()
+	action, err := opts.Notifier.Notify(ctx, n)
+	if err != nil {
+		queue.Debugf("NOTIFY failed: %v", err)
+		return err
+	}
+	if action != actionFocus && action != notify.ActionDefault {
+		return nil
+	}
+
+	// The session may have been answered or removed meanwhile.
+	sf, err := opts.Store.ReadSession(sessionID)
+	if err != nil || sf.Current == nil || !queue.NeedsAttention(sf.Current.Event) {
+		queue.Debugf("NOTIFY session=%s no longer waiting", sessionID)
+		return nil
+	}
+	// Background processes inherit the window of the hook that spawned
+	// them, which is not where the user is.
+	terminal.ForgetCurrent()
+	return jumpToEntry(opts, sf.Current)
+}
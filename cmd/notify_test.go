@@ -0,0 +1,239 @@
+package cmd_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/notify"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// fakeNotifier records notifications and answers them with action, after
+// running before when set.
+type fakeNotifier struct {
+	action string
+	before func()
+	sent   []notify.Notification
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n notify.Notification) (string, error) {
+	f.sent = append(f.sent, n)
+	if f.before != nil {
+		f.before()
+	}
+	return f.action, nil
+}
+
+// writeNotifyConfig enables notifications without a coalescing delay.
+func writeNotifyConfig(t *testing.T, cfg queue.Config) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg.Notify.Enabled = true
+	if cfg.Notify.Coalesce == "" {
+		cfg.Notify.Coalesce = "0s"
+	}
+	if err := queue.WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pushWithSpawn pushes a notification event for sess-a and returns the
+// arguments of every spawned process.
+func pushWithSpawn(t *testing.T, notificationType string) [][]string {
+	t.Helper()
+	t.Setenv("KITTY_WINDOW_ID", "42")
+	opts, _, _ := testOptionsWithStdin(`{"session_id":"sess-a","cwd":"/tmp/a","hook_event_name":"Notification","notification_type":"` + notificationType + `"}`)
+	var spawned [][]string
+	opts.Spawn = func(args ...string) error {
+		spawned = append(spawned, args)
+		return nil
+	}
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "push"); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	return spawned
+}
+
+func TestPush_SchedulesNotification(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{})
+
+	spawned := pushWithSpawn(t, "permission_prompt")
+	if len(spawned) != 1 || strings.Join(spawned[0], " ") != "_notify" {
+		t.Fatalf("spawned %q, want _notify", spawned)
+	}
+	ids, err := notify.Claim(queue.Dir())
+	if err != nil || len(ids) != 1 || ids[0] != "sess-a" {
+		t.Errorf("pending = %q, %v; want sess-a", ids, err)
+	}
+}
+
+func TestPush_NotificationSkipped(t *testing.T) {
+	tests := []struct {
+		name  string
+		prev  string
+		event string
+		cfg   queue.Config
+	}{
+		{"event not enabled", "", "permission_prompt", queue.Config{Notify: queue.Notify{Events: []string{"ASK"}}}},
+		{"already in that event", "permission_prompt", "permission_prompt", queue.Config{}},
+		{"do not disturb", "", "permission_prompt", queue.Config{DND: true}},
+		{"outside the dnd allowlist", "", "permission_prompt", queue.Config{DND: true, DNDAllow: []string{"/tmp/b/**"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupQueueDir(t)
+			writeNotifyConfig(t, tt.cfg)
+			if tt.prev != "" {
+				seedEntry(t, "sess-a", "/tmp/a", tt.prev, 1)
+			}
+			if spawned := pushWithSpawn(t, tt.event); len(spawned) != 0 {
+				t.Errorf("spawned %q, want nothing", spawned)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		setupQueueDir(t)
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		if spawned := pushWithSpawn(t, "permission_prompt"); len(spawned) != 0 {
+			t.Errorf("spawned %q, want nothing", spawned)
+		}
+	})
+
+	t.Run("in the dnd allowlist", func(t *testing.T) {
+		setupQueueDir(t)
+		writeNotifyConfig(t, queue.Config{DND: true, DNDAllow: []string{"/tmp/a/**"}})
+		if spawned := pushWithSpawn(t, "permission_prompt"); len(spawned) != 1 {
+			t.Errorf("spawned %q, want _notify", spawned)
+		}
+	})
+}
+
+func TestNotify_Single(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{})
+	seedEntryWithMessage(t, "sess-a", "/tmp/a", "elicitation_dialog", 1, "Which region?")
+	if err := notify.Enqueue(queue.Dir(), "sess-a"); err != nil {
+		t.Fatal(err)
+	}
+	opts, _, _ := testOptions()
+	n := &fakeNotifier{}
+	opts.Notifier = n
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_notify"); err != nil {
+		t.Fatalf("_notify: %v", err)
+	}
+	if len(n.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(n.sent))
+	}
+	if got := n.sent[0]; got.Summary != "ASK  /tmp/a" || got.Body != "Which region?" {
+		t.Errorf("notification = %+v", got)
+	}
+	var buttons []string
+	for _, a := range n.sent[0].Actions {
+		if a.Label != "" {
+			buttons = append(buttons, a.Label)
+		}
+	}
+	if !slices.Equal(buttons, []string{"Focus"}) {
+		t.Errorf("buttons = %q, want a single Focus", buttons)
+	}
+}
+
+func TestNotify_CoalescesBurst(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -60)
+	seedEntryAtTime(t, "sess-b", "/tmp/b", "idle_prompt", 2, -30)
+	seedEntryAtTime(t, "sess-c", "/tmp/c", "working", 3, -10) // answered since
+	for _, id := range []string{"sess-b", "sess-a", "sess-c", "sess-a"} {
+		if err := notify.Enqueue(queue.Dir(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts, _, _ := testOptions()
+	n := &fakeNotifier{}
+	opts.Notifier = n
+
+	// Two processes were spawned for the burst; one sends it all.
+	for range 2 {
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_notify"); err != nil {
+			t.Fatalf("_notify: %v", err)
+		}
+	}
+	if len(n.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(n.sent))
+	}
+	got := n.sent[0]
+	if got.Summary != "2 sessions need you" {
+		t.Errorf("summary = %q", got.Summary)
+	}
+	if got.Body != "PERM  /tmp/a\nIDLE  /tmp/b" {
+		t.Errorf("body = %q", got.Body)
+	}
+}
+
+func TestNotify_FocusAction(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{})
+	calls := fakeKitty(t)
+	seedEntry(t, "sess-a", "/tmp/a", "permission_prompt", 1)
+	if err := notify.Enqueue(queue.Dir(), "sess-a"); err != nil {
+		t.Fatal(err)
+	}
+	opts, _, _ := testOptions()
+	opts.Notifier = &fakeNotifier{action: "focus"}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_notify"); err != nil {
+		t.Fatalf("_notify: %v", err)
+	}
+	if got := calls(); len(got) != 1 || !strings.HasSuffix(got[0], "focus-window --match id:42") {
+		t.Errorf("kitty calls = %q, want focus id:42", got)
+	}
+}
+
+func TestNotify_FocusActionAfterAnswer(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{})
+	calls := fakeKitty(t)
+	seedEntry(t, "sess-a", "/tmp/a", "permission_prompt", 1)
+	if err := notify.Enqueue(queue.Dir(), "sess-a"); err != nil {
+		t.Fatal(err)
+	}
+	opts, _, _ := testOptions()
+	opts.Notifier = &fakeNotifier{action: "focus", before: func() {
+		// Answered in the terminal while the notification was up.
+		if err := testStore().Update("sess-a", func(e *queue.Entry) error {
+			e.Event = "working"
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_notify"); err != nil {
+		t.Fatalf("_notify: %v", err)
+	}
+	if got := calls(); len(got) != 1 || got[0] != "" {
+		t.Errorf("kitty calls = %q, want none for an answered session", got)
+	}
+}
+
+func TestNotify_NothingPending(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{})
+	opts, _, _ := testOptions()
+	n := &fakeNotifier{}
+	opts.Notifier = n
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_notify"); err != nil {
+		t.Fatalf("_notify: %v", err)
+	}
+	if len(n.sent) != 0 {
+		t.Errorf("sent %d notifications, want 0", len(n.sent))
+	}
+}
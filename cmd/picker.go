@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/tui"
+)
+
+// Picker UIs selectable with --ui or the "ui" config key.
+const (
+	uiAuto   = "auto"
+	uiFzf    = "fzf"
+	uiNative = "native"
+)
+
+// nativeHeader is the key help shown by the native picker.
+const nativeHeader = "cc-queue — enter=jump  tab=shell  ctrl-r=refresh  esc=quit\n" + bulkHeader
+
+// bulkKey is a bulk action bound to a picker key.
+type bulkKey struct {
+	action    string
+	snoozeFor time.Duration
+}
+
+// bulkKeys maps picker keys to the bulk action they apply.
+var bulkKeys = map[string]bulkKey{
+	"alt-d": {action: actionDismiss},
+	"alt-s": {action: actionSnooze},
+	"alt-h": {action: actionSnooze, snoozeFor: pickerSnoozeFor},
+	"alt-r": {action: actionRead},
+	"alt-t": {action: actionTabs},
+	"alt-p": {action: actionPin},
+	"alt-k": {action: actionRaise},
+	"alt-j": {action: actionLower},
+}
+
+// ageRefreshInterval is how often an open picker reloads even when the
+// queue is unchanged, so the age column keeps ticking.
+const ageRefreshInterval = 5 * time.Second
+
+// resolveUI picks the picker implementation. "auto" (or empty) uses fzf
+// when it is installed and the native picker otherwise.
+func resolveUI(ui string) (string, error) {
+	switch ui {
+	case "", uiAuto:
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return uiFzf, nil
+		}
+		return uiNative, nil
+	case uiFzf:
+		if _, err := exec.LookPath("fzf"); err != nil {
+			return "", fmt.Errorf("fzf not found in PATH (use --ui native)")
+		}
+		return uiFzf, nil
+	case uiNative:
+		return uiNative, nil
+	default:
+		return "", fmt.Errorf("invalid --ui %q (use auto, fzf or native)", ui)
+	}
+}
+
+// watchQueue returns a channel signalled when the queue directory changes,
+// or nil (which never fires) when it cannot be watched.
+func watchQueue(ctx context.Context) <-chan struct{} {
+	if err := queue.EnsureDir(); err != nil {
+		queue.Debugf("WATCH disabled: %v", err)
+		return nil
+	}
+	changes, err := queue.Watch(ctx, queue.Dir())
+	if err != nil {
+		queue.Debugf("WATCH disabled: %v", err)
+		return nil
+	}
+	return changes
+}
+
+// fzfServer is the HTTP control API of one fzf instance. fzf picks a free
+// loopback port itself and its start action writes $FZF_PORT to a private
+// file, so no other process can take the port first. Every request must
+// carry a key generated for this instance, so other local processes cannot
+// drive fzf into executing commands.
+type fzfServer struct {
+	key  string
+	dir  string
+	addr string // read from the port file once fzf has written it
+}
+
+// newFzfServer generates the API key and the private directory of the port
+// file.
+func newFzfServer() (*fzfServer, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "cc-queue-fzf")
+	if err != nil {
+		return nil, err
+	}
+	return &fzfServer{key: hex.EncodeToString(b), dir: dir}, nil
+}
+
+// portFile is where fzf's start action writes its port.
+func (s *fzfServer) portFile() string {
+	return filepath.Join(s.dir, "port")
+}
+
+// args are the fzf options starting the server.
+func (s *fzfServer) args() []string {
+	return []string{
+		"--listen=127.0.0.1:0",
+		"--bind=start:execute-silent(echo $FZF_PORT > " + shellQuote(s.portFile()) + ")",
+	}
+}
+
+// env is the fzf environment requiring the key.
+func (s *fzfServer) env() []string {
+	return append(os.Environ(), "FZF_API_KEY="+s.key)
+}
+
+// close removes the port file.
+func (s *fzfServer) close() {
+	os.RemoveAll(s.dir)
+}
+
+// request sends an HTTP request for path to fzf, with the API key. It fails
+// until fzf has written its port.
+func (s *fzfServer) request(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	if s.addr == "" {
+		port, err := os.ReadFile(s.portFile())
+		if err != nil || strings.TrimSpace(string(port)) == "" {
+			return nil, fmt.Errorf("fzf port not known yet")
+		}
+		s.addr = "127.0.0.1:" + strings.TrimSpace(string(port))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+s.addr+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Api-Key", s.key)
+	return http.DefaultClient.Do(req)
+}
+
+// fzfHasSelection reports whether items are marked in fzf. A reload clears
+// fzf's selection, so live reloads wait until the user is done with it.
+func fzfHasSelection(ctx context.Context, srv *fzfServer) bool {
+	resp, err := srv.request(ctx, http.MethodGet, "/?limit=1", nil)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	var state struct {
+		Selected []json.RawMessage `json:"selected"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&state) != nil {
+		return false
+	}
+	return len(state.Selected) > 0
+}
+
+// fzfLiveReload sends a reload action to the fzf instance behind srv
+// whenever the queue changes and every ageRefreshInterval, until ctx is done.
+// Failed requests are ignored: fzf may not be listening yet, or just exited.
+func fzfLiveReload(ctx context.Context, srv *fzfServer, reloadCmd string) {
+	changes := watchQueue(ctx)
+	tick := time.NewTicker(ageRefreshInterval)
+	defer tick.Stop()
+	action := "reload-sync(" + reloadCmd + ")"
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		case <-tick.C:
+		}
+		if fzfHasSelection(ctx, srv) {
+			continue
+		}
+		resp, err := srv.request(ctx, http.MethodPost, "/", strings.NewReader(action))
+		if err != nil {
+			queue.Debugf("FZF reload failed: %v", err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
+
+// picker couples the native picker model with the queue entries it shows.
+type picker struct {
+	opts      Options
+	model     *tui.Picker
+	entries   map[string]*queue.Entry
+	previewID string
+}
+
+// reload refreshes the list from the store and the preview of the selection.
+func (p *picker) reload() {
+	entries, err := p.opts.Store.List()
+	if err != nil {
+		p.model.Status = "⚠ " + err.Error()
+		return
+	}
+	entries = pickerEntries(entries, p.opts.TimeNow())
+	sortForPicker(entries, p.opts.Branches)
+	rows, maxPath := buildRows(entries, p.opts.Branches)
+	items := make([]tui.Item, len(rows))
+	p.entries = make(map[string]*queue.Entry, len(entries))
+	for i, r := range rows {
+		items[i] = tui.Item{ID: r.sessionID, Text: r.text(maxPath)}
+		p.entries[r.sessionID] = entries[i]
+	}
+	p.model.Columns = rowsHeader(maxPath)
+	p.model.SetItems(items)
+	p.previewID = "" // ages and conversation may have changed
+	p.updatePreview()
+}
+
+// updatePreview re-renders the preview when the selection changed.
+func (p *picker) updatePreview() {
+	sel, ok := p.model.Selected()
+	if !ok {
+		p.previewID = ""
+		p.model.SetPreview("")
+		return
+	}
+	if sel.ID == p.previewID {
+		return
+	}
+	var buf bytes.Buffer
+	writePreview(&buf, p.opts, sel.ID)
+	p.model.SetPreview(buf.String())
+	p.previewID = sel.ID
+}
+
+// selected returns the entry under the cursor.
+func (p *picker) selected() *queue.Entry {
+	sel, ok := p.model.Selected()
+	if !ok {
+		return nil
+	}
+	return p.entries[sel.ID]
+}
+
+// firstLine returns the first line of an error message for the status line.
+func firstLine(err error) string {
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return msg
+}
+
+// jumpFailedStatus describes a failed jump in the picker's status line,
+// telling when the entry was removed for it.
+func jumpFailedStatus(err error) string {
+	if errors.As(err, new(removedError)) {
+		return "⚠ " + firstLine(err) + " — entry removed"
+	}
+	return "⚠ " + firstLine(err)
+}
+
+// runNativePicker runs the built-in picker on the controlling terminal until
+// the user jumps to a session, opens a shell, or quits.
+func runNativePicker(opts Options) error {
+	term, err := tui.Open()
+	if err != nil {
+		return fmt.Errorf("native picker needs a terminal: %w", err)
+	}
+	defer term.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := watchQueue(ctx)
+
+	header := nativeHeader
+	if notice := dndNotice(queue.ReadConfig(), opts.TimeNow()); notice != "" {
+		header += "\n" + notice
+	}
+	p := &picker{opts: opts, model: tui.NewPicker("Jump to session> ", header)}
+	p.reload()
+
+	keys := term.Keys()
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+	tick := time.NewTicker(ageRefreshInterval)
+	defer tick.Stop()
+
+	for {
+		if w, h, err := term.Size(); err == nil {
+			term.Write([]byte(p.model.Render(w, h)))
+		}
+
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch key {
+			case "esc", "ctrl-c", "ctrl-g", "ctrl-q":
+				return nil
+			case "enter":
+				e := p.selected()
+				if e == nil {
+					continue
+				}
+				if e.WindowID == "" {
+					p.model.Status = noJumpMessage(opts, e)
+					continue
+				}
+				if err := jumpToEntry(opts, e); err != nil {
+					p.model.Status = jumpFailedStatus(err)
+					p.reload()
+					continue
+				}
+				return nil
+			case "tab":
+				e := p.selected()
+				if e == nil {
+					continue
+				}
+				if err := launchShell(e); err != nil {
+					p.model.Status = "⚠ " + firstLine(err)
+					continue
+				}
+				return nil
+			case "alt-a":
+				p.model.ToggleAll()
+			case "alt-d", "alt-s", "alt-h", "alt-r", "alt-t", "alt-p", "alt-k", "alt-j":
+				var ids []string
+				for _, it := range p.model.Marked() {
+					ids = append(ids, it.ID)
+				}
+				p.model.Status = ""
+				bk := bulkKeys[key]
+				if err := applyAction(opts, bk.action, ids, bk.snoozeFor); err != nil {
+					p.model.Status = "⚠ " + firstLine(err)
+				}
+				p.model.ClearMarks()
+				p.reload()
+			case "ctrl-r":
+				if opts.CleanStaleWindowsFn != nil {
+					opts.CleanStaleWindowsFn()
+				}
+				p.model.Status = ""
+				p.reload()
+			default:
+				p.model.HandleKey(key)
+				p.updatePreview()
+			}
+		case <-changes:
+			p.reload()
+		case <-tick.C:
+			p.reload()
+		case <-resize:
+		}
+	}
+}
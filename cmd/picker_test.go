@@ -0,0 +1,47 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func TestJump_InvalidUI(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+	root := cmd.NewRootCmd(opts)
+
+	_, _, err := executeCommand(root, "--ui", "bogus")
+	if err == nil || !strings.Contains(err.Error(), `invalid --ui "bogus"`) {
+		t.Fatalf("expected invalid --ui error, got %v", err)
+	}
+}
+
+func TestJump_FzfMissing(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("PATH", t.TempDir())
+	opts, _, _ := testOptions()
+	root := cmd.NewRootCmd(opts)
+
+	_, _, err := executeCommand(root, "--ui", "fzf")
+	if err == nil || !strings.Contains(err.Error(), "fzf not found") {
+		t.Fatalf("expected fzf not found error, got %v", err)
+	}
+}
+
+func TestJump_InvalidUIFromConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	setupQueueDir(t)
+	if err := queue.WriteConfig(queue.Config{UI: "curses"}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+	opts, _, _ := testOptions()
+	root := cmd.NewRootCmd(opts)
+
+	_, _, err := executeCommand(root)
+	if err == nil || !strings.Contains(err.Error(), `invalid --ui "curses"`) {
+		t.Fatalf("expected invalid ui error from config, got %v", err)
+	}
+}
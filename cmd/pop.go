@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/duboisf/cc-queue/internal/queue"
 	"github.com/spf13/cobra"
 )
@@ -21,27 +19,30 @@ func newPopCmd(opts Options) *cobra.Command {
 				return err
 			}
 
-			kittyWinID := os.Getenv("KITTY_WINDOW_ID")
-			if kittyWinID == "" {
-				// Outside kitty — fall back to removing the entry.
-				queue.Debugf("POP session=%s (no kitty, removing)", input.SessionID)
-				queue.Remove(input.SessionID)
-				return nil
-			}
+			// Answering a prompt is what auto-advance moves on from.
+			prev, _ := opts.Store.ReadSession(input.SessionID)
+			answered := prev != nil && prev.Current != nil && queue.NeedsAttention(prev.Current.Event)
 
-			// In kitty — mark session as working.
+			// Mark session as working.
 			entry := &queue.Entry{
-				Timestamp:     opts.TimeNow(),
-				SessionID:     input.SessionID,
-				KittyWindowID: kittyWinID,
-				KittyListenOn: os.Getenv("KITTY_LISTEN_ON"),
-				PID:           queue.AncestorPID(),
-				CWD:           input.CWD,
-				Event:         "working",
+				Timestamp: opts.TimeNow(),
+				SessionID: input.SessionID,
+				PID:       queue.AncestorPID(),
+				CWD:       input.CWD,
+				Event:     "working",
 			}
+			setLocation(entry)
+			input.ApplyTo(entry)
 
 			queue.Debugf("POP session=%s -> working", input.SessionID)
-			return queue.Write(entry)
+			if err := opts.Store.Write(entry); err != nil {
+				return err
+			}
+			opts.Journal.Record(queue.OpPop, entry, "", entry.Timestamp)
+			if answered {
+				scheduleAdvance(opts, entry.WindowID)
+			}
+			return nil
 		},
 	}
 }
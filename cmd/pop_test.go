@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/duboisf/cc-queue/cmd"
-	"github.com/duboisf/cc-queue/internal/queue"
 )
 
 func TestPop_MarksAsWorking(t *testing.T) {
@@ -27,7 +26,7 @@ func TestPop_MarksAsWorking(t *testing.T) {
 		t.Fatalf("expected 1 entry after pop, got %d", n)
 	}
 
-	entries, _ := queue.List()
+	entries, _ := testStore().List()
 	if entries[0].Event != "working" {
 		t.Errorf("expected event=working, got %q", entries[0].Event)
 	}
@@ -50,7 +49,7 @@ func TestPop_CreatesWorkingEntryForNewSession(t *testing.T) {
 		t.Fatalf("expected 1 entry, got %d", n)
 	}
 
-	entries, _ := queue.List()
+	entries, _ := testStore().List()
 	if entries[0].Event != "working" {
 		t.Errorf("expected event=working, got %q", entries[0].Event)
 	}
@@ -62,9 +61,9 @@ func TestPop_CreatesWorkingEntryForNewSession(t *testing.T) {
 	}
 }
 
-func TestPop_RemovesEntryWithoutKittyWindowID(t *testing.T) {
+func TestPop_MarksWorkingOutsideTerminal(t *testing.T) {
 	setupQueueDir(t)
-	// No KITTY_WINDOW_ID — falls back to removing the entry.
+	// No KITTY_WINDOW_ID — the session is still tracked, without a window.
 	t.Setenv("KITTY_WINDOW_ID", "")
 
 	seedEntry(t, "test-sess", "/tmp/project", "permission_prompt", 1)
@@ -80,8 +79,12 @@ func TestPop_RemovesEntryWithoutKittyWindowID(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if n := entryCount(t); n != 0 {
-		t.Fatalf("expected 0 entries after pop without kitty, got %d", n)
+	sf, err := testStore().ReadSession("test-sess")
+	if err != nil {
+		t.Fatalf("expected the session to stay queued: %v", err)
+	}
+	if sf.Current.Event != "working" || sf.Current.WindowID != "" {
+		t.Errorf("event=%q wid=%q, want working without a window", sf.Current.Event, sf.Current.WindowID)
 	}
 }
 
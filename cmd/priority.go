@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+func newPriorityCmd(opts Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "priority <session_id> <level>",
+		Short: "Set a session's priority (pinned, high, normal, low)",
+		Long: `Set a session's priority (pinned, high, normal, low).
+
+Priority comes first when sorting the picker, list and first: a pinned
+session stays above everything else whatever its state, and a low one
+sinks below normal sessions. It is kept across the session's events
+until changed.`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return sessionIDCompletions(opts.Store, toComplete), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return queue.Priorities, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID := args[0]
+			level, err := queue.ParsePriority(args[1])
+			if err != nil {
+				return err
+			}
+			err = setPriority(opts, sessionID, func(string) string { return level })
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("session %s is not in the queue", sessionID)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(opts.Stdout, "Priority of %s set to %s\n", sessionID, args[1])
+			return nil
+		},
+	}
+}
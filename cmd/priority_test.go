@@ -0,0 +1,124 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+)
+
+func TestPriority_SortsAboveAttention(t *testing.T) {
+	setupQueueDir(t)
+	seedEntryAtTime(t, "perm", "/tmp/perm", "permission_prompt", 1, -60)
+	seedEntryAtTime(t, "migration", "/tmp/migration", "working", 2, -10)
+	seedEntryAtTime(t, "chore", "/tmp/chore", "permission_prompt", 3, -120)
+	opts, _, _ := testOptions()
+
+	for _, args := range [][]string{
+		{"priority", "migration", "pinned"},
+		{"priority", "chore", "low"},
+	} {
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(listOutput(t, opts)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header + 3 rows, got:\n%s", strings.Join(lines, "\n"))
+	}
+	for i, want := range []string{"/tmp/migration", "/tmp/perm", "/tmp/chore"} {
+		if !strings.Contains(lines[i+1], want) {
+			t.Errorf("row %d = %q, want %s", i, lines[i+1], want)
+		}
+	}
+	if !strings.HasPrefix(lines[1], "⇈") {
+		t.Errorf("pinned row should be marked: %q", lines[1])
+	}
+}
+
+func TestPriority_Output(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "priority", "s1", "high"); err != nil {
+		t.Fatalf("priority: %v", err)
+	}
+	if got := stdout.String(); got != "Priority of s1 set to high\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	sf, err := testStore().ReadSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.Current.Priority != "high" {
+		t.Errorf("Priority = %q, want high", sf.Current.Priority)
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "priority", "s1", "normal"); err != nil {
+		t.Fatalf("priority: %v", err)
+	}
+	if sf, _ := testStore().ReadSession("s1"); sf.Current.Priority != "" {
+		t.Errorf("normal should be stored as empty, got %q", sf.Current.Priority)
+	}
+}
+
+func TestPriority_Errors(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	opts, _, _ := testOptions()
+
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "priority", "s1", "urgent")
+	if err == nil || !strings.Contains(err.Error(), `invalid priority "urgent"`) {
+		t.Errorf("err = %v, want invalid priority", err)
+	}
+	_, _, err = executeCommand(cmd.NewRootCmd(opts), "priority", "nope", "high")
+	if err == nil || !strings.Contains(err.Error(), "session nope is not in the queue") {
+		t.Errorf("err = %v, want missing session", err)
+	}
+}
+
+func TestPriority_Completion(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	opts, _, _ := testOptions()
+
+	out, _, err := executeCommand(cmd.NewRootCmd(opts), "__complete", "priority", "s1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range []string{"pinned", "high", "normal", "low"} {
+		if !strings.Contains(out, level+"\n") {
+			t.Errorf("completion missing %q:\n%s", level, out)
+		}
+	}
+}
+
+func TestAction_PinRaiseLower(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	opts, _, _ := testOptions()
+
+	steps := []struct {
+		action, want string
+	}{
+		{"raise", "high"},
+		{"raise", "pinned"},
+		{"pin", ""},
+		{"lower", "low"},
+		{"pin", "pinned"},
+	}
+	for _, st := range steps {
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", st.action, "s1"); err != nil {
+			t.Fatalf("_action %s: %v", st.action, err)
+		}
+		sf, err := testStore().ReadSession("s1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sf.Current.Priority != st.want {
+			t.Fatalf("after %s: Priority = %q, want %q", st.action, sf.Current.Priority, st.want)
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
@@ -16,32 +15,65 @@ func newPushCmd(opts Options) *cobra.Command {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			kittyWinID := os.Getenv("KITTY_WINDOW_ID")
-			if kittyWinID == "" {
-				queue.Debugf("PUSH skip: KITTY_WINDOW_ID not set")
-				return nil
-			}
-
 			input, err := queue.ParseHookInput(opts.Stdin)
 			if err != nil {
 				return err
 			}
 
-			message, _ := input.Raw["message"].(string)
+			switch ev := input.Event.(type) {
+			case *queue.StopInput:
+				if ev.StopHookActive {
+					// A stop hook is making Claude continue; the turn isn't over.
+					queue.Debugf("PUSH skip: stop hook active session=%s", input.SessionID)
+					return nil
+				}
+			case *queue.SubagentStopInput:
+				// The main agent keeps going; don't hide a pending prompt.
+				if sf, err := opts.Store.ReadSession(input.SessionID); err == nil && sf.Current != nil && queue.NeedsAttention(sf.Current.Event) {
+					queue.Debugf("PUSH skip: subagent stop while %s session=%s", sf.Current.Event, input.SessionID)
+					return nil
+				}
+			}
 
 			entry := &queue.Entry{
-				Timestamp:     opts.TimeNow(),
-				SessionID:     input.SessionID,
-				KittyWindowID: kittyWinID,
-				KittyListenOn: os.Getenv("KITTY_LISTEN_ON"),
-				PID:           queue.AncestorPID(),
-				CWD:           input.CWD,
-				Event:         input.EventType(),
-				Message:       message,
+				Timestamp: opts.TimeNow(),
+				SessionID: input.SessionID,
+				PID:       queue.AncestorPID(),
+				CWD:       input.CWD,
+				Event:     input.EventType(),
+				Message:   input.Message(),
+			}
+			setLocation(entry)
+			input.ApplyTo(entry)
+
+			var prev *queue.Entry
+			if sf, err := opts.Store.ReadSession(input.SessionID); err == nil {
+				prev = sf.Current
 			}
 
 			queue.Debugf("PUSH session=%s event=%s pid=%d", input.SessionID, input.EventType(), entry.PID)
-			return queue.Write(entry)
+			if err := opts.Store.Write(entry); err != nil {
+				return err
+			}
+			opts.Journal.Record(queue.OpPush, entry, "", entry.Timestamp)
+			if prev == nil || prev.Event != entry.Event {
+				scheduleNotify(opts, entry)
+				scheduleWebhooks(opts, entry)
+				scheduleReminders(opts, entry)
+			}
+			return nil
 		},
 	}
 }
+
+// setLocation records where the session of e runs: its terminal window or
+// pane, or else its TTY, so sessions over plain SSH, in an editor's
+// terminal or under nohup are queued too, without a window to jump to.
+func setLocation(e *queue.Entry) {
+	if t, pane, ok := terminal.Detect(); ok {
+		e.Terminal, e.WindowID, e.ListenOn = t.Name(), pane.ID, pane.Addr
+		return
+	}
+	e.TTY = queue.ProcessTTY(e.PID)
+	queue.Debugf("LOCATE session=%s outside a known terminal tty=%q", e.SessionID, e.TTY)
+}
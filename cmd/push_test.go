@@ -25,7 +25,7 @@ func TestPush_ValidInput(t *testing.T) {
 	}
 }
 
-func TestPush_SkipsWithoutKittyWindowID(t *testing.T) {
+func TestPush_RecordsOutsideTerminal(t *testing.T) {
 	setupQueueDir(t)
 	// Explicitly clear KITTY_WINDOW_ID (may be set when running inside kitty).
 	t.Setenv("KITTY_WINDOW_ID", "")
@@ -39,8 +39,52 @@ func TestPush_SkipsWithoutKittyWindowID(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if n := entryCount(t); n != 0 {
-		t.Fatalf("expected 0 entries, got %d", n)
+	sf, err := testStore().ReadSession("test-sess")
+	if err != nil {
+		t.Fatalf("expected the session to be queued: %v", err)
+	}
+	if e := sf.Current; e.Terminal != "" || e.WindowID != "" || e.PID <= 0 {
+		t.Errorf("terminal=%q wid=%q pid=%d, want no window and a PID", e.Terminal, e.WindowID, e.PID)
+	}
+}
+
+func TestPush_DetectsTerminal(t *testing.T) {
+	tests := []struct {
+		name, tmuxPane, zellijPane, weztermPane, kittyWindow string
+		terminal, wid, addr                                  string
+	}{
+		{"kitty", "", "", "", "42", "kitty", "42", "unix:/tmp/kitty-1"},
+		{"wezterm", "", "", "5", "", "wezterm", "5", "/tmp/wezterm-sock"},
+		{"tmux", "%3", "", "", "", "tmux", "%3", "/tmp/tmux-1000/default"},
+		{"tmux inside kitty", "%3", "", "", "42", "tmux", "%3", "/tmp/tmux-1000/default"},
+		{"zellij", "", "2", "", "", "zellij", "2", "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupQueueDir(t)
+			t.Setenv("TMUX_PANE", tt.tmuxPane)
+			t.Setenv("TMUX", "/tmp/tmux-1000/default,4242,0")
+			t.Setenv("ZELLIJ_PANE_ID", tt.zellijPane)
+			t.Setenv("ZELLIJ_SESSION_NAME", "api")
+			t.Setenv("WEZTERM_PANE", tt.weztermPane)
+			t.Setenv("WEZTERM_UNIX_SOCKET", "/tmp/wezterm-sock")
+			t.Setenv("KITTY_WINDOW_ID", tt.kittyWindow)
+			t.Setenv("KITTY_LISTEN_ON", "unix:/tmp/kitty-1")
+
+			input := `{"session_id":"s1","cwd":"/tmp/project","hook_event_name":"Notification","notification_type":"idle_prompt"}`
+			opts, _, _ := testOptionsWithStdin(input)
+			if _, _, err := executeCommand(cmd.NewRootCmd(opts), "push"); err != nil {
+				t.Fatalf("push: %v", err)
+			}
+			sf, err := testStore().ReadSession("s1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := sf.Current
+			if e.Terminal != tt.terminal || e.WindowID != tt.wid || e.ListenOn != tt.addr {
+				t.Errorf("terminal=%q wid=%q addr=%q, want %q %q %q", e.Terminal, e.WindowID, e.ListenOn, tt.terminal, tt.wid, tt.addr)
+			}
+		})
 	}
 }
 
@@ -61,12 +105,96 @@ func TestPush_SessionStartEvent(t *testing.T) {
 		t.Fatalf("expected 1 entry, got %d", n)
 	}
 
-	entries, _ := queue.List()
+	entries, _ := testStore().List()
 	if entries[0].Event != "SessionStart" {
 		t.Errorf("expected event=SessionStart, got %q", entries[0].Event)
 	}
 }
 
+func TestPush_StoresSessionMetadata(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("KITTY_WINDOW_ID", "42")
+
+	input := `{"session_id":"meta-sess","cwd":"/tmp/project","hook_event_name":"SessionStart","source":"resume","permission_mode":"plan","transcript_path":"/tmp/t.jsonl"}`
+	opts, _, _ := testOptionsWithStdin(input)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "push"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sf, err := testStore().ReadSession("meta-sess")
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	e := sf.Current
+	if e.Source != "resume" || e.PermissionMode != "plan" || e.TranscriptPath != "/tmp/t.jsonl" {
+		t.Errorf("entry = %+v, want source/mode/transcript set", e)
+	}
+}
+
+func TestPush_StopEvent(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("KITTY_WINDOW_ID", "42")
+	seedEntry(t, "stop-sess", "/tmp/project", "working", 1001)
+
+	input := `{"session_id":"stop-sess","cwd":"/tmp/project","hook_event_name":"Stop","stop_hook_active":false}`
+	opts, _, _ := testOptionsWithStdin(input)
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "push"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sf, _ := testStore().ReadSession("stop-sess")
+	if sf.Current.Event != "Stop" || queue.EventLabel(sf.Current.Event) != "DONE" {
+		t.Errorf("event = %q, want Stop (DONE)", sf.Current.Event)
+	}
+}
+
+func TestPush_StopHookActiveSkipped(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("KITTY_WINDOW_ID", "42")
+	seedEntry(t, "stop-sess", "/tmp/project", "working", 1001)
+
+	input := `{"session_id":"stop-sess","cwd":"/tmp/project","hook_event_name":"Stop","stop_hook_active":true}`
+	opts, _, _ := testOptionsWithStdin(input)
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "push"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sf, _ := testStore().ReadSession("stop-sess")
+	if sf.Current.Event != "working" {
+		t.Errorf("event = %q, want working (Claude is continuing)", sf.Current.Event)
+	}
+}
+
+func TestPush_SubagentStop(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		want    string
+	}{
+		{"while working", "working", "SubagentStop"},
+		{"keeps pending prompt", "permission_prompt", "permission_prompt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupQueueDir(t)
+			t.Setenv("KITTY_WINDOW_ID", "42")
+			seedEntry(t, "sub-sess", "/tmp/project", tt.current, 1001)
+
+			input := `{"session_id":"sub-sess","cwd":"/tmp/project","hook_event_name":"SubagentStop"}`
+			opts, _, _ := testOptionsWithStdin(input)
+			if _, _, err := executeCommand(cmd.NewRootCmd(opts), "push"); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			sf, _ := testStore().ReadSession("sub-sess")
+			if sf.Current.Event != tt.want {
+				t.Errorf("event = %q, want %q", sf.Current.Event, tt.want)
+			}
+		})
+	}
+}
+
 func TestPush_MalformedJSON(t *testing.T) {
 	setupQueueDir(t)
 	t.Setenv("KITTY_WINDOW_ID", "42")
@@ -79,3 +207,26 @@ func TestPush_MalformedJSON(t *testing.T) {
 		t.Fatal("expected error for malformed JSON, got nil")
 	}
 }
+
+func TestPush_WritesToInjectedStore(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("KITTY_WINDOW_ID", "42")
+
+	input := `{"session_id":"mem-sess","cwd":"/tmp/project","hook_event_name":"Notification","notification_type":"idle_prompt"}`
+	opts, _, _ := testOptionsWithStdin(input)
+	store := queue.NewMemStore()
+	opts.Store = store
+	root := cmd.NewRootCmd(opts)
+
+	if _, _, err := executeCommand(root, "push"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	entries, _ := store.List()
+	if len(entries) != 1 || entries[0].SessionID != "mem-sess" {
+		t.Fatalf("store entries = %v, want mem-sess", entries)
+	}
+	if n := entryCount(t); n != 0 {
+		t.Errorf("expected nothing written to disk, got %d entries", n)
+	}
+}
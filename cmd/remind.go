@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/notify"
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// remindPoll bounds how long _remind sleeps between passes, so it notices
+// config changes, expired snoozes and the end of do-not-disturb.
+const remindPoll = time.Minute
+
+// scheduleReminders spawns a background _remind when a reminder policy
+// applies to entry, which just entered its event. The process exits once
+// no waiting session has a reminder left.
+func scheduleReminders(opts Options, entry *queue.Entry) {
+	if opts.Spawn == nil {
+		return
+	}
+	cfg := queue.ReadConfig()
+	rs, err := queue.CompileReminders(cfg.Reminders)
+	if err != nil {
+		queue.Debugf("REMIND %s: %v", queue.ConfigPath(), err)
+		return
+	}
+	if !rs.Applies(entry.Event) {
+		return
+	}
+	if err := opts.Spawn("_remind"); err != nil {
+		queue.Debugf("REMIND spawn failed: %v", err)
+	}
+}
+
+// planReminders returns the entries with a reminder due at now, the acks to
+// keep, and when the next reminder falls due (zero when none is left). A
+// reminder is due when the session's wait reached a step that was not
+// acknowledged yet; only the latest step is sent, however many were missed.
+// Reminders for snoozed or do-not-disturb sessions wait until they are back.
+func planReminders(cfg queue.Config, rs *queue.ReminderSet, entries []*queue.Entry, acks map[string]queue.ReminderAck, now time.Time) (due []*queue.Entry, keep map[string]queue.ReminderAck, next time.Time) {
+	keep = make(map[string]queue.ReminderAck)
+	soonest := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	for _, e := range entries {
+		if !rs.Applies(e.Event) {
+			continue
+		}
+		since := e.WaitingSince()
+		step, nextWait := rs.Step(e.Event, now.Sub(since))
+		ack, ok := acks[e.SessionID]
+		if !ok || !ack.Since.Equal(since) {
+			ack = queue.ReminderAck{Since: since} // a new wait
+		}
+		if step > ack.Step {
+			if e.IsSnoozed(now) || cfg.DNDMutes(e.CWD, now) {
+				soonest(now.Add(remindPoll))
+			} else {
+				due = append(due, e)
+				ack.Step = step
+			}
+		}
+		keep[e.SessionID] = ack
+		if nextWait > 0 {
+			soonest(since.Add(nextWait))
+		}
+	}
+	return due, keep, next
+}
+
+// planQueue runs planReminders on the queue with the current config.
+func planQueue(opts Options, now time.Time) (cfg queue.Config, due []*queue.Entry, keep map[string]queue.ReminderAck, next time.Time, err error) {
+	cfg = queue.ReadConfig()
+	rs, err := queue.CompileReminders(cfg.Reminders)
+	if err != nil {
+		return cfg, nil, nil, time.Time{}, fmt.Errorf("%s: %w", queue.ConfigPath(), err)
+	}
+	entries, err := opts.Store.List()
+	if err != nil {
+		return cfg, nil, nil, time.Time{}, err
+	}
+	acks, err := queue.ReadReminderAcks()
+	if err != nil {
+		queue.Debugf("REMIND acks unreadable, starting over: %v", err)
+	}
+	due, keep, next = planReminders(cfg, rs, entries, acks, now)
+	return cfg, due, keep, next, nil
+}
+
+// remindPass sends the reminders due now through the configured notifiers
+// and returns when the next one falls due, or the zero time when none is
+// left. Desktop notifications wait for a click in the background, on wg.
+func remindPass(ctx context.Context, opts Options, wg *sync.WaitGroup) (time.Time, error) {
+	now := opts.TimeNow()
+	cfg, due, keep, next, err := planQueue(opts, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Acknowledge before sending, so a crash never sends a reminder twice.
+	if err := queue.WriteReminderAcks(keep); err != nil {
+		return time.Time{}, err
+	}
+	if len(due) == 0 {
+		return next, nil
+	}
+	queue.Debugf("REMIND sessions=%d", len(due))
+
+	queueWebhooks(opts, cfg, due...)
+	if opts.Notifier != nil {
+		var desktop []*queue.Entry
+		for _, e := range due {
+			if cfg.Notify.Wants(e.Event) {
+				desktop = append(desktop, e)
+			}
+		}
+		if len(desktop) > 0 {
+			sortForPicker(desktop, opts.Branches)
+			n := reminderNotification(desktop, now)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				notifyAndFocus(ctx, opts, n, desktop[0].SessionID)
+			}()
+		}
+	}
+	return next, nil
+}
+
+// reminderNotification is buildNotification with how long each session has
+// been waiting.
+func reminderNotification(entries []*queue.Entry, now time.Time) notify.Notification {
+	n := buildNotification(entries)
+	if len(entries) == 1 {
+		n.Summary = "Waiting " + queue.FormatDuration(now.Sub(entries[0].WaitingSince())) + " · " + n.Summary
+		return n
+	}
+	n.Summary = fmt.Sprintf("%d sessions still waiting", len(entries))
+	var lines []string
+	for _, e := range entries {
+		lines = append(lines, queue.FormatDuration(now.Sub(e.WaitingSince()))+"  "+queue.EventLabel(e.Event)+"  "+queue.ShortenPath(e.CWD))
+	}
+	n.Body = strings.Join(lines, "\n")
+	return n
+}
+
+// remindUntilDone runs reminder passes, sleeping until the next reminder or
+// a queue change in between, until no waiting session has a reminder left.
+func remindUntilDone(ctx context.Context, opts Options, wg *sync.WaitGroup) error {
+	changes := watchQueue(ctx)
+	for {
+		next, err := remindPass(ctx, opts, wg)
+		if err != nil || next.IsZero() {
+			return err
+		}
+		wait := min(next.Sub(opts.TimeNow()), remindPoll)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changes:
+		case <-time.After(wait):
+		}
+	}
+}
+
+func newRemindCmd(opts Options) *cobra.Command {
+	var once bool
+
+	cmd := &cobra.Command{
+		Use:    "_remind",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := queue.EnsureDir(); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			var wg sync.WaitGroup
+			defer wg.Wait()
+			if once {
+				_, err := remindPass(ctx, opts, &wg)
+				return err
+			}
+
+			lockPath := filepath.Join(queue.Dir(), ".remind.lock")
+			for {
+				unlock, ok, err := queue.TryLock(lockPath)
+				if err != nil || !ok {
+					return err // another _remind is on it
+				}
+				err = remindUntilDone(ctx, opts, &wg)
+				unlock()
+				if err != nil {
+					return err
+				}
+				// A session may have started waiting after the last pass,
+				// its own _remind giving up on the lock we held.
+				_, due, _, next, err := planQueue(opts, opts.TimeNow())
+				if err != nil || (len(due) == 0 && next.IsZero()) {
+					return err
+				}
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&once, "once", false, "Send the reminders due now and exit")
+	_ = cmd.RegisterFlagCompletionFunc("once", cobra.NoFileCompletions)
+	return cmd
+}
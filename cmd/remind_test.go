@@ -0,0 +1,198 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// permReminders reminds about PERM prompts at 5m and 15m, then every 30m.
+var permReminders = []queue.Reminder{{Event: "PERM", At: []string{"5m", "15m"}, Every: "30m"}}
+
+// remindOnce runs one reminder pass with the real clock and returns the
+// notifications sent.
+func remindOnce(t *testing.T) []string {
+	t.Helper()
+	opts, _, _ := testOptions()
+	opts.TimeNow = time.Now
+	n := &fakeNotifier{}
+	opts.Notifier = n
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_remind", "--once"); err != nil {
+		t.Fatalf("_remind --once: %v", err)
+	}
+	var got []string
+	for _, sent := range n.sent {
+		got = append(got, sent.Summary)
+	}
+	return got
+}
+
+func TestPush_SchedulesReminders(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{Reminders: permReminders}); err != nil {
+		t.Fatal(err)
+	}
+
+	spawned := pushWithSpawn(t, "permission_prompt")
+	if len(spawned) != 1 || strings.Join(spawned[0], " ") != "_remind" {
+		t.Errorf("spawned %q, want _remind", spawned)
+	}
+
+	setupQueueDir(t)
+	if spawned := pushWithSpawn(t, "idle_prompt"); len(spawned) != 0 {
+		t.Errorf("spawned %q for an event without reminders", spawned)
+	}
+}
+
+func TestRemind_FiresOncePerStep(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{Reminders: permReminders})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -6*60)
+	seedEntryAtTime(t, "sess-b", "/tmp/b", "permission_prompt", 2, -2*60) // not due yet
+	seedEntryAtTime(t, "sess-c", "/tmp/c", "idle_prompt", 3, -60*60)      // no policy
+
+	if got := remindOnce(t); len(got) != 1 || got[0] != "Waiting 6m · PERM  /tmp/a" {
+		t.Fatalf("first pass sent %q, want one reminder for /tmp/a", got)
+	}
+	if got := remindOnce(t); len(got) != 0 {
+		t.Errorf("second pass sent %q, want nothing", got)
+	}
+}
+
+func TestRemind_MissedStepsSendOne(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{Reminders: permReminders})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -50*60)
+	seedEntryAtTime(t, "sess-b", "/tmp/b", "permission_prompt", 2, -20*60)
+
+	got := remindOnce(t)
+	if len(got) != 1 || got[0] != "2 sessions still waiting" {
+		t.Fatalf("sent %q, want one reminder for both sessions", got)
+	}
+	acks, err := queue.ReadReminderAcks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acks["sess-a"].Step != 3 || acks["sess-b"].Step != 2 {
+		t.Errorf("acks = %+v, want steps 3 and 2", acks)
+	}
+}
+
+func TestRemind_NewWaitStartsOver(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{Reminders: permReminders})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -6*60)
+	if got := remindOnce(t); len(got) != 1 {
+		t.Fatalf("sent %q, want one reminder", got)
+	}
+
+	// The session was answered and asked again, 7 minutes ago.
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -7*60)
+	if got := remindOnce(t); len(got) != 1 {
+		t.Errorf("sent %q, want a reminder for the new wait", got)
+	}
+}
+
+func TestRemind_TouchKeepsWait(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{Reminders: permReminders})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -6*60)
+	if got := remindOnce(t); len(got) != 1 {
+		t.Fatalf("sent %q, want one reminder", got)
+	}
+
+	// Jumped to and away from, but not answered.
+	if err := testStore().Touch("sess-a", time.Now().Add(-6*60*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if got := remindOnce(t); len(got) != 0 {
+		t.Errorf("sent %q, want nothing for a touched session", got)
+	}
+}
+
+func TestRemind_WaitsOutDND(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{Reminders: permReminders, DND: true})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -6*60)
+	if got := remindOnce(t); len(got) != 0 {
+		t.Fatalf("sent %q during do not disturb", got)
+	}
+
+	writeNotifyConfig(t, queue.Config{Reminders: permReminders})
+	// The config moved to a new XDG_CONFIG_HOME; the acks stay in the queue dir.
+	if got := remindOnce(t); len(got) != 1 {
+		t.Errorf("sent %q after do not disturb, want one reminder", got)
+	}
+}
+
+func TestRemind_Webhooks(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{
+		Reminders: permReminders,
+		Webhooks:  []queue.Webhook{{URL: "http://127.0.0.1:1"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -6*60)
+	opts, _, _ := testOptions()
+	opts.TimeNow = time.Now
+	var spawned []string
+	opts.Spawn = func(args ...string) error {
+		spawned = append(spawned, strings.Join(args, " "))
+		return nil
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_remind", "--once"); err != nil {
+		t.Fatalf("_remind --once: %v", err)
+	}
+	if len(spawned) != 1 || spawned[0] != "_deliver" {
+		t.Errorf("spawned %q, want _deliver", spawned)
+	}
+	if n := len(outboxFiles(t)); n != 1 {
+		t.Errorf("outbox has %d messages, want 1", n)
+	}
+}
+
+func TestRemind_ExitsWhenNothingLeft(t *testing.T) {
+	setupQueueDir(t)
+	writeNotifyConfig(t, queue.Config{Reminders: []queue.Reminder{{Event: "PERM", At: []string{"5m"}}}})
+	seedEntryAtTime(t, "sess-a", "/tmp/a", "permission_prompt", 1, -6*60)
+	opts, _, _ := testOptions()
+	opts.TimeNow = time.Now
+	n := &fakeNotifier{}
+	opts.Notifier = n
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := executeCommand(cmd.NewRootCmd(opts), "_remind")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("_remind: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("_remind did not exit with no reminder left")
+	}
+	if len(n.sent) != 1 {
+		t.Errorf("sent %d notifications, want 1", len(n.sent))
+	}
+}
+
+func TestRemind_InvalidConfig(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{Reminders: []queue.Reminder{{Event: "PERM"}}}); err != nil {
+		t.Fatal(err)
+	}
+	opts, _, _ := testOptions()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_remind", "--once"); err == nil {
+		t.Error("expected an error for a reminder without at or every")
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// noJumpMark flags rows of sessions outside any known terminal, which
+// cannot be focused.
+const noJumpMark = "  ⊘ no jump"
+
+// clipboards are the commands tried, in order, to copy to the clipboard.
+var clipboards = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"pbcopy"},
+}
+
+// shellQuote quotes s for a POSIX shell when it needs it.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./~+=:,@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// resumeCommand is the shell command resuming the session of e in its CWD.
+func resumeCommand(e *queue.Entry) string {
+	return "cd " + shellQuote(e.CWD) + " && claude --resume " + shellQuote(e.SessionID)
+}
+
+// copyToClipboard copies text with the first clipboard command installed.
+func copyToClipboard(text string) error {
+	for _, c := range clipboards {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errors.New("no clipboard command found")
+}
+
+// noJumpMessage tells how to get back to a session with no window to jump
+// to, copying its resume command to the clipboard when possible. It fits
+// on one line, for the picker's header.
+func noJumpMessage(opts Options, e *queue.Entry) string {
+	where := "outside a known terminal"
+	if e.TTY != "" {
+		where = "on " + e.TTY
+	}
+	resume := resumeCommand(e)
+	if opts.Clipboard != nil && opts.Clipboard(resume) == nil {
+		return "Runs " + where + " — copied: " + resume
+	}
+	return "Runs " + where + " — resume with: " + resume
+}
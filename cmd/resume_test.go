@@ -0,0 +1,53 @@
+package cmd_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func TestJumpInternal_NoWindowPrintsResume(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	var copied string
+	opts.Clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	seedEntryNoWindow(t, "sess-ssh", "/home/user/my proj", "permission_prompt", 1001)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_jump", "sess-ssh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "cd '/home/user/my proj' && claude --resume sess-ssh"
+	if copied != want {
+		t.Errorf("copied %q, want %q", copied, want)
+	}
+	if got := stdout.String(); !strings.Contains(got, "copied: "+want) || strings.Count(got, "\n") != 1 {
+		t.Errorf("output = %q, want one line with the copied command", got)
+	}
+}
+
+func TestJumpInternal_NoWindowWithoutClipboard(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	opts.Clipboard = func(string) error { return errors.New("no clipboard") }
+	seedEntryNoWindow(t, "sess-ssh", "/srv/app", "idle_prompt", 1001)
+	if err := testStore().Update("sess-ssh", func(e *queue.Entry) error {
+		e.TTY = "/dev/pts/7"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_jump", "sess-ssh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Runs on /dev/pts/7 — resume with: cd /srv/app && claude --resume sess-ssh\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"syscall"
 	"time"
 
-	"github.com/duboisf/cc-queue/internal/kitty"
+	"github.com/duboisf/cc-queue/internal/notify"
 	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
@@ -21,20 +23,34 @@ type Options struct {
 	Stdout io.Writer
 	// Stderr for error output.
 	Stderr io.Writer
-	// FullTabber manages kitty tab layout for full-tab overlays.
-	FullTabber kitty.FullTabber
-	// CleanStaleWindowsFn removes entries with dead kitty windows. Nil to skip.
+	// FullTabber manages the terminal layout for full-tab overlays.
+	FullTabber terminal.FullTabber
+	// CleanStaleWindowsFn removes entries with dead terminal windows. Nil to skip.
 	CleanStaleWindowsFn func()
+	// Store persists queue entries.
+	Store queue.Store
+	// Journal records queue operations. Nil to skip.
+	Journal *queue.Journal
+	// Branches caches the git branch of session directories. Nil to run
+	// git on every lookup.
+	Branches *queue.BranchCache
 	// ClaudeDir is the path to the Claude Code config directory.
 	// Defaults to ~/.claude if empty.
 	ClaudeDir string
+	// Spawn starts cc-queue with args as a detached background process,
+	// so hooks return without waiting for it. Nil to skip.
+	Spawn func(args ...string) error
+	// Notifier shows desktop notifications. Nil to skip.
+	Notifier notify.Notifier
+	// Clipboard copies text to the clipboard. Nil to skip.
+	Clipboard func(text string) error
 }
 
 // NewRootCmd creates the root cobra command with all subcommands wired up.
 func NewRootCmd(opts Options) *cobra.Command {
 	root := &cobra.Command{
 		Use:           "cc-queue",
-		Short:         "Claude Code input queue for kitty terminal",
+		Short:         "Claude Code input queue for kitty and tmux",
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE:          jumpRunE(opts),
@@ -44,6 +60,10 @@ func NewRootCmd(opts Options) *cobra.Command {
 	}
 	root.Flags().Bool("full-tab", false, "Use stack layout to cover the entire tab, restore on exit")
 	_ = root.RegisterFlagCompletionFunc("full-tab", cobra.NoFileCompletions)
+	root.Flags().String("ui", "", `Picker: "auto" (fzf if installed), "fzf" or "native" (default from config, else auto)`)
+	_ = root.RegisterFlagCompletionFunc("ui", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{uiAuto, uiFzf, uiNative}, cobra.ShellCompDirectiveNoFileComp
+	})
 	root.SetOut(opts.Stdout)
 	root.SetErr(opts.Stderr)
 
@@ -64,9 +84,27 @@ func NewRootCmd(opts Options) *cobra.Command {
 	cleanCmd.GroupID = "core"
 	firstCmd := newFirstCmd(opts)
 	firstCmd.GroupID = "core"
+	nextCmd := newNextCmd(opts)
+	nextCmd.GroupID = "core"
+	prevCmd := newPrevCmd(opts)
+	prevCmd.GroupID = "core"
+	snoozeCmd := newSnoozeCmd(opts)
+	snoozeCmd.GroupID = "core"
+	priorityCmd := newPriorityCmd(opts)
+	priorityCmd.GroupID = "core"
+	dndCmd := newDNDCmd(opts)
+	dndCmd.GroupID = "core"
+	logCmd := newLogCmd(opts)
+	logCmd.GroupID = "core"
+	statsCmd := newStatsCmd(opts)
+	statsCmd.GroupID = "core"
+	metricsCmd := newMetricsCmd(opts)
+	metricsCmd.GroupID = "core"
 
 	configCmd := newConfigCmd(opts)
 	configCmd.GroupID = "setup"
+	rulesCmd := newRulesCmd(opts)
+	rulesCmd.GroupID = "setup"
 	debugCmd := newDebugCmd(opts)
 	debugCmd.GroupID = "setup"
 	installCmd := newInstallCmd(opts)
@@ -87,17 +125,32 @@ func NewRootCmd(opts Options) *cobra.Command {
 		clearCmd,
 		cleanCmd,
 		firstCmd,
+		nextCmd,
+		prevCmd,
+		snoozeCmd,
+		priorityCmd,
+		dndCmd,
+		logCmd,
+		statsCmd,
+		metricsCmd,
 		configCmd,
+		rulesCmd,
 		debugCmd,
 		installCmd,
 		hooksCmd,
 		completionCmd,
 		versionCmd,
 		newEndCmd(opts),
+		newToolCmd(opts),
 		newListFzfCmd(opts),
 		newPreviewCmd(opts),
-		newJumpInternalCmd(),
-		newShellCmd(),
+		newJumpInternalCmd(opts),
+		newShellCmd(opts),
+		newActionCmd(opts),
+		newAdvanceCmd(opts),
+		newNotifyCmd(opts),
+		newDeliverCmd(opts),
+		newRemindCmd(opts),
 	)
 	return root
 }
@@ -110,47 +163,66 @@ func Execute() error {
 
 // DefaultOptions returns production-ready Options with standard I/O.
 func DefaultOptions() Options {
+	journal := queue.NewJournal("")
+	store := queue.NewFileStore(queue.Dir())
+	store.Journal = journal
 	return Options{
-		TimeNow:    time.Now,
-		Stdin:      os.Stdin,
-		Stdout:     os.Stdout,
-		Stderr:     os.Stderr,
-		FullTabber: kitty.NewLayoutManager(),
-		CleanStaleWindowsFn: func() {
-			entries, err := queue.List()
+		TimeNow:             time.Now,
+		Stdin:               os.Stdin,
+		Stdout:              os.Stdout,
+		Stderr:              os.Stderr,
+		FullTabber:          terminal.FullScreen{},
+		Store:               store,
+		Journal:             journal,
+		Branches:            queue.NewBranchCache("", queue.DefaultBranchTTL),
+		Spawn:               spawnSelf,
+		Notifier:            notify.NewDBus(),
+		Clipboard:           copyToClipboard,
+		CleanStaleWindowsFn: func() { cleanStaleWindows(store, journal) },
+	}
+}
+
+// cleanStaleWindows removes the entries whose window or pane is gone,
+// querying each terminal the queue's sessions run in once. Entries whose
+// terminal cannot be queried are kept. Removals are recorded to journal.
+func cleanStaleWindows(store queue.Store, journal *queue.Journal) {
+	entries, err := store.List()
+	if err != nil {
+		return
+	}
+	type server struct{ terminal, addr string }
+	live := make(map[server]map[string]bool)
+	for _, e := range entries {
+		k := server{e.Terminal, e.ListenOn}
+		if _, seen := live[k]; seen || e.WindowID == "" {
+			continue
+		}
+		live[k] = nil
+		if t := terminal.ByName(e.Terminal); t != nil {
+			ids, err := t.LivePanes(e.ListenOn)
 			if err != nil {
-				return
-			}
-			// Collect unique kitty sockets from queue entries.
-			sockets := make(map[string]bool)
-			for _, e := range entries {
-				if e.KittyListenOn != "" {
-					sockets[e.KittyListenOn] = true
-				}
-			}
-			if len(sockets) == 0 {
-				return
-			}
-			// Query each socket for its window IDs.
-			allIDs := make(map[string]bool)
-			queried := false
-			for sock := range sockets {
-				out, err := exec.Command("kitty", "@", "--to", sock, "ls").Output()
-				if err != nil {
-					continue
-				}
-				ids, err := kitty.ParseWindowIDs(out)
-				if err != nil {
-					continue
-				}
-				queried = true
-				for id := range ids {
-					allIDs[id] = true
-				}
+				queue.Debugf("CLEAN_STALE_WINDOW keep terminal=%s addr=%s: %v", e.Terminal, e.ListenOn, err)
 			}
-			if queried {
-				queue.CleanStaleWindows(allIDs)
-			}
-		},
+			live[k] = ids
+		}
+	}
+	queue.CleanStaleWindows(store, journal, func(e *queue.Entry) bool {
+		ids := live[server{e.Terminal, e.ListenOn}]
+		return ids == nil || ids[e.WindowID]
+	})
+}
+
+// spawnSelf starts the running cc-queue binary with args in its own session,
+// detached from the hook's stdio, and does not wait for it.
+func spawnSelf(args ...string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(self, args...)
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := c.Start(); err != nil {
+		return err
 	}
+	return c.Process.Release()
 }
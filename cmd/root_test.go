@@ -12,9 +12,9 @@ func TestNewRootCmd_HasExpectedSubcommands(t *testing.T) {
 	root := cmd.NewRootCmd(opts)
 
 	expected := []string{
-		"push", "pop", "list", "clear", "clean", "first",
-		"config", "debug", "install", "hooks", "completion", "version", "end",
-		"_list-fzf", "_preview", "_jump", "_shell",
+		"push", "pop", "list", "clear", "clean", "first", "next", "prev", "snooze", "priority", "dnd", "log", "stats", "metrics",
+		"config", "rules", "debug", "install", "hooks", "completion", "version", "end", "tool",
+		"_list-fzf", "_preview", "_jump", "_shell", "_action", "_advance", "_notify", "_deliver", "_remind",
 	}
 	sort.Strings(expected)
 
@@ -63,4 +63,28 @@ func TestDefaultOptions_NonNil(t *testing.T) {
 	if opts.CleanStaleWindowsFn == nil {
 		t.Error("CleanStaleWindowsFn is nil")
 	}
+	if opts.Store == nil {
+		t.Error("Store is nil")
+	}
+	if opts.Clipboard == nil {
+		t.Error("Clipboard is nil")
+	}
+}
+
+func TestDefaultOptions_CleanStaleWindowsZellij(t *testing.T) {
+	setupQueueDir(t)
+	// The stub lists no zellij session, so the "gone" session has exited.
+	fakeCLI(t, "zellij")
+	seedEntry(t, "gone", "/tmp/a", "idle_prompt", 1)
+	setPane(t, "gone", "zellij", "3", "gone")
+	seedEntryNoWindow(t, "nowin", "/tmp/b", "idle_prompt", 1)
+
+	cmd.DefaultOptions().CleanStaleWindowsFn()
+
+	if _, err := testStore().ReadSession("gone"); err == nil {
+		t.Error("entry of an exited zellij session should be removed")
+	}
+	if _, err := testStore().ReadSession("nowin"); err != nil {
+		t.Errorf("entry without a window should be kept: %v", err)
+	}
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+func newRulesCmd(opts Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Inspect the sort rules from the config",
+		Long: `Inspect the sort rules from the config.
+
+Rules live under "rules" in config.json and are evaluated in order; the
+first rule matching an entry gives it its rank, and lower ranks sort first.
+Entries no rule matches have rank 0. Priority (cc-queue priority) still
+comes before rules, and the default order (prompts, then idle sessions,
+oldest first) breaks ties. For example:
+
+  "rules": [
+    {"name": "infra perms", "event": "PERM", "cwd": "~/git/infra/**", "rank": -10},
+    {"name": "stale idle", "event": "IDLE", "older_than": "2h", "rank": 10}
+  ]
+
+A rule can match on event (type or label), cwd (glob, ** for any depth),
+branch (glob), older_than / newer_than (90m, 2h, 1d) and message (regexp).`,
+		Args: cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.AddCommand(newRulesTestCmd(opts))
+	return cmd
+}
+
+func newRulesTestCmd(opts Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "test",
+		Short: "Show how the current queue sorts under the rules",
+		Args:  cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := queue.ReadConfig()
+			rules, err := queue.CompileRules(cfg.Rules)
+			if err != nil {
+				return fmt.Errorf("%s: %w", queue.ConfigPath(), err)
+			}
+			if len(cfg.Rules) == 0 {
+				fmt.Fprintf(opts.Stdout, "No rules in %s, using the default order\n\n", queue.ConfigPath())
+			}
+
+			entries, err := opts.Store.List()
+			if err != nil {
+				return err
+			}
+			now := opts.TimeNow()
+			entries = visibleEntries(entries, now)
+			if len(entries) == 0 {
+				fmt.Fprintln(opts.Stdout, "No active sessions")
+				return nil
+			}
+
+			ranked := rankEntries(entries, rules, opts.Branches, now)
+			sorted := make([]*queue.Entry, len(ranked))
+			for i, r := range ranked {
+				sorted[i] = r.Entry
+			}
+			rows, maxPath := buildRows(sorted, opts.Branches)
+
+			maxRule := len("RULE")
+			for _, r := range ranked {
+				maxRule = max(maxRule, len(r.rule))
+			}
+			fmt.Fprintf(opts.Stdout, "%4s  %-*s %s\n", "RANK", maxRule, "RULE", rowsHeader(maxPath))
+			for i, r := range ranked {
+				rule := r.rule
+				if rule == "" {
+					rule = "-"
+				}
+				fmt.Fprintf(opts.Stdout, "%4d  %-*s %s\n", r.rank, maxRule, rule, rows[i].text(maxPath))
+			}
+			return nil
+		},
+	}
+}
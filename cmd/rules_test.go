@@ -0,0 +1,93 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+func writeRules(t *testing.T, rules ...queue.Rule) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{Rules: rules}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+}
+
+func TestRulesTest_ShowsRankedOrder(t *testing.T) {
+	setupQueueDir(t)
+	writeRules(t,
+		queue.Rule{Name: "infra perms", Event: "PERM", CWD: "/tmp/infra/**", Rank: -10},
+		queue.Rule{Name: "stale idle", Event: "IDLE", OlderThan: "2h", Rank: 10},
+	)
+	seedEntryAtTime(t, "idle-old", "/tmp/old", "idle_prompt", 1, -3*3600)
+	seedEntryAtTime(t, "idle-new", "/tmp/new", "idle_prompt", 2, -60)
+	seedEntryAtTime(t, "perm-app", "/tmp/app", "permission_prompt", 3, -600)
+	seedEntryAtTime(t, "perm-infra", "/tmp/infra/prod", "permission_prompt", 4, -30)
+	opts, stdout, _ := testOptions()
+	opts.TimeNow = time.Now
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "rules", "test"); err != nil {
+		t.Fatalf("rules test: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected header + 4 rows, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(lines[0], "RANK") || !strings.Contains(lines[0], "RULE") {
+		t.Errorf("header = %q", lines[0])
+	}
+	want := []struct{ rank, rule, path string }{
+		{"-10", "infra perms", "/tmp/infra/prod"},
+		{"0", "-", "/tmp/app"},
+		{"0", "-", "/tmp/new"},
+		{"10", "stale idle", "/tmp/old"},
+	}
+	for i, w := range want {
+		fields := strings.Fields(lines[i+1])
+		if fields[0] != w.rank || !strings.Contains(lines[i+1], w.rule) || !strings.Contains(lines[i+1], w.path) {
+			t.Errorf("row %d = %q, want rank %s rule %s path %s", i, lines[i+1], w.rank, w.rule, w.path)
+		}
+	}
+}
+
+func TestRulesTest_NoRules(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "rules", "test"); err != nil {
+		t.Fatalf("rules test: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "No rules in ") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
+
+func TestRulesTest_InvalidRule(t *testing.T) {
+	setupQueueDir(t)
+	writeRules(t, queue.Rule{Name: "broken", Message: "("})
+	opts, _, _ := testOptions()
+
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "rules", "test")
+	if err == nil || !strings.Contains(err.Error(), "rule broken: message") {
+		t.Fatalf("err = %v, want invalid rule", err)
+	}
+}
+
+func TestList_UsesRules(t *testing.T) {
+	setupQueueDir(t)
+	writeRules(t, queue.Rule{Event: "IDLE", CWD: "/tmp/infra/**", Rank: -1})
+	seedEntryAtTime(t, "perm", "/tmp/app", "permission_prompt", 1, -600)
+	seedEntryAtTime(t, "idle", "/tmp/infra", "idle_prompt", 2, -60)
+	opts, _, _ := testOptions()
+
+	lines := strings.Split(strings.TrimSpace(listOutput(t, opts)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "/tmp/infra") {
+		t.Errorf("rule should move the idle infra session first:\n%s", strings.Join(lines, "\n"))
+	}
+}
@@ -1,38 +1,21 @@
 package cmd
 
 import (
-	"fmt"
-	"os/exec"
-	"strings"
-
 	"github.com/duboisf/cc-queue/internal/queue"
 	"github.com/spf13/cobra"
 )
 
-// KittyLaunchArgs builds the kitty CLI arguments to launch a new window
-// in the same tab as the given entry, using its CWD.
-func KittyLaunchArgs(entry *queue.Entry) []string {
-	args := []string{"@"}
-	if entry.KittyListenOn != "" {
-		args = append(args, "--to", entry.KittyListenOn)
-	}
-	args = append(args, "launch", "--type=window",
-		"--cwd="+entry.CWD,
-		"--match", "id:"+entry.KittyWindowID)
-	return args
-}
-
-func newShellCmd() *cobra.Command {
+func newShellCmd(opts Options) *cobra.Command {
 	return &cobra.Command{
 		Use:    "_shell [session_id]",
 		Hidden: true,
 		Args:   cobra.ExactArgs(1),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-			return sessionIDCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+			return sessionIDCompletions(opts.Store, toComplete), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sessionID := args[0]
-			entries, err := queue.List()
+			entries, err := opts.Store.List()
 			if err != nil {
 				return err
 			}
@@ -43,30 +26,24 @@ func newShellCmd() *cobra.Command {
 					break
 				}
 			}
-			if target == nil || target.KittyWindowID == "" {
+			if target == nil {
 				return nil
 			}
-
-			launchArgs := KittyLaunchArgs(target)
-			launchCmd := exec.Command("kitty", launchArgs...)
-			out, err := launchCmd.Output()
-			if err != nil {
-				// Do NOT remove the entry — the session is still valid.
-				return fmt.Errorf("kitty launch failed: %w", err)
-			}
-
-			// kitty @ launch prints the new window ID — focus it.
-			newWID := strings.TrimSpace(string(out))
-			if newWID != "" {
-				focusArgs := []string{"@"}
-				if target.KittyListenOn != "" {
-					focusArgs = append(focusArgs, "--to", target.KittyListenOn)
-				}
-				focusArgs = append(focusArgs, "focus-window", "--match", "id:"+newWID)
-				focusCmd := exec.Command("kitty", focusArgs...)
-				_ = focusCmd.Run()
-			}
-			return nil
+			return launchShell(target)
 		},
 	}
 }
+
+// launchShell opens a shell next to the entry's session, in its CWD, and
+// focuses it. It is a no-op for entries without a terminal window.
+func launchShell(target *queue.Entry) error {
+	if target.WindowID == "" {
+		return nil
+	}
+	t, pane, err := entryPane(target)
+	if err != nil {
+		return err
+	}
+	// Do NOT remove the entry on failure — the session is still valid.
+	return t.LaunchShell(pane, target.CWD)
+}
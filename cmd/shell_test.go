@@ -5,41 +5,83 @@ import (
 	"testing"
 
 	"github.com/duboisf/cc-queue/cmd"
-	"github.com/duboisf/cc-queue/internal/queue"
 )
 
-func TestKittyLaunchArgs(t *testing.T) {
-	entry := &queue.Entry{
-		CWD:           "/home/user/project",
-		KittyWindowID: "42",
+func TestShellCmd_Kitty(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	opts, _, _ := testOptions()
+	seedEntry(t, "sess-shell", "/home/user/project", "permission_prompt", 1001)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_shell", "sess-shell"); err != nil {
+		t.Fatalf("_shell: %v", err)
+	}
+	want := "@ launch --type=window --cwd=/home/user/project --match id:42"
+	if got := calls(); len(got) != 1 || got[0] != want {
+		t.Errorf("kitty calls = %q, want [%q]", got, want)
 	}
-	got := cmd.KittyLaunchArgs(entry)
-	want := []string{"@", "launch", "--type=window", "--cwd=/home/user/project", "--match", "id:42"}
-	if len(got) != len(want) {
-		t.Fatalf("len = %d, want %d: %v", len(got), len(want), got)
+}
+
+func TestShellCmd_KittyWithListenOn(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeKitty(t)
+	opts, _, _ := testOptions()
+	seedEntry(t, "sess-shell", "/home/user/project", "permission_prompt", 1001)
+	setPane(t, "sess-shell", "kitty", "42", "unix:/tmp/kitty-sock")
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_shell", "sess-shell"); err != nil {
+		t.Fatalf("_shell: %v", err)
 	}
-	for i := range want {
-		if got[i] != want[i] {
-			t.Errorf("arg[%d] = %q, want %q", i, got[i], want[i])
-		}
+	want := "@ --to unix:/tmp/kitty-sock launch --type=window --cwd=/home/user/project --match id:42"
+	if got := calls(); len(got) != 1 || got[0] != want {
+		t.Errorf("kitty calls = %q, want [%q]", got, want)
 	}
 }
 
-func TestKittyLaunchArgs_WithListenOn(t *testing.T) {
-	entry := &queue.Entry{
-		CWD:           "/home/user/project",
-		KittyWindowID: "42",
-		KittyListenOn: "unix:/tmp/kitty-sock",
+func TestShellCmd_Tmux(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeCLI(t, "tmux")
+	opts, _, _ := testOptions()
+	seedEntry(t, "sess-shell", "/home/user/project", "permission_prompt", 1001)
+	setPane(t, "sess-shell", "tmux", "%5", "")
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_shell", "sess-shell"); err != nil {
+		t.Fatalf("_shell: %v", err)
 	}
-	got := cmd.KittyLaunchArgs(entry)
-	want := []string{"@", "--to", "unix:/tmp/kitty-sock", "launch", "--type=window", "--cwd=/home/user/project", "--match", "id:42"}
-	if len(got) != len(want) {
-		t.Fatalf("len = %d, want %d: %v", len(got), len(want), got)
+	want := "split-window -t %5 -c /home/user/project -P -F #{pane_id}"
+	if got := calls(); len(got) != 1 || got[0] != want {
+		t.Errorf("tmux calls = %q, want [%q]", got, want)
 	}
-	for i := range want {
-		if got[i] != want[i] {
-			t.Errorf("arg[%d] = %q, want %q", i, got[i], want[i])
-		}
+}
+
+func TestShellCmd_Wezterm(t *testing.T) {
+	setupQueueDir(t)
+	calls := fakeCLI(t, "wezterm")
+	opts, _, _ := testOptions()
+	seedEntry(t, "sess-shell", "/home/user/project", "permission_prompt", 1001)
+	setPane(t, "sess-shell", "wezterm", "3", "")
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_shell", "sess-shell"); err != nil {
+		t.Fatalf("_shell: %v", err)
+	}
+	want := "cli split-pane --pane-id 3 --cwd /home/user/project"
+	if got := calls(); len(got) != 1 || got[0] != want {
+		t.Errorf("wezterm calls = %q, want [%q]", got, want)
+	}
+}
+
+func TestShellCmd_UnknownTerminal(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+	seedEntry(t, "sess-shell", "/home/user/project", "permission_prompt", 1001)
+	setPane(t, "sess-shell", "teletype", "1", "")
+
+	_, _, err := executeCommand(cmd.NewRootCmd(opts), "_shell", "sess-shell")
+	if err == nil || !strings.Contains(err.Error(), "teletype") {
+		t.Errorf("err = %v, want unknown terminal", err)
+	}
+	if n := entryCount(t); n != 1 {
+		t.Errorf("expected entry to persist, got %d entries", n)
 	}
 }
 
@@ -54,7 +96,7 @@ func TestShellCmd_MissingSession(t *testing.T) {
 	}
 }
 
-func TestShellCmd_NoKittyWindowID(t *testing.T) {
+func TestShellCmd_NoWindowID(t *testing.T) {
 	setupQueueDir(t)
 	opts, _, _ := testOptions()
 
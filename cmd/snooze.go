@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+// snoozeDurations are the durations offered by shell completion.
+var snoozeDurations = []string{"15m", "30m", "1h", "2h", "4h", "1d"}
+
+func newSnoozeCmd(opts Options) *cobra.Command {
+	var off bool
+
+	cmd := &cobra.Command{
+		Use:   "snooze <session_id> [duration]",
+		Short: "Hide a session until its next event or for a while",
+		Long: `Hide a session from list, first and the picker.
+
+The entry stays in the queue and comes back when the session pushes a new
+event or, when a duration is given (15m, 2h, 1d), when it expires.
+--off brings a snoozed session back right away.`,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return sessionIDCompletions(opts.Store, toComplete), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return snoozeDurations, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID := args[0]
+			var d time.Duration
+			if len(args) == 2 {
+				if off {
+					return fmt.Errorf("--off takes no duration")
+				}
+				var err error
+				if d, err = queue.ParseDuration(args[1]); err != nil {
+					return err
+				}
+				if d <= 0 {
+					return fmt.Errorf("invalid duration %q: must be positive", args[1])
+				}
+			}
+
+			var err error
+			if off {
+				err = opts.Store.Update(sessionID, func(e *queue.Entry) error {
+					e.Snoozed = false
+					e.SnoozeUntil = time.Time{}
+					return nil
+				})
+			} else {
+				err = snoozeSession(opts, sessionID, d)
+			}
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("session %s is not in the queue", sessionID)
+			}
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case off:
+				fmt.Fprintf(opts.Stdout, "Unsnoozed %s\n", sessionID)
+			case d > 0:
+				fmt.Fprintf(opts.Stdout, "Snoozed %s until %s\n", sessionID, opts.TimeNow().Add(d).Local().Format("Mon 15:04"))
+			default:
+				fmt.Fprintf(opts.Stdout, "Snoozed %s until its next event\n", sessionID)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&off, "off", false, "Bring a snoozed session back")
+	_ = cmd.RegisterFlagCompletionFunc("off", cobra.NoFileCompletions)
+	return cmd
+}
@@ -0,0 +1,129 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duboisf/cc-queue/cmd"
+)
+
+func listOutput(t *testing.T, opts cmd.Options) string {
+	t.Helper()
+	var out strings.Builder
+	opts.Stdout = &out
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "list"); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	return out.String()
+}
+
+func TestSnooze_UntilNextEvent(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/parked", "idle_prompt", 1)
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "snooze", "s1"); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if got := stdout.String(); got != "Snoozed s1 until its next event\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	if out := listOutput(t, opts); strings.Contains(out, "/tmp/parked") {
+		t.Errorf("snoozed entry listed:\n%s", out)
+	}
+
+	seedEntry(t, "s1", "/tmp/parked", "permission_prompt", 1)
+	if out := listOutput(t, opts); !strings.Contains(out, "/tmp/parked") {
+		t.Errorf("entry should be back after a new event:\n%s", out)
+	}
+}
+
+func TestSnooze_Duration(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/parked", "idle_prompt", 1)
+	opts, stdout, _ := testOptions()
+	start := opts.TimeNow()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "snooze", "s1", "2h"); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Snoozed s1 until ") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+
+	sf, err := testStore().ReadSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(2 * time.Hour); !sf.Current.SnoozeUntil.Equal(want) {
+		t.Errorf("SnoozeUntil = %v, want %v", sf.Current.SnoozeUntil, want)
+	}
+
+	opts.TimeNow = func() time.Time { return start.Add(time.Hour) }
+	if out := listOutput(t, opts); strings.Contains(out, "/tmp/parked") {
+		t.Errorf("entry listed before the snooze expired:\n%s", out)
+	}
+	opts.TimeNow = func() time.Time { return start.Add(3 * time.Hour) }
+	if out := listOutput(t, opts); !strings.Contains(out, "/tmp/parked") {
+		t.Errorf("entry should be back once the snooze expired:\n%s", out)
+	}
+}
+
+func TestSnooze_Off(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/parked", "idle_prompt", 1)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "snooze", "s1"); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "snooze", "--off", "s1"); err != nil {
+		t.Fatalf("snooze --off: %v", err)
+	}
+	if out := listOutput(t, opts); !strings.Contains(out, "/tmp/parked") {
+		t.Errorf("entry should be back after --off:\n%s", out)
+	}
+}
+
+func TestSnooze_Errors(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/parked", "idle_prompt", 1)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing session", []string{"snooze", "nope"}, "session nope is not in the queue"},
+		{"bad duration", []string{"snooze", "s1", "soon"}, `invalid duration "soon"`},
+		{"zero duration", []string{"snooze", "s1", "0s"}, "must be positive"},
+		{"off with duration", []string{"snooze", "--off", "s1", "1h"}, "--off takes no duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, _, _ := testOptions()
+			_, _, err := executeCommand(cmd.NewRootCmd(opts), tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAction_SnoozeFor(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "s1", "/tmp/a", "idle_prompt", 1)
+	opts, _, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_action", "--for", "1h", "snooze", "s1"); err != nil {
+		t.Fatalf("_action snooze --for: %v", err)
+	}
+	sf, err := testStore().ReadSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := opts.TimeNow().Add(time.Hour); !sf.Current.Snoozed || !sf.Current.SnoozeUntil.Equal(want) {
+		t.Errorf("entry = %+v, want snoozed until %v", sf.Current, want)
+	}
+}
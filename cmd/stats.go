@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+type waitStatsJSON struct {
+	Key          string  `json:"key"`
+	Count        int     `json:"count"`
+	TotalSeconds float64 `json:"total_seconds"`
+	P50Seconds   float64 `json:"p50_seconds"`
+	P95Seconds   float64 `json:"p95_seconds"`
+}
+
+type statsViewJSON struct {
+	Since     time.Time       `json:"since"`
+	Until     time.Time       `json:"until"`
+	Waits     int             `json:"waits"`
+	ByProject []waitStatsJSON `json:"by_project"`
+	ByEvent   []waitStatsJSON `json:"by_event"`
+}
+
+func toWaitStatsJSON(stats []queue.WaitStats) []waitStatsJSON {
+	out := make([]waitStatsJSON, len(stats))
+	for i, s := range stats {
+		out[i] = waitStatsJSON{s.Key, s.Count, s.Total.Seconds(), s.P50.Seconds(), s.P95.Seconds()}
+	}
+	return out
+}
+
+// writeWaitStats prints one aggregation table.
+func writeWaitStats(w io.Writer, title, keyLabel string, stats []queue.WaitStats) {
+	width := len(keyLabel)
+	for _, s := range stats {
+		width = max(width, len(s.Key))
+	}
+	fmt.Fprintf(w, "\n%s:\n\n", title)
+	fmt.Fprintf(w, "    %-*s  %5s  %6s  %5s  %5s\n", width, keyLabel, "COUNT", "TOTAL", "P50", "P95")
+	for _, s := range stats {
+		fmt.Fprintf(w, "    %-*s  %5d  %6s  %5s  %5s\n", width, s.Key, s.Count,
+			queue.FormatDuration(s.Total), queue.FormatDuration(s.P50), queue.FormatDuration(s.P95))
+	}
+}
+
+func newStatsCmd(opts Options) *cobra.Command {
+	var since, until, output string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show how long sessions waited for you",
+		Long: `Show how long sessions waited for you, per project and per event type.
+
+A wait starts when a session first needs attention (PERM, ASK, IDLE, ...)
+and ends when you respond to it. Waits are reconstructed from the journal
+(see "cc-queue log") and counted in the window where they ended.
+
+--since and --until accept a duration before now (90m, 2h, 7d) or a
+date (2006-01-02, 2006-01-02T15:04, RFC 3339).`,
+		Args: cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := opts.TimeNow()
+			from, err := parseTimeFlag(since, now)
+			if err != nil {
+				return err
+			}
+			to, err := parseTimeFlag(until, now)
+			if err != nil {
+				return err
+			}
+			if to.IsZero() {
+				to = now
+			}
+
+			// Read everything: a wait ending in the window may have started before it.
+			records, err := opts.Journal.Read(queue.JournalFilter{Until: to})
+			if err != nil {
+				return err
+			}
+			var waits []queue.Wait
+			for _, w := range queue.Waits(records) {
+				if !w.End.Before(from) {
+					waits = append(waits, w)
+				}
+			}
+
+			byProject := queue.SummarizeWaits(waits, func(w queue.Wait) string { return queue.ShortenPath(w.CWD) })
+			byEvent := queue.SummarizeWaits(waits, func(w queue.Wait) string { return queue.EventLabel(w.Event) })
+
+			if output == "json" {
+				data, err := json.MarshalIndent(statsViewJSON{
+					Since:     from,
+					Until:     to,
+					Waits:     len(waits),
+					ByProject: toWaitStatsJSON(byProject),
+					ByEvent:   toWaitStatsJSON(byEvent),
+				}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(opts.Stdout, string(data))
+				return nil
+			}
+
+			fmt.Fprintf(opts.Stdout, "Waits from %s to %s: %d answered\n",
+				from.Local().Format("2006-01-02 15:04"), to.Local().Format("2006-01-02 15:04"), len(waits))
+			if len(waits) == 0 {
+				return nil
+			}
+			writeWaitStats(opts.Stdout, "By project", "PROJECT", byProject)
+			writeWaitStats(opts.Stdout, "By event", "EVENT", byEvent)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "7d", "Start of the window (e.g. 24h, 7d, 2006-01-02)")
+	cmd.Flags().StringVar(&until, "until", "", "End of the window (default now)")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", `Output format: "text" or "json"`)
+	_ = cmd.RegisterFlagCompletionFunc("since", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("until", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
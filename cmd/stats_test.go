@@ -0,0 +1,95 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// seedWait journals a push followed by a pop for sessionID.
+func seedWait(t *testing.T, sessionID, cwd, event string, start time.Time, d time.Duration) {
+	t.Helper()
+	j := testJournal()
+	if err := j.Append(queue.JournalRecord{Time: start, Op: queue.OpPush, SessionID: sessionID, CWD: cwd, Event: event}); err != nil {
+		t.Fatalf("seedWait: %v", err)
+	}
+	if err := j.Append(queue.JournalRecord{Time: start.Add(d), Op: queue.OpPop, SessionID: sessionID, CWD: cwd, Event: "working"}); err != nil {
+		t.Fatalf("seedWait: %v", err)
+	}
+}
+
+func TestStats_Empty(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "stats"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "0 answered") {
+		t.Errorf("output = %q, want 0 answered", stdout.String())
+	}
+}
+
+func TestStats_Text(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	now := opts.TimeNow()
+
+	seedWait(t, "a", "/tmp/infra", "permission_prompt", now.Add(-2*time.Hour), 10*time.Minute)
+	seedWait(t, "b", "/tmp/web", "idle_prompt", now.Add(-time.Hour), 2*time.Minute)
+	// Outside the default 7-day window.
+	seedWait(t, "c", "/tmp/old", "idle_prompt", now.Add(-30*24*time.Hour), time.Minute)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "stats"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := stdout.String()
+	if !strings.Contains(got, "2 answered") {
+		t.Errorf("output missing wait count:\n%s", got)
+	}
+	for _, want := range []string{"/tmp/infra", "/tmp/web", "PERM", "IDLE", "10m"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "/tmp/old") {
+		t.Errorf("output includes wait outside window:\n%s", got)
+	}
+	// Costliest project first.
+	if strings.Index(got, "/tmp/infra") > strings.Index(got, "/tmp/web") {
+		t.Errorf("expected /tmp/infra before /tmp/web:\n%s", got)
+	}
+}
+
+func TestStats_JSON(t *testing.T) {
+	setupQueueDir(t)
+	opts, stdout, _ := testOptions()
+	now := opts.TimeNow()
+
+	seedWait(t, "a", "/tmp/infra", "permission_prompt", now.Add(-2*time.Hour), 90*time.Second)
+
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "stats", "--since", "24h", "-o", "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got struct {
+		Waits   int `json:"waits"`
+		ByEvent []struct {
+			Key          string  `json:"key"`
+			Count        int     `json:"count"`
+			TotalSeconds float64 `json:"total_seconds"`
+		} `json:"by_event"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if got.Waits != 1 || len(got.ByEvent) != 1 {
+		t.Fatalf("got %+v, want one wait", got)
+	}
+	if e := got.ByEvent[0]; e.Key != "PERM" || e.Count != 1 || e.TotalSeconds != 90 {
+		t.Errorf("by_event[0] = %+v, want PERM/1/90", e)
+	}
+}
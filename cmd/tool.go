@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+func newToolCmd(opts Options) *cobra.Command {
+	return &cobra.Command{
+		Use:    "tool",
+		Hidden: true,
+		Short:  "Record the pending tool call (called by PreToolUse hook, reads stdin)",
+		Args:   cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input, err := queue.ParseHookInput(opts.Stdin)
+			if err != nil {
+				return err
+			}
+			pre, ok := input.Event.(*queue.PreToolUseInput)
+			if !ok {
+				return nil
+			}
+
+			tool := queue.NewToolCall(pre)
+			queue.Debugf("TOOL session=%s %s", input.SessionID, tool.OneLine(80))
+			err = opts.Store.Update(input.SessionID, func(e *queue.Entry) error {
+				e.Tool = tool
+				return nil
+			})
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // session not tracked (e.g. outside kitty)
+			}
+			return err
+		},
+	}
+}
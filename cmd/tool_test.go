@@ -0,0 +1,74 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+)
+
+func TestTool_RecordsPendingToolCall(t *testing.T) {
+	setupQueueDir(t)
+	seedEntry(t, "sess-tool", "/tmp/project", "working", 1001)
+
+	input := `{"session_id":"sess-tool","cwd":"/tmp/project","hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"rm -rf build"}}`
+	opts, _, _ := testOptionsWithStdin(input)
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "tool"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sf, err := testStore().ReadSession("sess-tool")
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	if sf.Current.Tool == nil || sf.Current.Tool.Name != "Bash" || sf.Current.Tool.Summary != "rm -rf build" {
+		t.Errorf("Tool = %+v, want Bash: rm -rf build", sf.Current.Tool)
+	}
+	if sf.Current.Event != "working" {
+		t.Errorf("Event = %q, want unchanged working", sf.Current.Event)
+	}
+}
+
+func TestTool_UntrackedSession(t *testing.T) {
+	setupQueueDir(t)
+
+	input := `{"session_id":"nope","hook_event_name":"PreToolUse","tool_name":"Read","tool_input":{"file_path":"/x"}}`
+	opts, _, _ := testOptionsWithStdin(input)
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "tool"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n := entryCount(t); n != 0 {
+		t.Errorf("expected no entry to be created, got %d", n)
+	}
+}
+
+func TestTool_ShownForPermissionPrompt(t *testing.T) {
+	setupQueueDir(t)
+	t.Setenv("KITTY_WINDOW_ID", "42")
+	seedEntry(t, "sess-perm", "/tmp/project", "working", 1001)
+
+	steps := []struct{ command, input string }{
+		{"tool", `{"session_id":"sess-perm","cwd":"/tmp/project","hook_event_name":"PreToolUse","tool_name":"WebFetch","tool_input":{"url":"https://example.com/api"}}`},
+		{"push", `{"session_id":"sess-perm","cwd":"/tmp/project","hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs your permission to use Fetch"}`},
+	}
+	for _, s := range steps {
+		opts, _, _ := testOptionsWithStdin(s.input)
+		if _, _, err := executeCommand(cmd.NewRootCmd(opts), s.command); err != nil {
+			t.Fatalf("%s: %v", s.command, err)
+		}
+	}
+
+	for _, args := range [][]string{{"list"}, {"_preview", "sess-perm"}} {
+		opts, stdout, _ := testOptions()
+		out, _, err := executeCommand(cmd.NewRootCmd(opts), args...)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		out += stdout.String()
+		for _, want := range []string{"WebFetch", "https://example.com/api"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%v output missing %q:\n%s", args, want, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+	"github.com/duboisf/cc-queue/internal/webhook"
+	"github.com/spf13/cobra"
+)
+
+// outboxDir holds the webhook messages waiting to be delivered.
+func outboxDir() string {
+	return filepath.Join(queue.Dir(), "outbox")
+}
+
+// scheduleWebhooks sends entry, which just entered its event, to the
+// webhooks wanting it.
+func scheduleWebhooks(opts Options, entry *queue.Entry) {
+	cfg := queue.ReadConfig()
+	if len(cfg.Webhooks) == 0 {
+		return
+	}
+	if cfg.DNDMutes(entry.CWD, opts.TimeNow()) {
+		queue.Debugf("WEBHOOK skip: do not disturb session=%s", entry.SessionID)
+		return
+	}
+	queueWebhooks(opts, cfg, entry)
+}
+
+// queueWebhooks renders the webhooks wanting each of entries into the outbox
+// and spawns a background _deliver to send them. Errors are only logged:
+// hooks must not fail over a webhook.
+func queueWebhooks(opts Options, cfg queue.Config, entries ...*queue.Entry) {
+	if opts.Spawn == nil {
+		return
+	}
+	var msgs []webhook.Message
+	for _, e := range entries {
+		for _, w := range cfg.Webhooks {
+			if !w.Wants(e.Event) {
+				continue
+			}
+			m, err := webhook.Render(w, e)
+			if err != nil {
+				queue.Debugf("WEBHOOK %v", err)
+				continue
+			}
+			msgs = append(msgs, m)
+		}
+	}
+	if len(msgs) == 0 {
+		return
+	}
+	if err := webhook.NewOutbox(outboxDir()).Add(msgs...); err != nil {
+		queue.Debugf("WEBHOOK outbox: %v", err)
+		return
+	}
+	if err := opts.Spawn("_deliver"); err != nil {
+		queue.Debugf("WEBHOOK spawn failed: %v", err)
+	}
+}
+
+func newDeliverCmd(opts Options) *cobra.Command {
+	return &cobra.Command{
+		Use:    "_deliver",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return webhook.NewOutbox(outboxDir()).Run(cmd.Context())
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package cmd_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/cmd"
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// outboxFiles returns the messages waiting in the webhook outbox.
+func outboxFiles(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(queue.Dir(), "outbox", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestPush_Webhook(t *testing.T) {
+	setupQueueDir(t)
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.Method+" "+string(body))
+	}))
+	defer srv.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := queue.WriteConfig(queue.Config{Webhooks: []queue.Webhook{
+		{Name: "all", URL: srv.URL, Body: "{{label .Event}} {{.SessionID}}"},
+		{Name: "ask only", URL: srv.URL, Events: []string{"ASK"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	spawned := pushWithSpawn(t, "permission_prompt")
+	if len(spawned) != 1 || strings.Join(spawned[0], " ") != "_deliver" {
+		t.Fatalf("spawned %q, want _deliver", spawned)
+	}
+	if n := len(outboxFiles(t)); n != 1 {
+		t.Fatalf("outbox has %d messages, want 1", n)
+	}
+
+	opts, _, _ := testOptions()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_deliver"); err != nil {
+		t.Fatalf("_deliver: %v", err)
+	}
+	if len(bodies) != 1 || bodies[0] != "POST PERM sess-a" {
+		t.Errorf("requests = %q, want one POST PERM sess-a", bodies)
+	}
+	if n := len(outboxFiles(t)); n != 0 {
+		t.Errorf("outbox has %d messages after delivery, want 0", n)
+	}
+}
+
+func TestPush_WebhookSkipped(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  queue.Config
+	}{
+		{"no webhooks", queue.Config{}},
+		{"event not wanted", queue.Config{Webhooks: []queue.Webhook{{URL: "http://127.0.0.1:1", Events: []string{"IDLE"}}}}},
+		{"invalid template", queue.Config{Webhooks: []queue.Webhook{{URL: "http://127.0.0.1:1", Body: "{{"}}}},
+		{"do not disturb", queue.Config{DND: true, Webhooks: []queue.Webhook{{URL: "http://127.0.0.1:1"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupQueueDir(t)
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if err := queue.WriteConfig(tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+			if spawned := pushWithSpawn(t, "permission_prompt"); len(spawned) != 0 {
+				t.Errorf("spawned %q, want nothing", spawned)
+			}
+			if n := len(outboxFiles(t)); n != 0 {
+				t.Errorf("outbox has %d messages, want 0", n)
+			}
+		})
+	}
+}
+
+func TestDeliver_EmptyOutbox(t *testing.T) {
+	setupQueueDir(t)
+	opts, _, _ := testOptions()
+	if _, _, err := executeCommand(cmd.NewRootCmd(opts), "_deliver"); err != nil {
+		t.Fatalf("_deliver: %v", err)
+	}
+}
@@ -2,9 +2,13 @@ module github.com/duboisf/cc-queue
 
 go 1.25.6
 
-require github.com/spf13/cobra v1.10.2
+require (
+	github.com/godbus/dbus/v5 v5.2.2
+	github.com/spf13/cobra v1.10.2
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/sys v0.27.0 // indirect
 )
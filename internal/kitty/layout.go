@@ -48,9 +48,10 @@ type LayoutManager struct {
 	Runner Runner
 }
 
-// NewLayoutManager returns a LayoutManager that shells out to kitty.
+// NewLayoutManager returns a LayoutManager that talks to kitty over
+// $KITTY_LISTEN_ON, or shells out to kitty without a reachable socket.
 func NewLayoutManager() *LayoutManager {
-	return &LayoutManager{Runner: &ExecRunner{}}
+	return &LayoutManager{Runner: &ClientRunner{Client: NewClient(""), Fallback: &ExecRunner{}}}
 }
 
 // CurrentLayout returns the layout name of the focused tab.
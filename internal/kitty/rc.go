@@ -0,0 +1,206 @@
+package kitty
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// rcPrefix and rcSuffix frame remote control messages, both ways.
+const (
+	rcPrefix = "\x1bP@kitty-cmd"
+	rcSuffix = "\x1b\\"
+)
+
+// rcVersion is the client version sent with commands. kitty rejects
+// clients newer than itself, so it is the oldest version with every
+// command the client uses.
+var rcVersion = []int{0, 20, 0}
+
+// defaultTimeout bounds a remote control round trip.
+const defaultTimeout = 5 * time.Second
+
+// ErrUnreachable is returned when the kitty socket cannot be reached, so
+// callers can fall back to kitty @.
+var ErrUnreachable = errors.New("kitty socket unreachable")
+
+// Client sends remote control commands to kitty over its listen_on socket,
+// speaking the protocol of kitty @ without forking it.
+type Client struct {
+	// Addr is the socket, as in listen_on: unix:/path, unix:@abstract or
+	// tcp:host:port.
+	Addr string
+	// Timeout bounds each command.
+	Timeout time.Duration
+}
+
+// NewClient returns a client for the kitty at addr, or at $KITTY_LISTEN_ON
+// when addr is empty.
+func NewClient(addr string) *Client {
+	if addr == "" {
+		addr = os.Getenv("KITTY_LISTEN_ON")
+	}
+	return &Client{Addr: addr, Timeout: defaultTimeout}
+}
+
+// rcRequest is a remote control command.
+type rcRequest struct {
+	Cmd        string `json:"cmd"`
+	Version    []int  `json:"version"`
+	NoResponse bool   `json:"no_response"`
+	Payload    any    `json:"payload,omitempty"`
+}
+
+// rcResponse is kitty's reply to a command.
+type rcResponse struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// dial connects to the socket of c.
+func (c *Client) dial() (net.Conn, error) {
+	network, address, ok := strings.Cut(c.Addr, ":")
+	if !ok || (network != "unix" && network != "tcp") {
+		return nil, fmt.Errorf("%w: unsupported address %q", ErrUnreachable, c.Addr)
+	}
+	conn, err := net.DialTimeout(network, address, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	return conn, nil
+}
+
+// Call sends cmd with payload and returns the data of kitty's reply:
+// unquoted when it is a string, as kitty @ prints it.
+func (c *Client) Call(cmd string, payload any) ([]byte, error) {
+	msg, err := json.Marshal(rcRequest{Cmd: cmd, Version: rcVersion, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	if _, err := conn.Write([]byte(rcPrefix + string(msg) + rcSuffix)); err != nil {
+		return nil, err
+	}
+	raw, err := readMessage(bufio.NewReader(conn))
+	if err != nil {
+		return nil, err
+	}
+	var resp rcResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("parsing kitty response: %w", err)
+	}
+	if !resp.OK {
+		return nil, errors.New(resp.Error)
+	}
+	var s string
+	if json.Unmarshal(resp.Data, &s) == nil {
+		return []byte(s), nil
+	}
+	if string(resp.Data) == "null" {
+		return nil, nil
+	}
+	return resp.Data, nil
+}
+
+// readMessage reads one framed message from r and returns its JSON.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	var buf []byte
+	for !bytes.HasSuffix(buf, []byte(rcSuffix)) {
+		chunk, err := r.ReadBytes(rcSuffix[len(rcSuffix)-1])
+		buf = append(buf, chunk...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	start := bytes.Index(buf, []byte(rcPrefix))
+	if start < 0 {
+		return nil, errors.New("malformed response")
+	}
+	return buf[start+len(rcPrefix) : len(buf)-len(rcSuffix)], nil
+}
+
+// Ls returns the kitty @ ls JSON of every OS window, tab and window.
+func (c *Client) Ls() ([]byte, error) {
+	return c.Call("ls", nil)
+}
+
+// FocusWindow focuses the window matching match (id:7).
+func (c *Client) FocusWindow(match string) error {
+	_, err := c.Call("focus-window", map[string]any{"match": match})
+	return err
+}
+
+// LaunchOptions are the kitty @ launch options the client sets; the rest
+// keep kitty's defaults.
+type LaunchOptions struct {
+	Type     string `json:"type,omitempty"`
+	CWD      string `json:"cwd,omitempty"`
+	Match    string `json:"match,omitempty"`
+	TabTitle string `json:"tab_title,omitempty"`
+}
+
+// Launch opens a window or tab and returns the new window's ID.
+func (c *Client) Launch(o LaunchOptions) (string, error) {
+	out, err := c.Call("launch", o)
+	return strings.TrimSpace(string(out)), err
+}
+
+// GotoLayout switches the active tab to layout.
+func (c *Client) GotoLayout(layout string) error {
+	_, err := c.Call("goto-layout", map[string]any{"layout": layout})
+	return err
+}
+
+// SetTabTitle sets the title of the tab matching match (window_id:7).
+func (c *Client) SetTabTitle(match, title string) error {
+	_, err := c.Call("set-tab-title", map[string]any{"match": match, "title": title})
+	return err
+}
+
+// SendText types text into the window matching match.
+func (c *Client) SendText(match, text string) error {
+	_, err := c.Call("send-text", map[string]any{
+		"match": match,
+		"data":  "base64:" + base64.StdEncoding.EncodeToString([]byte(text)),
+	})
+	return err
+}
+
+// ClientRunner implements Runner over the socket, falling back to another
+// Runner when the socket cannot be reached.
+type ClientRunner struct {
+	Client   *Client
+	Fallback Runner
+}
+
+// Ls lists the windows over the socket.
+func (r *ClientRunner) Ls() ([]byte, error) {
+	out, err := r.Client.Ls()
+	if errors.Is(err, ErrUnreachable) {
+		return r.Fallback.Ls()
+	}
+	return out, err
+}
+
+// GotoLayout switches the layout over the socket.
+func (r *ClientRunner) GotoLayout(name string) error {
+	err := r.Client.GotoLayout(name)
+	if errors.Is(err, ErrUnreachable) {
+		return r.Fallback.GotoLayout(name)
+	}
+	return err
+}
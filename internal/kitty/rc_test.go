@@ -0,0 +1,263 @@
+package kitty_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/duboisf/cc-queue/internal/kitty"
+)
+
+// rcCommand is a command as the fake kitty receives it.
+type rcCommand struct {
+	Cmd     string         `json:"cmd"`
+	Version []int          `json:"version"`
+	Payload map[string]any `json:"payload"`
+}
+
+// fakeKitty is a remote control socket answering each command with the
+// reply its handler returns.
+type fakeKitty struct {
+	mu   sync.Mutex
+	cmds []rcCommand
+}
+
+// commands returns the commands received so far.
+func (f *fakeKitty) commands() []rcCommand {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cmds
+}
+
+// serveFakeKitty listens on a unix socket and answers every command with
+// reply(cmd), framed like kitty does. It returns the listen_on address.
+func serveFakeKitty(t *testing.T, reply func(c rcCommand) string) (string, *fakeKitty) {
+	t.Helper()
+	// Socket paths are limited to ~108 bytes, too short for t.TempDir().
+	dir, err := os.MkdirTemp("", "kitty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeKitty{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.handle(t, conn, reply)
+		}
+	}()
+	return "unix:" + sock, f
+}
+
+func (f *fakeKitty) handle(t *testing.T, conn net.Conn, reply func(c rcCommand) string) {
+	defer conn.Close()
+	msg, err := bufio.NewReader(conn).ReadBytes('\\')
+	if err != nil {
+		t.Errorf("reading command: %v", err)
+		return
+	}
+	body, ok := bytes.CutPrefix(msg, []byte("\x1bP@kitty-cmd"))
+	body, ok2 := bytes.CutSuffix(body, []byte("\x1b\\"))
+	if !ok || !ok2 {
+		t.Errorf("malformed command %q", msg)
+		return
+	}
+	var c rcCommand
+	if err := json.Unmarshal(body, &c); err != nil {
+		t.Errorf("parsing command: %v", err)
+		return
+	}
+	f.mu.Lock()
+	f.cmds = append(f.cmds, c)
+	f.mu.Unlock()
+	conn.Write([]byte("\x1bP@kitty-cmd" + reply(c) + "\x1b\\"))
+}
+
+// ok replies success with data.
+func ok(data string) func(rcCommand) string {
+	return func(rcCommand) string { return `{"ok": true, "data": ` + data + `}` }
+}
+
+func TestClient_Ls(t *testing.T) {
+	ls := `[{"tabs":[{"windows":[{"id":1},{"id":4}]}]}]`
+	quoted, _ := json.Marshal(ls)
+	addr, f := serveFakeKitty(t, ok(string(quoted)))
+
+	out, err := kitty.NewClient(addr).Ls()
+	if err != nil {
+		t.Fatalf("Ls: %v", err)
+	}
+	if string(out) != ls {
+		t.Errorf("Ls = %s, want %s", out, ls)
+	}
+	cmds := f.commands()
+	if len(cmds) != 1 || cmds[0].Cmd != "ls" || len(cmds[0].Version) != 3 {
+		t.Errorf("commands = %+v, want one versioned ls", cmds)
+	}
+}
+
+func TestClient_FocusWindow(t *testing.T) {
+	addr, f := serveFakeKitty(t, ok("null"))
+
+	if err := kitty.NewClient(addr).FocusWindow("id:7"); err != nil {
+		t.Fatalf("FocusWindow: %v", err)
+	}
+	cmds := f.commands()
+	if len(cmds) != 1 || cmds[0].Cmd != "focus-window" || cmds[0].Payload["match"] != "id:7" {
+		t.Errorf("commands = %+v, want focus-window on id:7", cmds)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	addr, _ := serveFakeKitty(t, func(rcCommand) string {
+		return `{"ok": false, "error": "No matching windows for expression: id:7"}`
+	})
+
+	err := kitty.NewClient(addr).FocusWindow("id:7")
+	if err == nil || !strings.Contains(err.Error(), "No matching windows") {
+		t.Fatalf("FocusWindow error = %v, want kitty's error", err)
+	}
+	if errors.Is(err, kitty.ErrUnreachable) {
+		t.Error("kitty's error reported as unreachable")
+	}
+}
+
+func TestClient_Launch(t *testing.T) {
+	for _, data := range []string{`"12"`, `12`} {
+		t.Run(data, func(t *testing.T) {
+			addr, f := serveFakeKitty(t, ok(data))
+
+			id, err := kitty.NewClient(addr).Launch(kitty.LaunchOptions{
+				Type: "tab", CWD: "/home/me/api", Match: "window_id:7", TabTitle: "api",
+			})
+			if err != nil {
+				t.Fatalf("Launch: %v", err)
+			}
+			if id != "12" {
+				t.Errorf("Launch = %q, want 12", id)
+			}
+			want := map[string]any{"type": "tab", "cwd": "/home/me/api", "match": "window_id:7", "tab_title": "api"}
+			cmds := f.commands()
+			if len(cmds) != 1 || cmds[0].Cmd != "launch" {
+				t.Fatalf("commands = %+v, want one launch", cmds)
+			}
+			for k, v := range want {
+				if cmds[0].Payload[k] != v {
+					t.Errorf("payload[%s] = %v, want %v", k, cmds[0].Payload[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GotoLayout(t *testing.T) {
+	addr, f := serveFakeKitty(t, ok("null"))
+
+	if err := kitty.NewClient(addr).GotoLayout("stack"); err != nil {
+		t.Fatalf("GotoLayout: %v", err)
+	}
+	cmds := f.commands()
+	if len(cmds) != 1 || cmds[0].Cmd != "goto-layout" || cmds[0].Payload["layout"] != "stack" {
+		t.Errorf("commands = %+v, want goto-layout stack", cmds)
+	}
+}
+
+func TestClient_SetTabTitle(t *testing.T) {
+	addr, f := serveFakeKitty(t, ok("null"))
+
+	if err := kitty.NewClient(addr).SetTabTitle("window_id:7", "api"); err != nil {
+		t.Fatalf("SetTabTitle: %v", err)
+	}
+	cmds := f.commands()
+	if len(cmds) != 1 || cmds[0].Cmd != "set-tab-title" ||
+		cmds[0].Payload["match"] != "window_id:7" || cmds[0].Payload["title"] != "api" {
+		t.Errorf("commands = %+v, want set-tab-title api on window_id:7", cmds)
+	}
+}
+
+func TestClient_SendText(t *testing.T) {
+	addr, f := serveFakeKitty(t, ok("null"))
+
+	if err := kitty.NewClient(addr).SendText("id:7", "ls\r"); err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+	cmds := f.commands()
+	if len(cmds) != 1 || cmds[0].Cmd != "send-text" || cmds[0].Payload["match"] != "id:7" {
+		t.Fatalf("commands = %+v, want send-text on id:7", cmds)
+	}
+	data, _ := cmds[0].Payload["data"].(string)
+	enc, found := strings.CutPrefix(data, "base64:")
+	text, err := base64.StdEncoding.DecodeString(enc)
+	if !found || err != nil || string(text) != "ls\r" {
+		t.Errorf("data = %q, want base64 of %q", data, "ls\r")
+	}
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	for _, addr := range []string{"", "unix:" + filepath.Join(t.TempDir(), "missing"), "fd:3"} {
+		t.Run(addr, func(t *testing.T) {
+			t.Setenv("KITTY_LISTEN_ON", "")
+			_, err := kitty.NewClient(addr).Ls()
+			if !errors.Is(err, kitty.ErrUnreachable) {
+				t.Errorf("Ls error = %v, want ErrUnreachable", err)
+			}
+		})
+	}
+}
+
+func TestNewClient_DefaultsToListenOn(t *testing.T) {
+	t.Setenv("KITTY_LISTEN_ON", "unix:/tmp/kitty-1")
+	if c := kitty.NewClient(""); c.Addr != "unix:/tmp/kitty-1" {
+		t.Errorf("Addr = %q, want $KITTY_LISTEN_ON", c.Addr)
+	}
+}
+
+func TestClientRunner_UsesSocket(t *testing.T) {
+	addr, f := serveFakeKitty(t, ok("null"))
+	fallback := &mockRunner{}
+	r := &kitty.ClientRunner{Client: kitty.NewClient(addr), Fallback: fallback}
+
+	if err := r.GotoLayout("stack"); err != nil {
+		t.Fatalf("GotoLayout: %v", err)
+	}
+	if len(f.commands()) != 1 || len(fallback.gotoCalls) != 0 {
+		t.Errorf("socket commands = %d, fallback calls = %v, want 1 and none", len(f.commands()), fallback.gotoCalls)
+	}
+}
+
+func TestClientRunner_FallsBack(t *testing.T) {
+	fallback := &mockRunner{lsOutput: []byte("[]")}
+	r := &kitty.ClientRunner{
+		Client:   kitty.NewClient("unix:" + filepath.Join(t.TempDir(), "missing")),
+		Fallback: fallback,
+	}
+
+	out, err := r.Ls()
+	if err != nil || string(out) != "[]" {
+		t.Errorf("Ls = %q, %v, want the fallback's output", out, err)
+	}
+	if err := r.GotoLayout("stack"); err != nil {
+		t.Fatalf("GotoLayout: %v", err)
+	}
+	if len(fallback.gotoCalls) != 1 {
+		t.Errorf("fallback goto calls = %v, want one", fallback.gotoCalls)
+	}
+}
@@ -7,15 +7,18 @@ import (
 )
 
 type kittyWindow struct {
-	ID int `json:"id"`
+	ID        int  `json:"id"`
+	IsFocused bool `json:"is_focused"`
 }
 
 type kittyTab struct {
-	Windows []kittyWindow `json:"windows"`
+	IsFocused bool          `json:"is_focused"`
+	Windows   []kittyWindow `json:"windows"`
 }
 
 type kittyOSWindow struct {
-	Tabs []kittyTab `json:"tabs"`
+	IsFocused bool       `json:"is_focused"`
+	Tabs      []kittyTab `json:"tabs"`
 }
 
 // ParseWindowIDs extracts all window IDs from kitty @ ls JSON output.
@@ -34,3 +37,28 @@ func ParseWindowIDs(lsOutput []byte) (map[string]bool, error) {
 	}
 	return ids, nil
 }
+
+// FocusedWindowID returns the ID of the window with the keyboard focus in
+// kitty @ ls JSON output, or "" when kitty does not have the focus.
+func FocusedWindowID(lsOutput []byte) (string, error) {
+	var osWindows []kittyOSWindow
+	if err := json.Unmarshal(lsOutput, &osWindows); err != nil {
+		return "", fmt.Errorf("parsing kitty ls output: %w", err)
+	}
+	for _, ow := range osWindows {
+		if !ow.IsFocused {
+			continue
+		}
+		for _, t := range ow.Tabs {
+			if !t.IsFocused {
+				continue
+			}
+			for _, w := range t.Windows {
+				if w.IsFocused {
+					return strconv.Itoa(w.ID), nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
@@ -88,3 +88,30 @@ func TestParseWindowIDs_NoWindows(t *testing.T) {
 		t.Errorf("got %d IDs, want 0", len(ids))
 	}
 }
+
+func TestFocusedWindowID(t *testing.T) {
+	tests := []struct {
+		name, ls, want string
+	}{
+		{"focused", `[{"is_focused": true, "tabs": [{"is_focused": true, "windows": [{"id": 3}, {"id": 7, "is_focused": true}]}]}]`, "7"},
+		{"kitty in the background", `[{"is_focused": false, "tabs": [{"is_focused": false, "windows": [{"id": 7, "is_focused": false}]}]}]`, ""},
+		{"empty", `[]`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kitty.FocusedWindowID([]byte(tt.ls))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FocusedWindowID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusedWindowID_InvalidJSON(t *testing.T) {
+	if _, err := kitty.FocusedWindowID([]byte(`not json`)); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
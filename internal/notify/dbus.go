@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Well-known name, object path and interface of the freedesktop
+// notification service.
+const (
+	dbusDest  = "org.freedesktop.Notifications"
+	dbusPath  = "/org/freedesktop/Notifications"
+	dbusIface = "org.freedesktop.Notifications"
+)
+
+// Signal is a signal of the notification service about one notification.
+type Signal struct {
+	// Name is the member, ActionInvoked or NotificationClosed.
+	Name string
+	// ID is the notification's ID.
+	ID uint32
+	// Action is the invoked action's key, for ActionInvoked.
+	Action string
+}
+
+// Bus abstracts the session bus calls the D-Bus notifier makes, for testability.
+type Bus interface {
+	// Call invokes a method of the notification service and returns the
+	// body of the reply.
+	Call(ctx context.Context, method string, args ...any) ([]any, error)
+	// Signals streams the signals of the notification service until ctx is
+	// done. It returns once the subscription is active.
+	Signals(ctx context.Context) (<-chan Signal, error)
+}
+
+// SessionBus implements Bus over a connection to the session bus, opened
+// on first use.
+type SessionBus struct {
+	once sync.Once
+	conn *dbus.Conn
+	err  error
+}
+
+// connect returns the connection to the session bus.
+func (b *SessionBus) connect() (*dbus.Conn, error) {
+	b.once.Do(func() {
+		b.conn, b.err = dbus.ConnectSessionBus()
+		if b.err != nil {
+			b.err = fmt.Errorf("connecting to the session bus: %w", b.err)
+		}
+	})
+	return b.conn, b.err
+}
+
+func (b *SessionBus) Call(ctx context.Context, method string, args ...any) ([]any, error) {
+	conn, err := b.connect()
+	if err != nil {
+		return nil, err
+	}
+	call := conn.Object(dbusDest, dbusPath).CallWithContext(ctx, dbusIface+"."+method, 0, args...)
+	if call.Err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", dbusIface, method, call.Err)
+	}
+	return call.Body, nil
+}
+
+func (b *SessionBus) Signals(ctx context.Context) (<-chan Signal, error) {
+	conn, err := b.connect()
+	if err != nil {
+		return nil, err
+	}
+	match := []dbus.MatchOption{dbus.WithMatchObjectPath(dbusPath), dbus.WithMatchInterface(dbusIface)}
+	if err := conn.AddMatchSignalContext(ctx, match...); err != nil {
+		return nil, fmt.Errorf("subscribing to %s: %w", dbusIface, err)
+	}
+	raw := make(chan *dbus.Signal, 16)
+	conn.Signal(raw)
+
+	signals := make(chan Signal)
+	go func() {
+		defer close(signals)
+		defer conn.RemoveMatchSignal(match...)
+		defer conn.RemoveSignal(raw)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s, ok := <-raw:
+				if !ok {
+					return
+				}
+				sig, ok := parseSignal(s)
+				if !ok {
+					continue
+				}
+				select {
+				case signals <- sig:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return signals, nil
+}
+
+// parseSignal reads the notification signals out of s.
+func parseSignal(s *dbus.Signal) (Signal, bool) {
+	if s.Path != dbusPath || len(s.Body) < 2 {
+		return Signal{}, false
+	}
+	name, ok := strings.CutPrefix(s.Name, dbusIface+".")
+	if !ok {
+		return Signal{}, false
+	}
+	id, ok := s.Body[0].(uint32)
+	if !ok {
+		return Signal{}, false
+	}
+	sig := Signal{Name: name, ID: id}
+	switch name {
+	case "ActionInvoked":
+		if sig.Action, ok = s.Body[1].(string); !ok {
+			return Signal{}, false
+		}
+	case "NotificationClosed":
+	default:
+		return Signal{}, false
+	}
+	return sig, true
+}
+
+// DBus implements Notifier with org.freedesktop.Notifications.
+type DBus struct {
+	Bus Bus
+}
+
+// NewDBus returns a DBus notifier on the session bus.
+func NewDBus() *DBus {
+	return &DBus{Bus: &SessionBus{}}
+}
+
+// markupEscaper escapes the body for servers supporting body markup.
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (d *DBus) Notify(ctx context.Context, n Notification) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Subscribe before sending, so a quick click is not missed.
+	var signals <-chan Signal
+	if len(n.Actions) > 0 {
+		var err error
+		if signals, err = d.Bus.Signals(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	actions := []string{}
+	for _, a := range n.Actions {
+		actions = append(actions, a.Key, a.Label)
+	}
+	reply, err := d.Bus.Call(ctx, "Notify",
+		"cc-queue",                    // app_name
+		uint32(0),                     // replaces_id
+		"",                            // app_icon
+		n.Summary,                     // summary
+		markupEscaper.Replace(n.Body), // body
+		actions,                       // actions
+		map[string]dbus.Variant{},     // hints
+		int32(-1),                     // expire_timeout: server default
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(reply) != 1 {
+		return "", fmt.Errorf("unexpected Notify reply %v", reply)
+	}
+	id, ok := reply[0].(uint32)
+	if !ok {
+		return "", fmt.Errorf("unexpected Notify reply %v", reply)
+	}
+	if signals == nil {
+		return "", nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case s, ok := <-signals:
+			if !ok {
+				return "", fmt.Errorf("session bus connection closed")
+			}
+			if s.ID != id {
+				continue
+			}
+			if s.Name == "ActionInvoked" {
+				return s.Action, nil
+			}
+			return "", nil
+		}
+	}
+}
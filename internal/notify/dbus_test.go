@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeBus stands in for the session bus: it records calls, replies with
+// reply and plays signals to subscribers.
+type fakeBus struct {
+	reply   []any
+	callErr error
+	signals []Signal
+	calls   [][]any
+	subs    int
+}
+
+func (b *fakeBus) Call(ctx context.Context, method string, args ...any) ([]any, error) {
+	b.calls = append(b.calls, append([]any{method}, args...))
+	return b.reply, b.callErr
+}
+
+func (b *fakeBus) Signals(ctx context.Context) (<-chan Signal, error) {
+	b.subs++
+	ch := make(chan Signal, len(b.signals))
+	for _, s := range b.signals {
+		ch <- s
+	}
+	return ch, nil
+}
+
+var focusNotification = Notification{
+	Summary: "PERM  ~/git/infra",
+	Body:    "Bash: terraform apply",
+	Actions: []Action{{Key: "focus", Label: "Focus"}},
+}
+
+func TestDBus_Notify_ActionInvoked(t *testing.T) {
+	bus := &fakeBus{
+		reply: []any{uint32(7)},
+		signals: []Signal{
+			{Name: "ActionInvoked", ID: 6, Action: "focus"}, // another notification
+			{Name: "NotificationClosed", ID: 5},
+			{Name: "ActionInvoked", ID: 7, Action: "focus"},
+		},
+	}
+	action, err := (&DBus{Bus: bus}).Notify(context.Background(), focusNotification)
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if action != "focus" {
+		t.Errorf("action = %q, want focus", action)
+	}
+	if bus.subs != 1 {
+		t.Errorf("subscriptions = %d, want 1", bus.subs)
+	}
+
+	if len(bus.calls) != 1 {
+		t.Fatalf("calls = %v, want one", bus.calls)
+	}
+	want := []any{"Notify", "cc-queue", uint32(0), "", "PERM  ~/git/infra", "Bash: terraform apply",
+		[]string{"focus", "Focus"}, map[string]dbus.Variant{}, int32(-1)}
+	if !reflect.DeepEqual(bus.calls[0], want) {
+		t.Errorf("call = %#v\nwant   %#v", bus.calls[0], want)
+	}
+}
+
+func TestDBus_Notify_Closed(t *testing.T) {
+	bus := &fakeBus{
+		reply:   []any{uint32(7)},
+		signals: []Signal{{Name: "NotificationClosed", ID: 7}},
+	}
+	action, err := (&DBus{Bus: bus}).Notify(context.Background(), focusNotification)
+	if err != nil || action != "" {
+		t.Errorf("Notify() = %q, %v; want no action", action, err)
+	}
+}
+
+func TestDBus_Notify_Timeout(t *testing.T) {
+	bus := &fakeBus{reply: []any{uint32(7)}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	action, err := (&DBus{Bus: bus}).Notify(ctx, focusNotification)
+	if !errors.Is(err, context.DeadlineExceeded) || action != "" {
+		t.Errorf("Notify() = %q, %v; want deadline exceeded", action, err)
+	}
+}
+
+func TestDBus_Notify_NoActions(t *testing.T) {
+	bus := &fakeBus{reply: []any{uint32(7)}}
+	n := Notification{Summary: "a <b> & 'c'", Body: "x < y"}
+	if _, err := (&DBus{Bus: bus}).Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if bus.subs != 0 {
+		t.Error("Notify without actions should not subscribe to signals")
+	}
+	args := bus.calls[0]
+	if args[4] != "a <b> & 'c'" {
+		t.Errorf("summary = %v", args[4])
+	}
+	if args[5] != "x &lt; y" {
+		t.Errorf("body = %v, want markup escaped", args[5])
+	}
+	if actions, ok := args[6].([]string); !ok || actions == nil || len(actions) != 0 {
+		t.Errorf("actions = %#v, want an empty array", args[6])
+	}
+}
+
+func TestDBus_Notify_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		bus  *fakeBus
+	}{
+		{"call fails", &fakeBus{callErr: errors.New("no notification daemon")}},
+		{"empty reply", &fakeBus{}},
+		{"unexpected reply", &fakeBus{reply: []any{"7"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&DBus{Bus: tt.bus}).Notify(context.Background(), focusNotification); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal dbus.Signal
+		want   Signal
+		ok     bool
+	}{
+		{
+			name:   "action invoked",
+			signal: dbus.Signal{Path: dbusPath, Name: dbusIface + ".ActionInvoked", Body: []any{uint32(7), "focus"}},
+			want:   Signal{Name: "ActionInvoked", ID: 7, Action: "focus"},
+			ok:     true,
+		},
+		{
+			name:   "closed",
+			signal: dbus.Signal{Path: dbusPath, Name: dbusIface + ".NotificationClosed", Body: []any{uint32(7), uint32(2)}},
+			want:   Signal{Name: "NotificationClosed", ID: 7},
+			ok:     true,
+		},
+		{
+			name:   "other member",
+			signal: dbus.Signal{Path: dbusPath, Name: dbusIface + ".ActivationToken", Body: []any{uint32(7), "token"}},
+		},
+		{
+			name:   "other interface",
+			signal: dbus.Signal{Path: dbusPath, Name: "org.freedesktop.DBus.NameAcquired", Body: []any{uint32(7), "x"}},
+		},
+		{
+			name:   "bad body",
+			signal: dbus.Signal{Path: dbusPath, Name: dbusIface + ".ActionInvoked", Body: []any{"7", "focus"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSignal(&tt.signal)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseSignal() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package notify raises desktop notifications for sessions needing input.
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ActionDefault is the action invoked by clicking the notification itself.
+const ActionDefault = "default"
+
+// Action is a button on a notification.
+type Action struct {
+	Key   string
+	Label string
+}
+
+// Notification is a desktop notification.
+type Notification struct {
+	Summary string
+	Body    string
+	Actions []Action
+}
+
+// Notifier shows desktop notifications.
+type Notifier interface {
+	// Notify shows n and, when it has actions, blocks until the user invokes
+	// one, the notification is closed, or ctx is done. It returns the key of
+	// the invoked action, or "" when none was.
+	Notify(ctx context.Context, n Notification) (string, error)
+}
+
+// pendingFile collects the sessions waiting to be notified about.
+const pendingFile = "notify.pending"
+
+// Enqueue adds a session to the pending notifications in dir.
+func Enqueue(dir, sessionID string) error {
+	f, err := os.OpenFile(filepath.Join(dir, pendingFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(sessionID + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Claim takes the pending notifications in dir, in the order they were
+// enqueued and without duplicates. Of several processes claiming at once,
+// only one gets them; the others get none, so a burst of events turns into
+// a single notification.
+func Claim(dir string) ([]string, error) {
+	claimed := filepath.Join(dir, fmt.Sprintf("%s.%d", pendingFile, os.Getpid()))
+	if err := os.Rename(filepath.Join(dir, pendingFile), claimed); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer os.Remove(claimed)
+
+	f, err := os.Open(claimed)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ids []string
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		id := strings.TrimSpace(sc.Text())
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, sc.Err()
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEnqueueClaim(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"a", "b", "a", "c"} {
+		if err := Enqueue(dir, id); err != nil {
+			t.Fatalf("Enqueue(%s): %v", id, err)
+		}
+	}
+
+	ids, err := Claim(dir)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Claim() = %q, want %q", ids, want)
+	}
+
+	// Nothing is left for the next claim.
+	if ids, err := Claim(dir); err != nil || ids != nil {
+		t.Errorf("second Claim() = %q, %v; want nothing", ids, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("leftover files: %q", files)
+	}
+}
+
+func TestClaim_OnlyOneWins(t *testing.T) {
+	dir := t.TempDir()
+	if err := Enqueue(dir, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var got [][]string
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids, err := Claim(dir)
+			if err != nil {
+				t.Errorf("Claim: %v", err)
+			}
+			if len(ids) > 0 {
+				mu.Lock()
+				got = append(got, ids)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(got) != 1 {
+		t.Errorf("claims with sessions = %q, want exactly one", got)
+	}
+}
+
+func TestClaim_MissingDir(t *testing.T) {
+	ids, err := Claim(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || ids != nil {
+		t.Errorf("Claim() = %q, %v; want nothing", ids, err)
+	}
+}
+
+func TestEnqueue_MissingDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	if err := Enqueue(dir, "a"); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Error("Enqueue should not create the directory")
+	}
+}
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultBranchTTL is how long a looked up git branch is reused.
+const DefaultBranchTTL = 10 * time.Second
+
+// cachedBranch is a looked up branch and when it was looked up.
+type cachedBranch struct {
+	Branch string    `json:"branch"`
+	At     time.Time `json:"at"`
+}
+
+// BranchCache remembers the git branch of directories for TTL, so the
+// picker, which rebuilds its rows every few seconds and on every queue
+// change, does not fork git for every entry every time. It is kept in a
+// file so the short-lived processes fzf reloads the list with share it.
+type BranchCache struct {
+	path string
+	// TTL is how long a looked up branch is reused.
+	TTL time.Duration
+	// Lookup returns the branch of a directory. Defaults to GitBranch.
+	Lookup func(cwd string) string
+
+	mu       sync.Mutex
+	branches map[string]cachedBranch // loaded on first use
+}
+
+// NewBranchCache returns a BranchCache kept in path with the given TTL.
+// An empty path means branches.json in Dir(), resolved on every call.
+func NewBranchCache(path string, ttl time.Duration) *BranchCache {
+	return &BranchCache{path: path, TTL: ttl, Lookup: GitBranch}
+}
+
+// Path returns the cache file path.
+func (c *BranchCache) Path() string {
+	if c.path != "" {
+		return c.path
+	}
+	return filepath.Join(Dir(), "branches.json")
+}
+
+// Get returns the git branch of cwd, or "" if it is not in a git repo.
+// A nil BranchCache looks it up every time.
+func (c *BranchCache) Get(cwd string) string {
+	if c == nil {
+		return GitBranch(cwd)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.branches == nil {
+		c.branches = c.load()
+	}
+	now := time.Now()
+	if b, ok := c.branches[cwd]; ok && now.Sub(b.At) < c.TTL {
+		return b.Branch
+	}
+	branch := c.Lookup(cwd)
+	c.branches[cwd] = cachedBranch{Branch: branch, At: now}
+	c.save(now)
+	return branch
+}
+
+// load reads the cache file. A missing or unreadable file is an empty cache.
+func (c *BranchCache) load() map[string]cachedBranch {
+	branches := make(map[string]cachedBranch)
+	if data, err := os.ReadFile(c.Path()); err == nil {
+		json.Unmarshal(data, &branches)
+	}
+	return branches
+}
+
+// save drops the expired branches and writes the rest to the cache file.
+// Failures only cost a lookup next time, so they are logged and ignored.
+func (c *BranchCache) save(now time.Time) {
+	for cwd, b := range c.branches {
+		if now.Sub(b.At) >= c.TTL {
+			delete(c.branches, cwd)
+		}
+	}
+	data, err := json.Marshal(c.branches)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.Path()), 0755)
+	}
+	if err == nil {
+		err = WriteFileAtomic(c.Path(), data, 0644)
+	}
+	if err != nil {
+		Debugf("BRANCH_CACHE error: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingLookup returns a branch lookup that counts its calls.
+func countingLookup(calls *int) func(string) string {
+	return func(cwd string) string {
+		*calls++
+		return "main"
+	}
+}
+
+func TestBranchCache_ReusesWithinTTL(t *testing.T) {
+	var calls int
+	c := NewBranchCache(filepath.Join(t.TempDir(), "branches.json"), time.Hour)
+	c.Lookup = countingLookup(&calls)
+
+	for range 3 {
+		if got := c.Get("/p"); got != "main" {
+			t.Fatalf("Get = %q, want main", got)
+		}
+	}
+	c.Get("/q")
+	if calls != 2 {
+		t.Errorf("lookups = %d, want one per directory", calls)
+	}
+}
+
+func TestBranchCache_Expires(t *testing.T) {
+	var calls int
+	c := NewBranchCache(filepath.Join(t.TempDir(), "branches.json"), 0)
+	c.Lookup = countingLookup(&calls)
+
+	c.Get("/p")
+	c.Get("/p")
+	if calls != 2 {
+		t.Errorf("lookups = %d, want 2 with a zero TTL", calls)
+	}
+}
+
+func TestBranchCache_SharedThroughFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "branches.json")
+	var calls int
+	first := NewBranchCache(path, time.Hour)
+	first.Lookup = countingLookup(&calls)
+	first.Get("/p")
+
+	second := NewBranchCache(path, time.Hour)
+	second.Lookup = countingLookup(&calls)
+	if got := second.Get("/p"); got != "main" {
+		t.Errorf("Get = %q, want main", got)
+	}
+	if calls != 1 {
+		t.Errorf("lookups = %d, want the second cache to read the first's file", calls)
+	}
+}
+
+func TestBranchCache_NilLooksUp(t *testing.T) {
+	var c *BranchCache
+	if got := c.Get(filepath.Join(t.TempDir(), "nonexistent")); got != "" {
+		t.Errorf("Get = %q, want empty outside a repo", got)
+	}
+}
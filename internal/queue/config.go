@@ -2,13 +2,151 @@ package queue
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Config holds cc-queue configuration.
 type Config struct {
 	Debug bool `json:"debug"`
+	// UI selects the picker: "auto" (default), "fzf" or "native".
+	UI string `json:"ui,omitempty"`
+	// Rules adjust the picker order; see Rule.
+	Rules []Rule `json:"rules,omitempty"`
+	// AutoAdvance moves on to the next waiting session once a prompt is answered.
+	AutoAdvance AutoAdvance `json:"auto_advance,omitzero"`
+	// DND (do not disturb) suppresses notifications and auto-advance, and
+	// hides sessions outside DNDAllow from first and the picker.
+	DND bool `json:"dnd,omitempty"`
+	// DNDUntil ends a timed do-not-disturb.
+	DNDUntil time.Time `json:"dnd_until,omitzero"`
+	// DNDAllow lists cwd globs (~ and ** allowed) whose sessions stay
+	// visible during do-not-disturb. Empty hides nothing.
+	DNDAllow []string `json:"dnd_allow,omitempty"`
+	// Notify raises desktop notifications when sessions need input.
+	Notify Notify `json:"notify,omitzero"`
+	// Webhooks are HTTP endpoints told when sessions need input.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Reminders re-notify about sessions that keep waiting; see Reminder.
+	Reminders []Reminder `json:"reminders,omitempty"`
+}
+
+// DefaultAdvanceDelay is the auto-advance delay when none is configured.
+const DefaultAdvanceDelay = 2 * time.Second
+
+// AutoAdvance configures focusing the next session needing attention after
+// answering one.
+type AutoAdvance struct {
+	Enabled bool `json:"enabled"`
+	// Delay is how long to wait before moving on (e.g. "3s"), so a quick
+	// follow-up in the same session is not interrupted.
+	Delay string `json:"delay,omitempty"`
+}
+
+// DelayDuration returns the configured delay, or DefaultAdvanceDelay when
+// it is empty or invalid.
+func (a AutoAdvance) DelayDuration() time.Duration {
+	if d, err := ParseDuration(a.Delay); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultAdvanceDelay
+}
+
+// DefaultNotifyCoalesce is how long notifications wait for more events
+// when no coalescing window is configured.
+const DefaultNotifyCoalesce = 2 * time.Second
+
+// DefaultNotifyEvents are the events notified about when Notify.Events is empty.
+var DefaultNotifyEvents = []string{"PERM", "ASK", "IDLE"}
+
+// Notify configures desktop notifications.
+type Notify struct {
+	Enabled bool `json:"enabled"`
+	// Events lists the event types or labels (PERM, ASK, IDLE, DONE) to
+	// notify about; empty means DefaultNotifyEvents.
+	Events []string `json:"events,omitempty"`
+	// Coalesce is how long to wait for more events (e.g. "2s"), so a burst
+	// shows as a single notification.
+	Coalesce string `json:"coalesce,omitempty"`
+}
+
+// Wants reports whether n is enabled for event.
+func (n Notify) Wants(event string) bool {
+	return n.Enabled && wantsEvent(n.Events, event)
+}
+
+// wantsEvent reports whether events, or DefaultNotifyEvents when it is
+// empty, lists event by type or label.
+func wantsEvent(events []string, event string) bool {
+	if len(events) == 0 {
+		events = DefaultNotifyEvents
+	}
+	for _, ev := range events {
+		if matchEvent(ev, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// CoalesceDuration returns the configured coalescing window, or
+// DefaultNotifyCoalesce when it is empty or invalid.
+func (n Notify) CoalesceDuration() time.Duration {
+	if d, err := ParseDuration(n.Coalesce); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultNotifyCoalesce
+}
+
+// Webhook is an HTTP endpoint told when a session needs input, such as
+// ntfy, a Slack-compatible incoming webhook or a bot.
+type Webhook struct {
+	// Name identifies the webhook in logs.
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+	// Method defaults to POST.
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is a text/template rendered with the Entry; empty sends a
+	// one-line summary.
+	Body string `json:"body,omitempty"`
+	// Events lists the event types or labels to send; empty means
+	// DefaultNotifyEvents.
+	Events []string `json:"events,omitempty"`
+}
+
+// Wants reports whether w is sent for event.
+func (w Webhook) Wants(event string) bool {
+	return wantsEvent(w.Events, event)
+}
+
+// DNDActive reports whether do-not-disturb is on at now.
+func (c Config) DNDActive(now time.Time) bool {
+	return c.DND && (c.DNDUntil.IsZero() || now.Before(c.DNDUntil))
+}
+
+// DNDHides reports whether do-not-disturb hides a session in cwd at now.
+func (c Config) DNDHides(cwd string, now time.Time) bool {
+	if !c.DNDActive(now) || len(c.DNDAllow) == 0 {
+		return false
+	}
+	for _, glob := range c.DNDAllow {
+		if MatchGlob(expandTilde(glob), cwd) {
+			return false
+		}
+	}
+	return true
+}
+
+// DNDMutes reports whether do-not-disturb silences notifications for a
+// session in cwd at now: all of them without an allowlist, and those outside
+// it with one.
+func (c Config) DNDMutes(cwd string, now time.Time) bool {
+	return c.DNDActive(now) && (len(c.DNDAllow) == 0 || c.DNDHides(cwd, now))
 }
 
 // ConfigDir returns the configuration directory for cc-queue.
@@ -29,18 +167,34 @@ func ConfigPath() string {
 // ReadConfig reads the configuration from disk.
 // Returns a zero Config if the file doesn't exist or can't be parsed.
 func ReadConfig() Config {
-	data, err := os.ReadFile(ConfigPath())
+	cfg, err := LoadConfig()
 	if err != nil {
 		return Config{}
 	}
+	return cfg
+}
+
+// LoadConfig reads the configuration from disk for updating it. A missing
+// file is a zero Config; a file that can't be parsed is an error, so it is
+// not overwritten.
+func LoadConfig() (Config, error) {
 	var cfg Config
+	data, err := os.ReadFile(ConfigPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return Config{}
+		return cfg, fmt.Errorf("%s: %w", ConfigPath(), err)
 	}
-	return cfg
+	return cfg, nil
 }
 
-// WriteConfig writes the configuration to disk, creating the directory if needed.
+// WriteConfig writes the configuration to disk, creating the directory if
+// needed. The file is replaced atomically: hooks reading it concurrently
+// would take a truncated file for a zero Config.
 func WriteConfig(cfg Config) error {
 	dir := ConfigDir()
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -51,7 +205,34 @@ func WriteConfig(cfg Config) error {
 		return err
 	}
 	data = append(data, '\n')
-	return os.WriteFile(ConfigPath(), data, 0644)
+	return WriteFileAtomic(ConfigPath(), data, 0644)
+}
+
+// UpdateConfig applies fn to the configuration on disk and writes it back,
+// holding a lock so concurrent updates cannot lose each other's changes.
+// Nothing is written if fn returns an error.
+func UpdateConfig(fn func(cfg *Config) error) (Config, error) {
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		return Config{}, err
+	}
+	lf, err := os.OpenFile(ConfigPath()+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return Config{}, err
+	}
+	defer lf.Close()
+	if err := DefaultLocker.Lock(int(lf.Fd())); err != nil {
+		return Config{}, err
+	}
+	defer DefaultLocker.Unlock(int(lf.Fd()))
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		return cfg, err
+	}
+	if err := fn(&cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, WriteConfig(cfg)
 }
 
 // DefaultConfigJSON returns the pretty-printed JSON of a zero-value Config.
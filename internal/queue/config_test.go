@@ -1,9 +1,13 @@
 package queue
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestConfigDir_XDGConfigHome(t *testing.T) {
@@ -64,6 +68,63 @@ func TestWriteAndReadConfig(t *testing.T) {
 	}
 }
 
+func TestUpdateConfig_Concurrent(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := UpdateConfig(func(cfg *Config) error {
+				cfg.DNDAllow = append(cfg.DNDAllow, fmt.Sprintf("/p%d", i))
+				return nil
+			})
+			if err != nil {
+				t.Errorf("UpdateConfig: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := ReadConfig().DNDAllow; len(got) != 20 {
+		t.Errorf("DNDAllow has %d entries, want all 20 updates", len(got))
+	}
+}
+
+func TestUpdateConfig_ErrorWritesNothing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := WriteConfig(Config{Debug: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := UpdateConfig(func(cfg *Config) error {
+		cfg.Debug = false
+		return errors.New("bad input")
+	})
+	if err == nil {
+		t.Fatal("expected fn's error, got nil")
+	}
+	if !ReadConfig().Debug {
+		t.Error("config written despite the error")
+	}
+}
+
+func TestUpdateConfig_InvalidJSON(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	path := filepath.Join(tmp, "cc-queue", "config.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("{invalid"), 0644)
+
+	if _, err := UpdateConfig(func(cfg *Config) error { return nil }); err == nil {
+		t.Fatal("expected error for invalid JSON, got nil")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{invalid" {
+		t.Errorf("invalid config overwritten with %q", data)
+	}
+}
+
 func TestWriteConfig_CreatesDirectory(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", tmp)
@@ -98,3 +159,159 @@ func TestWriteConfig_ToggleOff(t *testing.T) {
 		t.Error("expected Debug=false after toggling off")
 	}
 }
+
+func TestAutoAdvance_DelayDuration(t *testing.T) {
+	for delay, want := range map[string]time.Duration{
+		"":     DefaultAdvanceDelay,
+		"5s":   5 * time.Second,
+		"0s":   0,
+		"-1s":  DefaultAdvanceDelay,
+		"soon": DefaultAdvanceDelay,
+	} {
+		if got := (AutoAdvance{Delay: delay}).DelayDuration(); got != want {
+			t.Errorf("DelayDuration(%q) = %v, want %v", delay, got, want)
+		}
+	}
+}
+
+func TestConfig_DNDActive(t *testing.T) {
+	now := time.Date(2026, 2, 18, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{"off", Config{}, false},
+		{"on", Config{DND: true}, true},
+		{"until later", Config{DND: true, DNDUntil: now.Add(time.Minute)}, true},
+		{"expired", Config{DND: true, DNDUntil: now.Add(-time.Minute)}, false},
+		{"until without on", Config{DNDUntil: now.Add(time.Minute)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.DNDActive(now); got != tt.want {
+				t.Errorf("DNDActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_DNDHides(t *testing.T) {
+	now := time.Date(2026, 2, 18, 14, 30, 0, 0, time.UTC)
+	home, _ := os.UserHomeDir()
+	cfg := Config{DND: true, DNDAllow: []string{"~/git/infra/**", "/srv/*"}}
+	tests := []struct {
+		cwd  string
+		want bool
+	}{
+		{home + "/git/infra", false},
+		{home + "/git/infra/modules/gke", false},
+		{"/srv/api", false},
+		{"/srv/api/sub", true},
+		{home + "/git/cc-queue", true},
+	}
+	for _, tt := range tests {
+		if got := cfg.DNDHides(tt.cwd, now); got != tt.want {
+			t.Errorf("DNDHides(%q) = %v, want %v", tt.cwd, got, tt.want)
+		}
+	}
+
+	if (Config{DND: true}).DNDHides("/anywhere", now) {
+		t.Error("DND without an allowlist should hide nothing")
+	}
+	if (Config{DNDAllow: cfg.DNDAllow}).DNDHides(home+"/git/cc-queue", now) {
+		t.Error("an allowlist should hide nothing while DND is off")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() without a file: %v", err)
+	}
+	if cfg.DND || len(cfg.Rules) != 0 {
+		t.Errorf("LoadConfig() without a file = %+v, want zero", cfg)
+	}
+
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ConfigPath(), []byte("{oops"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(); err == nil {
+		t.Error("LoadConfig() with invalid JSON should fail")
+	}
+}
+
+func TestNotify_Wants(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     Notify
+		event string
+		want  bool
+	}{
+		{"disabled", Notify{}, "permission_prompt", false},
+		{"default PERM", Notify{Enabled: true}, "permission_prompt", true},
+		{"default ASK", Notify{Enabled: true}, "elicitation_dialog", true},
+		{"default IDLE", Notify{Enabled: true}, "idle_prompt", true},
+		{"default DONE", Notify{Enabled: true}, "Stop", false},
+		{"default working", Notify{Enabled: true}, "working", false},
+		{"by label", Notify{Enabled: true, Events: []string{"done"}}, "Stop", true},
+		{"by type", Notify{Enabled: true, Events: []string{"permission_prompt"}}, "permission_prompt", true},
+		{"not listed", Notify{Enabled: true, Events: []string{"PERM"}}, "idle_prompt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.Wants(tt.event); got != tt.want {
+				t.Errorf("Wants(%q) = %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotify_CoalesceDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    DefaultNotifyCoalesce,
+		"bad": DefaultNotifyCoalesce,
+		"0s":  0,
+		"5s":  5 * time.Second,
+	}
+	for in, want := range tests {
+		if got := (Notify{Coalesce: in}).CoalesceDuration(); got != want {
+			t.Errorf("CoalesceDuration(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestConfig_DNDMutes(t *testing.T) {
+	now := time.Date(2026, 2, 18, 14, 30, 0, 0, time.UTC)
+	if (Config{}).DNDMutes("/srv/a", now) {
+		t.Error("DND off should mute nothing")
+	}
+	if !(Config{DND: true}).DNDMutes("/srv/a", now) {
+		t.Error("DND without an allowlist should mute everything")
+	}
+	cfg := Config{DND: true, DNDAllow: []string{"/srv/a/**"}}
+	if cfg.DNDMutes("/srv/a", now) {
+		t.Error("DND should not mute allowlisted sessions")
+	}
+	if !cfg.DNDMutes("/srv/b", now) {
+		t.Error("DND should mute sessions outside the allowlist")
+	}
+}
+
+func TestWebhook_Wants(t *testing.T) {
+	if !(Webhook{}).Wants("idle_prompt") {
+		t.Error("a webhook without events should want IDLE")
+	}
+	if (Webhook{}).Wants("working") {
+		t.Error("a webhook without events should not want WORK")
+	}
+	w := Webhook{Events: []string{"PERM", "Stop"}}
+	if !w.Wants("permission_prompt") || !w.Wants("Stop") || w.Wants("idle_prompt") {
+		t.Errorf("Wants() does not follow Events %q", w.Events)
+	}
+}
@@ -3,7 +3,6 @@ package queue
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -14,14 +13,64 @@ import (
 
 // Entry represents a pending Claude Code input request.
 type Entry struct {
-	Timestamp     time.Time `json:"timestamp"`
-	SessionID     string    `json:"session_id"`
-	KittyWindowID string    `json:"kitty_window_id"`
-	KittyListenOn string    `json:"kitty_listen_on,omitempty"`
-	PID           int       `json:"pid"`
-	CWD           string    `json:"cwd"`
-	Event         string    `json:"event"`
-	Message       string    `json:"message,omitempty"`
+	// Timestamp orders the queue; jumping to or away from the session
+	// touches it.
+	Timestamp time.Time `json:"timestamp"`
+	// PushedAt is when the session entered its event. Touches leave it be.
+	PushedAt  time.Time `json:"pushed_at,omitzero"`
+	SessionID string    `json:"session_id"`
+	// Terminal is the backend the session runs in (kitty, tmux); empty
+	// means kitty, for entries written before other terminals.
+	Terminal string `json:"terminal,omitempty"`
+	// WindowID is the session's window or pane in its terminal. The JSON
+	// names predate other terminals and are kept for existing entries.
+	WindowID string `json:"kitty_window_id"`
+	// ListenOn is the address of the terminal's control socket.
+	ListenOn string `json:"kitty_listen_on,omitempty"`
+	// TTY is the session's terminal device (/dev/pts/4) when it runs
+	// outside any known terminal, so it has no window to jump to.
+	TTY     string `json:"tty,omitempty"`
+	PID     int    `json:"pid"`
+	CWD     string `json:"cwd"`
+	Event   string `json:"event"`
+	Message string `json:"message,omitempty"`
+	// TranscriptPath is the session's conversation JSONL, as reported by hooks.
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	// PermissionMode is the session's permission mode at the time of the event.
+	PermissionMode string `json:"permission_mode,omitempty"`
+	// Source is how the session started (startup, resume, clear, compact).
+	// It is carried over from the previous entry when not set.
+	Source string `json:"source,omitempty"`
+	// EndReason is why the session ended (clear, logout, ...).
+	EndReason string `json:"end_reason,omitempty"`
+	// Tool is the last tool call Claude Code was about to make, recorded by
+	// the PreToolUse hook. For permission_prompt it is the call awaiting approval.
+	Tool *ToolCall `json:"tool,omitempty"`
+	// Read marks the entry as seen in the picker. Read entries sort after
+	// unread ones; the session's next event clears the mark.
+	Read bool `json:"read,omitempty"`
+	// Snoozed hides the entry from list, first and the picker until the
+	// session's next event, or until SnoozeUntil when it is set.
+	Snoozed bool `json:"snoozed,omitempty"`
+	// SnoozeUntil ends a timed snooze.
+	SnoozeUntil time.Time `json:"snooze_until,omitzero"`
+	// Priority is pinned, high or low; empty means normal. It is set by the
+	// user and carried over from the previous entry.
+	Priority string `json:"priority,omitempty"`
+}
+
+// WaitingSince returns when the session entered its event: PushedAt, or
+// Timestamp for entries written before it was recorded.
+func (e *Entry) WaitingSince() time.Time {
+	if e.PushedAt.IsZero() {
+		return e.Timestamp
+	}
+	return e.PushedAt
+}
+
+// IsSnoozed reports whether the entry is hidden by a snooze at now.
+func (e *Entry) IsSnoozed(now time.Time) bool {
+	return e.Snoozed && (e.SnoozeUntil.IsZero() || now.Before(e.SnoozeUntil))
 }
 
 // SessionFile wraps the current entry with a capped history of previous entries.
@@ -44,7 +93,7 @@ type flockLocker struct{}
 func (flockLocker) Lock(fd int) error   { return syscall.Flock(fd, syscall.LOCK_EX) }
 func (flockLocker) Unlock(fd int) error { return syscall.Flock(fd, syscall.LOCK_UN) }
 
-// DefaultLocker is the file locker used by Write. Replace in tests.
+// DefaultLocker is the file locker used by FileStore. Replace in tests.
 var DefaultLocker Locker = flockLocker{}
 
 // Dir returns the queue storage directory.
@@ -61,129 +110,6 @@ func EnsureDir() error {
 	return os.MkdirAll(Dir(), 0755)
 }
 
-// entryPath returns the file path for a given session ID.
-func entryPath(sessionID string) string {
-	safe := strings.NewReplacer("/", "_", "\\", "_").Replace(sessionID)
-	return filepath.Join(Dir(), safe+".json")
-}
-
-// Write persists an entry to disk, keyed by session ID.
-// It locks the file, reads the existing session, pushes the old current entry
-// into history (deduplicating consecutive same-event entries), and writes back.
-func Write(e *Entry) error {
-	if err := EnsureDir(); err != nil {
-		return err
-	}
-
-	path := entryPath(e.SessionID)
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	if err := DefaultLocker.Lock(int(f.Fd())); err != nil {
-		return err
-	}
-	defer DefaultLocker.Unlock(int(f.Fd()))
-
-	var sf SessionFile
-	if data, err := io.ReadAll(f); err == nil && len(data) > 0 {
-		sf, _ = parseSessionFile(data)
-	}
-
-	// Push current to history, skipping if both event and message are identical.
-	if sf.Current != nil && (sf.Current.Event != e.Event || sf.Current.Message != e.Message) {
-		sf.History = append([]*Entry{sf.Current}, sf.History...)
-		if len(sf.History) > MaxHistory {
-			sf.History = sf.History[:MaxHistory]
-		}
-	}
-	sf.Current = e
-
-	out, err := json.MarshalIndent(sf, "", "  ")
-	if err != nil {
-		return err
-	}
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
-	if err := f.Truncate(0); err != nil {
-		return err
-	}
-	if _, err := f.Write(out); err != nil {
-		return err
-	}
-
-	Debugf("WRITE session=%s event=%s cwd=%s pid=%d wid=%s", e.SessionID, e.Event, e.CWD, e.PID, e.KittyWindowID)
-	return nil
-}
-
-// Touch atomically updates the timestamp of an existing session entry
-// without modifying event, message, or history.
-func Touch(sessionID string, now time.Time) error {
-	path := entryPath(sessionID)
-	f, err := os.OpenFile(path, os.O_RDWR, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	if err := DefaultLocker.Lock(int(f.Fd())); err != nil {
-		return err
-	}
-	defer DefaultLocker.Unlock(int(f.Fd()))
-
-	data, err := io.ReadAll(f)
-	if err != nil || len(data) == 0 {
-		return err
-	}
-	sf, err := parseSessionFile(data)
-	if err != nil || sf.Current == nil {
-		return err
-	}
-
-	sf.Current.Timestamp = now
-
-	out, err := json.MarshalIndent(sf, "", "  ")
-	if err != nil {
-		return err
-	}
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
-	if err := f.Truncate(0); err != nil {
-		return err
-	}
-	if _, err := f.Write(out); err != nil {
-		return err
-	}
-
-	Debugf("TOUCH session=%s timestamp=%s", sessionID, now.Format(time.RFC3339))
-	return nil
-}
-
-// TouchByWindowID updates the timestamp of entries matching the given kitty
-// window ID. This deprioritizes the current session when jumping to another.
-// It is a no-op if wid is empty or no matching entry is found.
-func TouchByWindowID(wid string, now time.Time) error {
-	if wid == "" {
-		return nil
-	}
-	entries, err := List()
-	if err != nil {
-		return err
-	}
-	for _, e := range entries {
-		if e.KittyWindowID == wid {
-			if err := Touch(e.SessionID, now); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
-}
-
 // Read loads the current entry from a session file.
 // Handles both new SessionFile format and legacy bare-entry format.
 func Read(fpath string) (*Entry, error) {
@@ -208,11 +134,6 @@ func ReadSession(fpath string) (*SessionFile, error) {
 	return &sf, nil
 }
 
-// ReadSessionByID loads a full SessionFile by session ID.
-func ReadSessionByID(sessionID string) (*SessionFile, error) {
-	return ReadSession(entryPath(sessionID))
-}
-
 // parseSessionFile unmarshals data into a SessionFile, handling backward
 // compatibility with legacy bare-entry JSON files.
 func parseSessionFile(data []byte) (SessionFile, error) {
@@ -234,72 +155,68 @@ func parseSessionFile(data []byte) (SessionFile, error) {
 	return sf, nil
 }
 
-// List returns all entries in the queue directory.
-// When multiple entries share the same (kitty_window_id, cwd) tuple,
-// only the most recent one is kept and the older duplicates are removed from disk.
-// Entries with an empty kitty_window_id are never deduplicated.
-func List() ([]*Entry, error) {
-	files, err := filepath.Glob(filepath.Join(Dir(), "*.json"))
-	if err != nil {
-		return nil, err
+// pushCurrent makes e the current entry of sf, moving the previous current
+// entry into history (deduplicating consecutive same-event entries).
+// Session-level fields that e leaves unset are carried over.
+func pushCurrent(sf *SessionFile, e *Entry) {
+	if e.PushedAt.IsZero() {
+		e.PushedAt = e.Timestamp
 	}
-	var entries []*Entry
-	for _, f := range files {
-		e, err := Read(f)
-		if err != nil {
-			continue
+	if sf.Current != nil && e.Source == "" {
+		e.Source = sf.Current.Source
+	}
+	if sf.Current != nil && e.Priority == "" {
+		e.Priority = sf.Current.Priority
+	}
+	// A permission prompt is about the tool call recorded just before it.
+	if sf.Current != nil && e.Tool == nil && e.Event == "permission_prompt" {
+		e.Tool = sf.Current.Tool
+	}
+	// Push current to history, skipping if both event and message are identical.
+	if sf.Current != nil && (sf.Current.Event != e.Event || sf.Current.Message != e.Message) {
+		sf.History = append([]*Entry{sf.Current}, sf.History...)
+		if len(sf.History) > MaxHistory {
+			sf.History = sf.History[:MaxHistory]
 		}
-		entries = append(entries, e)
 	}
+	sf.Current = e
+}
+
+// duplicate pairs an older entry with the entry that supersedes it.
+type duplicate struct {
+	stale, winner *Entry
+}
 
-	// Deduplicate by (kitty_window_id, cwd). Entries with empty window ID are kept as-is.
-	type dedupKey struct{ wid, cwd string }
+// dedupEntries splits entries into those to keep and older duplicates.
+// When multiple entries share the same (terminal, window, cwd) tuple, only
+// the most recent one is kept. Entries without a window are never
+// deduplicated.
+func dedupEntries(entries []*Entry) (kept []*Entry, dupes []duplicate) {
+	type dedupKey struct{ term, wid, cwd string }
 	best := make(map[dedupKey]*Entry)
 	for _, e := range entries {
-		if e.KittyWindowID == "" {
+		if e.WindowID == "" {
 			continue
 		}
-		k := dedupKey{e.KittyWindowID, e.CWD}
+		k := dedupKey{e.Terminal, e.WindowID, e.CWD}
 		if prev, ok := best[k]; !ok || e.Timestamp.After(prev.Timestamp) {
 			best[k] = e
 		}
 	}
 
-	// Build the final list, removing older duplicates from disk.
-	var result []*Entry
 	for _, e := range entries {
-		if e.KittyWindowID == "" {
-			result = append(result, e)
+		if e.WindowID == "" {
+			kept = append(kept, e)
 			continue
 		}
-		k := dedupKey{e.KittyWindowID, e.CWD}
+		k := dedupKey{e.Terminal, e.WindowID, e.CWD}
 		if best[k] == e {
-			result = append(result, e)
+			kept = append(kept, e)
 		} else {
-			Debugf("DEDUP removing session=%s (superseded by session=%s for wid=%s cwd=%s)",
-				e.SessionID, best[k].SessionID, e.KittyWindowID, e.CWD)
-			Remove(e.SessionID)
+			dupes = append(dupes, duplicate{stale: e, winner: best[k]})
 		}
 	}
-	return result, nil
-}
-
-// Remove deletes the entry for a given session ID.
-func Remove(sessionID string) error {
-	Debugf("REMOVE session=%s", sessionID)
-	return os.Remove(entryPath(sessionID))
-}
-
-// RemoveAll deletes every entry in the queue.
-func RemoveAll() error {
-	files, err := filepath.Glob(filepath.Join(Dir(), "*.json"))
-	if err != nil {
-		return err
-	}
-	for _, f := range files {
-		os.Remove(f)
-	}
-	return nil
+	return kept, dupes
 }
 
 // AncestorPID returns the grandparent PID of the current process.
@@ -337,6 +254,16 @@ func readPPID(pid int) (int, error) {
 	return strconv.Atoi(fields[1])
 }
 
+// ProcessTTY returns the terminal device on the standard input of pid, from
+// /proc/<pid>/fd/0, or "" when it is not a terminal (nohup, a pipe).
+func ProcessTTY(pid int) string {
+	dev, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/0", pid))
+	if err != nil || !(strings.HasPrefix(dev, "/dev/pts/") || strings.HasPrefix(dev, "/dev/tty")) {
+		return ""
+	}
+	return dev
+}
+
 // IsProcessAlive checks whether a PID still exists.
 func IsProcessAlive(pid int) bool {
 	if pid <= 0 {
@@ -344,47 +271,3 @@ func IsProcessAlive(pid int) bool {
 	}
 	return syscall.Kill(pid, 0) == nil
 }
-
-// CleanStale removes entries whose PID is no longer running.
-// Returns the number of entries removed.
-func CleanStale() (int, error) {
-	entries, err := List()
-	if err != nil {
-		return 0, err
-	}
-	Debugf("CLEAN_STALE found %d entries", len(entries))
-	removed := 0
-	for _, e := range entries {
-		alive := IsProcessAlive(e.PID)
-		Debugf("CLEAN_STALE session=%s pid=%d alive=%v", e.SessionID, e.PID, alive)
-		if !alive {
-			if err := Remove(e.SessionID); err == nil {
-				removed++
-			}
-		}
-	}
-	return removed, nil
-}
-
-// CleanStaleWindows removes entries whose KittyWindowID is not in the valid set.
-// Entries with an empty KittyWindowID are skipped.
-// Returns the number of entries removed.
-func CleanStaleWindows(validWindowIDs map[string]bool) (int, error) {
-	entries, err := List()
-	if err != nil {
-		return 0, err
-	}
-	removed := 0
-	for _, e := range entries {
-		if e.KittyWindowID == "" {
-			continue
-		}
-		if !validWindowIDs[e.KittyWindowID] {
-			Debugf("CLEAN_STALE_WINDOW session=%s wid=%s", e.SessionID, e.KittyWindowID)
-			if err := Remove(e.SessionID); err == nil {
-				removed++
-			}
-		}
-	}
-	return removed, nil
-}
@@ -3,6 +3,7 @@ package queue
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -12,17 +13,18 @@ import (
 func TestWriteAndRead(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	entry := &Entry{
-		Timestamp:     time.Date(2026, 2, 18, 14, 30, 0, 0, time.UTC),
-		SessionID:     "test-session-1",
-		KittyWindowID: "42",
-		PID:           os.Getpid(),
-		CWD:           "/home/fred/git/project",
-		Event:         "permission_prompt",
+		Timestamp: time.Date(2026, 2, 18, 14, 30, 0, 0, time.UTC),
+		SessionID: "test-session-1",
+		WindowID:  "42",
+		PID:       os.Getpid(),
+		CWD:       "/home/fred/git/project",
+		Event:     "permission_prompt",
 	}
 
-	if err := Write(entry); err != nil {
+	if err := s.Write(entry); err != nil {
 		t.Fatalf("Write: %v", err)
 	}
 
@@ -35,8 +37,8 @@ func TestWriteAndRead(t *testing.T) {
 	if got.SessionID != entry.SessionID {
 		t.Errorf("SessionID = %q, want %q", got.SessionID, entry.SessionID)
 	}
-	if got.KittyWindowID != entry.KittyWindowID {
-		t.Errorf("KittyWindowID = %q, want %q", got.KittyWindowID, entry.KittyWindowID)
+	if got.WindowID != entry.WindowID {
+		t.Errorf("WindowID = %q, want %q", got.WindowID, entry.WindowID)
 	}
 	if got.Event != entry.Event {
 		t.Errorf("Event = %q, want %q", got.Event, entry.Event)
@@ -46,6 +48,7 @@ func TestWriteAndRead(t *testing.T) {
 func TestWriteOverwritesSameSession(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	e1 := &Entry{
 		Timestamp: time.Now(),
@@ -58,10 +61,10 @@ func TestWriteOverwritesSameSession(t *testing.T) {
 		Event:     "idle_prompt",
 	}
 
-	Write(e1)
-	Write(e2)
+	s.Write(e1)
+	s.Write(e2)
 
-	entries, err := List()
+	entries, err := s.List()
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -76,8 +79,9 @@ func TestWriteOverwritesSameSession(t *testing.T) {
 func TestListEmpty(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	entries, err := List()
+	entries, err := s.List()
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -89,14 +93,15 @@ func TestListEmpty(t *testing.T) {
 func TestRemove(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "sess-rm", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "sess-rm", Timestamp: time.Now()})
 
-	if err := Remove("sess-rm"); err != nil {
+	if err := s.Remove("sess-rm"); err != nil {
 		t.Fatalf("Remove: %v", err)
 	}
 
-	entries, _ := List()
+	entries, _ := s.List()
 	if len(entries) != 0 {
 		t.Errorf("got %d entries after remove, want 0", len(entries))
 	}
@@ -105,14 +110,15 @@ func TestRemove(t *testing.T) {
 func TestRemoveAll(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "a", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "b", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "c", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "a", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "b", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "c", Timestamp: time.Now()})
 
-	RemoveAll()
+	s.RemoveAll()
 
-	entries, _ := List()
+	entries, _ := s.List()
 	if len(entries) != 0 {
 		t.Errorf("got %d entries after RemoveAll, want 0", len(entries))
 	}
@@ -134,13 +140,14 @@ func TestIsProcessAlive(t *testing.T) {
 func TestCleanStale(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	// Entry with our own PID (alive).
-	Write(&Entry{SessionID: "alive", PID: os.Getpid(), Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "alive", PID: os.Getpid(), Timestamp: time.Now()})
 	// Entry with a certainly-dead PID.
-	Write(&Entry{SessionID: "dead", PID: 999999999, Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "dead", PID: 999999999, Timestamp: time.Now()})
 
-	removed, err := CleanStale()
+	removed, err := CleanStale(s, nil)
 	if err != nil {
 		t.Fatalf("CleanStale: %v", err)
 	}
@@ -148,7 +155,7 @@ func TestCleanStale(t *testing.T) {
 		t.Errorf("removed = %d, want 1", removed)
 	}
 
-	entries, _ := List()
+	entries, _ := s.List()
 	if len(entries) != 1 {
 		t.Fatalf("got %d entries, want 1", len(entries))
 	}
@@ -184,16 +191,31 @@ func TestAncestorPID_ReturnsGrandparent(t *testing.T) {
 	}
 }
 
+func TestProcessTTY_NotATerminal(t *testing.T) {
+	if got := ProcessTTY(-1); got != "" {
+		t.Errorf("ProcessTTY(-1) = %q, want empty", got)
+	}
+	c := exec.Command("sleep", "5") // stdin is /dev/null, as under nohup
+	if err := c.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer c.Process.Kill()
+	if got := ProcessTTY(c.Process.Pid); got != "" {
+		t.Errorf("ProcessTTY = %q, want empty for /dev/null", got)
+	}
+}
+
 func TestCleanStaleWindows(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "valid", KittyWindowID: "10", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "stale", KittyWindowID: "99", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "no-wid", KittyWindowID: "", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "valid", WindowID: "10", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "stale", WindowID: "99", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "no-wid", WindowID: "", Timestamp: time.Now()})
 
 	validIDs := map[string]bool{"10": true, "20": true}
-	removed, err := CleanStaleWindows(validIDs)
+	removed, err := CleanStaleWindows(s, nil, func(e *Entry) bool { return validIDs[e.WindowID] })
 	if err != nil {
 		t.Fatalf("CleanStaleWindows: %v", err)
 	}
@@ -201,7 +223,7 @@ func TestCleanStaleWindows(t *testing.T) {
 		t.Errorf("removed = %d, want 1", removed)
 	}
 
-	entries, _ := List()
+	entries, _ := s.List()
 	if len(entries) != 2 {
 		t.Fatalf("got %d entries, want 2", len(entries))
 	}
@@ -224,11 +246,12 @@ func TestCleanStaleWindows(t *testing.T) {
 func TestCleanStaleWindows_EmptyValidSet(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "s1", KittyWindowID: "10", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s2", KittyWindowID: "20", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", WindowID: "10", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s2", WindowID: "20", Timestamp: time.Now()})
 
-	removed, err := CleanStaleWindows(map[string]bool{})
+	removed, err := CleanStaleWindows(s, nil, func(e *Entry) bool { return false })
 	if err != nil {
 		t.Fatalf("CleanStaleWindows: %v", err)
 	}
@@ -240,8 +263,9 @@ func TestCleanStaleWindows_EmptyValidSet(t *testing.T) {
 func TestSessionIDSanitization(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "a/b\\c", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "a/b\\c", Timestamp: time.Now()})
 
 	// The file should exist with sanitized name.
 	fpath := filepath.Join(Dir(), "a_b_c.json")
@@ -253,14 +277,15 @@ func TestSessionIDSanitization(t *testing.T) {
 func TestWritePreservesHistory(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()})
 
-	sf, err := ReadSessionByID("s1")
+	sf, err := s.ReadSession("s1")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if sf.Current.Event != "idle_prompt" {
 		t.Errorf("Current.Event = %q, want %q", sf.Current.Event, "idle_prompt")
@@ -276,18 +301,42 @@ func TestWritePreservesHistory(t *testing.T) {
 	}
 }
 
+func TestWriteCarriesOverSource(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
+
+	s.Write(&Entry{SessionID: "s1", Event: "SessionStart", Source: "resume", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+
+	sf, err := s.ReadSession("s1")
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	if sf.Current.Source != "resume" {
+		t.Errorf("Current.Source = %q, want %q", sf.Current.Source, "resume")
+	}
+
+	s.Write(&Entry{SessionID: "s1", Event: "SessionStart", Source: "compact", Timestamp: time.Now()})
+	sf, _ = s.ReadSession("s1")
+	if sf.Current.Source != "compact" {
+		t.Errorf("Current.Source = %q, want %q", sf.Current.Source, "compact")
+	}
+}
+
 func TestWriteDedupsIdenticalEventAndMessage(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
 	// Second "working" with same (empty) message should be deduped.
-	Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
 
-	sf, err := ReadSessionByID("s1")
+	sf, err := s.ReadSession("s1")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if sf.Current.Event != "working" {
 		t.Errorf("Current.Event = %q, want %q", sf.Current.Event, "working")
@@ -303,14 +352,15 @@ func TestWriteDedupsIdenticalEventAndMessage(t *testing.T) {
 func TestWriteKeepsHistoryForSameEventDifferentMessage(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow read?", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow write?", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow bash?", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow read?", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow write?", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow bash?", Timestamp: time.Now()})
 
-	sf, err := ReadSessionByID("s1")
+	sf, err := s.ReadSession("s1")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if sf.Current.Message != "Allow bash?" {
 		t.Errorf("Current.Message = %q, want %q", sf.Current.Message, "Allow bash?")
@@ -330,11 +380,12 @@ func TestWriteKeepsHistoryForSameEventDifferentMessage(t *testing.T) {
 func TestWriteRespectsMaxHistory(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	// Write MaxHistory+2 entries with alternating events to avoid dedup.
 	events := []string{"permission_prompt", "working"}
 	for i := 0; i < MaxHistory+2; i++ {
-		Write(&Entry{
+		s.Write(&Entry{
 			SessionID: "s1",
 			Event:     events[i%2],
 			Timestamp: time.Now(),
@@ -342,9 +393,9 @@ func TestWriteRespectsMaxHistory(t *testing.T) {
 		})
 	}
 
-	sf, err := ReadSessionByID("s1")
+	sf, err := s.ReadSession("s1")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if len(sf.History) != MaxHistory {
 		t.Errorf("History length = %d, want %d", len(sf.History), MaxHistory)
@@ -392,12 +443,13 @@ func TestReadBackwardCompatLegacyFormat(t *testing.T) {
 func TestReadSessionByID(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "lookup", Event: "idle_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "lookup", Event: "idle_prompt", Timestamp: time.Now()})
 
-	sf, err := ReadSessionByID("lookup")
+	sf, err := s.ReadSession("lookup")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if sf.Current.SessionID != "lookup" {
 		t.Errorf("SessionID = %q, want %q", sf.Current.SessionID, "lookup")
@@ -407,43 +459,44 @@ func TestReadSessionByID(t *testing.T) {
 func TestListDeduplicatesByWindowAndCWD(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	now := time.Now()
 
 	// Two entries with different session IDs but same kitty_window_id + cwd.
 	// This simulates Claude Code regenerating a session ID for the same project/window.
 	older := &Entry{
-		SessionID:     "session-old",
-		KittyWindowID: "42",
-		CWD:           "/home/fred/git/project",
-		Event:         "permission_prompt",
-		Timestamp:     now.Add(-10 * time.Minute),
-		PID:           os.Getpid(),
+		SessionID: "session-old",
+		WindowID:  "42",
+		CWD:       "/home/fred/git/project",
+		Event:     "permission_prompt",
+		Timestamp: now.Add(-10 * time.Minute),
+		PID:       os.Getpid(),
 	}
 	newer := &Entry{
-		SessionID:     "session-new",
-		KittyWindowID: "42",
-		CWD:           "/home/fred/git/project",
-		Event:         "idle_prompt",
-		Timestamp:     now,
-		PID:           os.Getpid(),
+		SessionID: "session-new",
+		WindowID:  "42",
+		CWD:       "/home/fred/git/project",
+		Event:     "idle_prompt",
+		Timestamp: now,
+		PID:       os.Getpid(),
 	}
 
-	if err := Write(older); err != nil {
-		t.Fatalf("Write(older): %v", err)
+	if err := s.Write(older); err != nil {
+		t.Fatalf("s.Write(older): %v", err)
 	}
-	if err := Write(newer); err != nil {
-		t.Fatalf("Write(newer): %v", err)
+	if err := s.Write(newer); err != nil {
+		t.Fatalf("s.Write(newer): %v", err)
 	}
 
-	entries, err := List()
+	entries, err := s.List()
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
 
 	// Should return only 1 entry (the most recent one), not 2.
 	if len(entries) != 1 {
-		t.Errorf("List() returned %d entries, want 1 (deduped by window_id+cwd)", len(entries))
+		t.Errorf("s.List() returned %d entries, want 1 (deduped by window_id+cwd)", len(entries))
 	}
 	if len(entries) == 1 && entries[0].SessionID != "session-new" {
 		t.Errorf("expected the newer session, got SessionID=%q", entries[0].SessionID)
@@ -453,94 +506,97 @@ func TestListDeduplicatesByWindowAndCWD(t *testing.T) {
 func TestListDeduplicatesByWindowAndCWD_DifferentWindows(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	now := time.Now()
 
 	// Two entries with the same CWD but different kitty_window_id.
 	// These should NOT be deduped -- they are genuinely different windows.
 	e1 := &Entry{
-		SessionID:     "sess-win10",
-		KittyWindowID: "10",
-		CWD:           "/home/fred/git/project",
-		Event:         "permission_prompt",
-		Timestamp:     now,
-		PID:           os.Getpid(),
+		SessionID: "sess-win10",
+		WindowID:  "10",
+		CWD:       "/home/fred/git/project",
+		Event:     "permission_prompt",
+		Timestamp: now,
+		PID:       os.Getpid(),
 	}
 	e2 := &Entry{
-		SessionID:     "sess-win20",
-		KittyWindowID: "20",
-		CWD:           "/home/fred/git/project",
-		Event:         "idle_prompt",
-		Timestamp:     now,
-		PID:           os.Getpid(),
+		SessionID: "sess-win20",
+		WindowID:  "20",
+		CWD:       "/home/fred/git/project",
+		Event:     "idle_prompt",
+		Timestamp: now,
+		PID:       os.Getpid(),
 	}
 
-	Write(e1)
-	Write(e2)
+	s.Write(e1)
+	s.Write(e2)
 
-	entries, err := List()
+	entries, err := s.List()
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
 
 	if len(entries) != 2 {
-		t.Errorf("List() returned %d entries, want 2 (different window IDs should not be deduped)", len(entries))
+		t.Errorf("s.List() returned %d entries, want 2 (different window IDs should not be deduped)", len(entries))
 	}
 }
 
 func TestListDeduplicatesByWindowAndCWD_EmptyWindowID(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	now := time.Now()
 
 	// Entries with empty kitty_window_id should all be kept (no dedup key).
 	e1 := &Entry{
-		SessionID:     "sess-no-wid-1",
-		KittyWindowID: "",
-		CWD:           "/home/fred/git/project",
-		Event:         "permission_prompt",
-		Timestamp:     now.Add(-5 * time.Minute),
-		PID:           os.Getpid(),
+		SessionID: "sess-no-wid-1",
+		WindowID:  "",
+		CWD:       "/home/fred/git/project",
+		Event:     "permission_prompt",
+		Timestamp: now.Add(-5 * time.Minute),
+		PID:       os.Getpid(),
 	}
 	e2 := &Entry{
-		SessionID:     "sess-no-wid-2",
-		KittyWindowID: "",
-		CWD:           "/home/fred/git/project",
-		Event:         "idle_prompt",
-		Timestamp:     now,
-		PID:           os.Getpid(),
+		SessionID: "sess-no-wid-2",
+		WindowID:  "",
+		CWD:       "/home/fred/git/project",
+		Event:     "idle_prompt",
+		Timestamp: now,
+		PID:       os.Getpid(),
 	}
 
-	Write(e1)
-	Write(e2)
+	s.Write(e1)
+	s.Write(e2)
 
-	entries, err := List()
+	entries, err := s.List()
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
 
 	// Both should be kept since we can't deduplicate without a window ID.
 	if len(entries) != 2 {
-		t.Errorf("List() returned %d entries, want 2 (empty window IDs should not be deduped)", len(entries))
+		t.Errorf("s.List() returned %d entries, want 2 (empty window IDs should not be deduped)", len(entries))
 	}
 }
 
 func TestTouch(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	original := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow?", Timestamp: original})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Message: "Allow?", Timestamp: original})
 
 	updated := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
-	if err := Touch("s1", updated); err != nil {
+	if err := s.Touch("s1", updated); err != nil {
 		t.Fatalf("Touch: %v", err)
 	}
 
-	sf, err := ReadSessionByID("s1")
+	sf, err := s.ReadSession("s1")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if !sf.Current.Timestamp.Equal(updated) {
 		t.Errorf("Timestamp = %v, want %v", sf.Current.Timestamp, updated)
@@ -553,22 +609,75 @@ func TestTouch(t *testing.T) {
 	}
 }
 
+func TestUpdate(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
+
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+
+	err := s.Update("s1", func(e *Entry) error {
+		e.Tool = &ToolCall{Name: "Bash", Summary: "ls"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	sf, _ := s.ReadSession("s1")
+	if sf.Current.Tool == nil || sf.Current.Tool.Name != "Bash" {
+		t.Errorf("Tool = %+v, want Bash", sf.Current.Tool)
+	}
+	if len(sf.History) != 1 {
+		t.Errorf("History length = %d, want 1 (Update must not push history)", len(sf.History))
+	}
+
+	if err := s.Update("missing", func(*Entry) error { return nil }); !os.IsNotExist(err) {
+		t.Errorf("Update(missing) error = %v, want not-exist", err)
+	}
+}
+
+func TestWriteCarriesToolIntoPermissionPrompt(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
+
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+	s.Update("s1", func(e *Entry) error {
+		e.Tool = &ToolCall{Name: "WebFetch", Summary: "https://example.com"}
+		return nil
+	})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+
+	sf, _ := s.ReadSession("s1")
+	if sf.Current.Tool == nil || sf.Current.Tool.Summary != "https://example.com" {
+		t.Errorf("permission_prompt Tool = %+v, want carried over", sf.Current.Tool)
+	}
+
+	s.Write(&Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()})
+	sf, _ = s.ReadSession("s1")
+	if sf.Current.Tool != nil {
+		t.Errorf("idle_prompt Tool = %+v, want nil", sf.Current.Tool)
+	}
+}
+
 func TestTouchPreservesHistory(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
-	Write(&Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "working", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()})
 
 	updated := time.Date(2026, 2, 22, 15, 0, 0, 0, time.UTC)
-	if err := Touch("s1", updated); err != nil {
+	if err := s.Touch("s1", updated); err != nil {
 		t.Fatalf("Touch: %v", err)
 	}
 
-	sf, err := ReadSessionByID("s1")
+	sf, err := s.ReadSession("s1")
 	if err != nil {
-		t.Fatalf("ReadSessionByID: %v", err)
+		t.Fatalf("ReadSession: %v", err)
 	}
 	if len(sf.History) != 2 {
 		t.Fatalf("History length = %d, want 2", len(sf.History))
@@ -584,70 +693,66 @@ func TestTouchPreservesHistory(t *testing.T) {
 func TestTouchNonexistent(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
-	err := Touch("nonexistent", time.Now())
+	err := s.Touch("nonexistent", time.Now())
 	if err == nil {
 		t.Error("expected error for nonexistent session")
 	}
 }
 
-func TestTouchByWindowID(t *testing.T) {
+// inWindow matches the entries of window wid.
+func inWindow(wid string) func(e *Entry) bool {
+	return func(e *Entry) bool { return e.WindowID == wid }
+}
+
+func TestTouchWhere(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	original := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
-	Write(&Entry{SessionID: "current", KittyWindowID: "100", Event: "working", Timestamp: original, PID: os.Getpid()})
-	Write(&Entry{SessionID: "other", KittyWindowID: "200", Event: "permission_prompt", Timestamp: original, PID: os.Getpid()})
+	s.Write(&Entry{SessionID: "current", WindowID: "100", Event: "working", Timestamp: original, PID: os.Getpid()})
+	s.Write(&Entry{SessionID: "other", WindowID: "200", Event: "permission_prompt", Timestamp: original, PID: os.Getpid()})
 
 	updated := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
-	if err := TouchByWindowID("100", updated); err != nil {
-		t.Fatalf("TouchByWindowID: %v", err)
+	if err := TouchWhere(s, nil, inWindow("100"), updated); err != nil {
+		t.Fatalf("TouchWhere: %v", err)
 	}
 
 	// "current" should have the updated timestamp.
-	sf, err := ReadSessionByID("current")
+	sf, err := s.ReadSession("current")
 	if err != nil {
-		t.Fatalf("ReadSessionByID(current): %v", err)
+		t.Fatalf("s.ReadSession(current): %v", err)
 	}
 	if !sf.Current.Timestamp.Equal(updated) {
 		t.Errorf("current timestamp = %v, want %v", sf.Current.Timestamp, updated)
 	}
 
 	// "other" should be untouched.
-	sf, err = ReadSessionByID("other")
+	sf, err = s.ReadSession("other")
 	if err != nil {
-		t.Fatalf("ReadSessionByID(other): %v", err)
+		t.Fatalf("s.ReadSession(other): %v", err)
 	}
 	if !sf.Current.Timestamp.Equal(original) {
 		t.Errorf("other timestamp = %v, want %v", sf.Current.Timestamp, original)
 	}
 }
 
-func TestTouchByWindowID_EmptyWID(t *testing.T) {
-	tmp := t.TempDir()
-	t.Setenv("XDG_STATE_HOME", tmp)
-
-	Write(&Entry{SessionID: "s1", KittyWindowID: "100", Event: "working", Timestamp: time.Now(), PID: os.Getpid()})
-
-	// Empty wid should be a no-op.
-	if err := TouchByWindowID("", time.Now()); err != nil {
-		t.Fatalf("TouchByWindowID: %v", err)
-	}
-}
-
-func TestTouchByWindowID_NoMatch(t *testing.T) {
+func TestTouchWhere_NoMatch(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	original := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
-	Write(&Entry{SessionID: "s1", KittyWindowID: "100", Event: "working", Timestamp: original, PID: os.Getpid()})
+	s.Write(&Entry{SessionID: "s1", WindowID: "100", Event: "working", Timestamp: original, PID: os.Getpid()})
 
-	// Non-matching wid should be a no-op.
-	if err := TouchByWindowID("999", time.Now()); err != nil {
-		t.Fatalf("TouchByWindowID: %v", err)
+	// Non-matching window should be a no-op.
+	if err := TouchWhere(s, nil, inWindow("999"), time.Now()); err != nil {
+		t.Fatalf("TouchWhere: %v", err)
 	}
 
-	sf, _ := ReadSessionByID("s1")
+	sf, _ := s.ReadSession("s1")
 	if !sf.Current.Timestamp.Equal(original) {
 		t.Errorf("timestamp changed unexpectedly: got %v, want %v", sf.Current.Timestamp, original)
 	}
@@ -656,9 +761,10 @@ func TestTouchByWindowID_NoMatch(t *testing.T) {
 func TestConcurrentWritesDontLoseHistory(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
 
 	// Seed an initial entry.
-	Write(&Entry{SessionID: "conc", Event: "SessionStart", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "conc", Event: "SessionStart", Timestamp: time.Now()})
 
 	// Write concurrently with real flock (DefaultLocker is flockLocker).
 	const goroutines = 10
@@ -668,7 +774,7 @@ func TestConcurrentWritesDontLoseHistory(t *testing.T) {
 		wg.Add(1)
 		go func(n int) {
 			defer wg.Done()
-			Write(&Entry{
+			s.Write(&Entry{
 				SessionID: "conc",
 				Event:     events[n%2],
 				Timestamp: time.Now(),
@@ -678,11 +784,128 @@ func TestConcurrentWritesDontLoseHistory(t *testing.T) {
 	wg.Wait()
 
 	// File should still be valid JSON with a current entry.
-	sf, err := ReadSessionByID("conc")
+	sf, err := s.ReadSession("conc")
 	if err != nil {
-		t.Fatalf("ReadSessionByID after concurrent writes: %v", err)
+		t.Fatalf("ReadSession after concurrent writes: %v", err)
 	}
 	if sf.Current == nil {
 		t.Fatal("Current is nil after concurrent writes")
 	}
 }
+
+func TestWriteLeavesNoTempFiles(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
+
+	s.Write(&Entry{SessionID: "s1", Event: "SessionStart", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+	s.Touch("s1", time.Now())
+
+	files, err := os.ReadDir(Dir())
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if len(names) != 2 || names[0] != lockFileName || names[1] != "s1.json" {
+		t.Errorf("files = %v, want [%s s1.json]", names, lockFileName)
+	}
+}
+
+func TestWriteReplacesFileAtomically(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
+
+	s.Write(&Entry{SessionID: "s1", Event: "SessionStart", Timestamp: time.Now()})
+
+	// A reader that opened the file before the write keeps seeing the
+	// complete old content rather than a truncated file.
+	f, err := os.Open(filepath.Join(Dir(), "s1.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+
+	var old SessionFile
+	if err := json.NewDecoder(f).Decode(&old); err != nil {
+		t.Fatalf("decoding old content: %v", err)
+	}
+	if old.Current.Event != "SessionStart" {
+		t.Errorf("old Current.Event = %q, want %q", old.Current.Event, "SessionStart")
+	}
+
+	sf, err := s.ReadSession("s1")
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	if sf.Current.Event != "permission_prompt" {
+		t.Errorf("Current.Event = %q, want %q", sf.Current.Event, "permission_prompt")
+	}
+}
+
+func TestListIgnoresStrayTempFiles(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmp)
+	s := NewFileStore("")
+
+	s.Write(&Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()})
+	// Leftover from a writer killed before rename.
+	os.WriteFile(filepath.Join(Dir(), ".s2.json.tmp-123"), []byte(`{"current":{"sess`), 0644)
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 || entries[0].SessionID != "s1" {
+		t.Errorf("entries = %v, want only s1", entries)
+	}
+}
+
+func TestIsSnoozed(t *testing.T) {
+	now := time.Date(2026, 2, 18, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		e    Entry
+		want bool
+	}{
+		{"not snoozed", Entry{}, false},
+		{"until next event", Entry{Snoozed: true}, true},
+		{"timed, pending", Entry{Snoozed: true, SnoozeUntil: now.Add(time.Minute)}, true},
+		{"timed, expired", Entry{Snoozed: true, SnoozeUntil: now}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.e.IsSnoozed(now); got != tt.want {
+			t.Errorf("%s: IsSnoozed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteClearsSnoozeAndRead(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	store := NewFileStore("")
+	if err := store.Write(&Entry{SessionID: "s1", Event: "idle_prompt"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update("s1", func(e *Entry) error {
+		e.Snoozed, e.Read = true, true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Write(&Entry{SessionID: "s1", Event: "permission_prompt"}); err != nil {
+		t.Fatal(err)
+	}
+	sf, err := store.ReadSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.Current.Snoozed || sf.Current.Read {
+		t.Errorf("new event should clear snooze and read marks: %+v", sf.Current)
+	}
+}
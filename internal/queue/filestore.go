@@ -0,0 +1,272 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// lockFileName is the store-wide lock file inside the store directory.
+const lockFileName = ".lock"
+
+// FileStore is a Store that keeps one JSON file per session in a directory.
+// Session files are replaced atomically (write to temp, fsync, rename) and
+// concurrent read-modify-write cycles are serialized with DefaultLocker.
+type FileStore struct {
+	dir string
+	// Journal records the duplicates List removes. Nil to skip.
+	Journal *Journal
+}
+
+// NewFileStore returns a FileStore rooted at dir.
+// An empty dir means Dir(), resolved on every call.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Dir returns the directory holding the session files.
+func (s *FileStore) Dir() string {
+	if s.dir != "" {
+		return s.dir
+	}
+	return Dir()
+}
+
+// path returns the file path for a given session ID.
+func (s *FileStore) path(sessionID string) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_").Replace(sessionID)
+	return filepath.Join(s.Dir(), safe+".json")
+}
+
+// Write persists an entry to disk, keyed by session ID.
+// It takes the store lock, reads the existing session, pushes the old current
+// entry into history (deduplicating consecutive same-event entries), and
+// atomically replaces the session file.
+func (s *FileStore) Write(e *Entry) error {
+	if err := os.MkdirAll(s.Dir(), 0755); err != nil {
+		return err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := s.path(e.SessionID)
+	var sf SessionFile
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		sf, _ = parseSessionFile(data)
+	}
+
+	pushCurrent(&sf, e)
+
+	if err := writeSessionFile(path, &sf); err != nil {
+		return err
+	}
+
+	Debugf("WRITE session=%s event=%s cwd=%s pid=%d wid=%s", e.SessionID, e.Event, e.CWD, e.PID, e.WindowID)
+	return nil
+}
+
+// Touch atomically updates the timestamp of an existing session entry
+// without modifying event, message, or history.
+func (s *FileStore) Touch(sessionID string, now time.Time) error {
+	path := s.path(sessionID)
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	sf, err := parseSessionFile(data)
+	if err != nil || sf.Current == nil {
+		return err
+	}
+
+	sf.Current.Timestamp = now
+
+	if err := writeSessionFile(path, &sf); err != nil {
+		return err
+	}
+
+	Debugf("TOUCH session=%s timestamp=%s", sessionID, now.Format(time.RFC3339))
+	return nil
+}
+
+// Update atomically applies fn to the current entry of an existing session.
+func (s *FileStore) Update(sessionID string, fn func(e *Entry) error) error {
+	path := s.path(sessionID)
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	sf, err := ReadSession(path)
+	if err != nil {
+		return err
+	}
+	if sf.Current == nil {
+		return notFound(sessionID)
+	}
+	if err := fn(sf.Current); err != nil {
+		return err
+	}
+	return writeSessionFile(path, sf)
+}
+
+// lock takes the store-wide lock that serializes read-modify-write cycles.
+// Session files are replaced by rename, so the lock lives in a separate
+// file whose inode never changes. Readers do not need the lock: they always
+// see either the old or the new session file, never a partial one.
+func (s *FileStore) lock() (unlock func(), err error) {
+	f, err := os.OpenFile(filepath.Join(s.Dir(), lockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := DefaultLocker.Lock(int(f.Fd())); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		DefaultLocker.Unlock(int(f.Fd()))
+		f.Close()
+	}, nil
+}
+
+// TryLock takes an exclusive lock on the file at path without waiting, for
+// background processes that must not run twice. ok is false when another
+// process holds the lock.
+func TryLock(path string) (unlock func(), ok bool, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, true, nil
+}
+
+// writeSessionFile atomically replaces path with the JSON encoding of sf.
+func writeSessionFile(path string, sf *SessionFile) error {
+	out, err := json.MarshalIndent(sf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, out, 0644)
+}
+
+// WriteFileAtomic writes data to a temporary file in the same directory,
+// fsyncs it, and renames it over path. A process killed partway leaves at
+// most a stray temporary file behind, never a truncated path.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir, name := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	// Persist the rename itself; failure here only weakens durability.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// ReadSession loads a full SessionFile by session ID.
+func (s *FileStore) ReadSession(sessionID string) (*SessionFile, error) {
+	return ReadSession(s.path(sessionID))
+}
+
+// List returns all entries in the store directory.
+// When multiple entries share the same (terminal, window, cwd) tuple,
+// only the most recent one is kept and the older duplicates are removed from disk.
+// Entries without a window are never deduplicated.
+func (s *FileStore) List() ([]*Entry, error) {
+	files, err := filepath.Glob(filepath.Join(s.Dir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, f := range files {
+		e, err := Read(f)
+		if err != nil || e == nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	result, dupes := dedupEntries(entries)
+	for _, d := range dupes {
+		Debugf("DEDUP removing session=%s (superseded by session=%s for wid=%s cwd=%s)",
+			d.stale.SessionID, d.winner.SessionID, d.stale.WindowID, d.stale.CWD)
+		if err := s.Remove(d.stale.SessionID); err == nil {
+			s.Journal.Record(OpRemove, d.stale, ReasonDedup, time.Now())
+		}
+	}
+	return result, nil
+}
+
+// Remove deletes the entry for a given session ID.
+func (s *FileStore) Remove(sessionID string) error {
+	Debugf("REMOVE session=%s", sessionID)
+	return os.Remove(s.path(sessionID))
+}
+
+// RemoveAll deletes every entry in the store.
+func (s *FileStore) RemoveAll() error {
+	files, err := filepath.Glob(filepath.Join(s.Dir(), "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		os.Remove(f)
+	}
+	return nil
+}
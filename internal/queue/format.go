@@ -10,7 +10,11 @@ import (
 
 // FormatAge returns a human-readable age string like "3s", "5m", "2h".
 func FormatAge(t time.Time) string {
-	d := time.Since(t)
+	return FormatDuration(time.Since(t))
+}
+
+// FormatDuration returns a human-readable duration string like "3s", "5m", "2h".
+func FormatDuration(d time.Duration) string {
 	switch {
 	case d < time.Minute:
 		return fmt.Sprintf("%ds", int(d.Seconds()))
@@ -36,6 +40,10 @@ func EventLabel(event string) string {
 		return "START"
 	case "SessionEnd":
 		return "END"
+	case "Stop":
+		return "DONE"
+	case "SubagentStop":
+		return "SUB"
 	default:
 		return strings.ToUpper(event)
 	}
@@ -44,13 +52,27 @@ func EventLabel(event string) string {
 // NeedsAttention returns true if the event represents a state that needs user input.
 func NeedsAttention(event string) bool {
 	switch event {
-	case "", "working", "SessionStart", "SessionEnd":
+	case "", "working", "SessionStart", "SessionEnd", "SubagentStop":
 		return false
 	default:
 		return true
 	}
 }
 
+// AttentionRank orders events for the picker: sessions blocked on a question
+// or that just finished a turn (0) come before sessions that have gone idle
+// (1), which come before sessions that need nothing (2).
+func AttentionRank(event string) int {
+	switch {
+	case !NeedsAttention(event):
+		return 2
+	case event == "idle_prompt":
+		return 1
+	default:
+		return 0
+	}
+}
+
 // GitBranch returns the current git branch for a directory, or "" if not a git repo.
 func GitBranch(cwd string) string {
 	cmd := exec.Command("git", "-C", cwd, "rev-parse", "--abbrev-ref", "HEAD")
@@ -42,6 +42,8 @@ func TestEventLabel(t *testing.T) {
 		{"working", "WORK"},
 		{"SessionStart", "START"},
 		{"SessionEnd", "END"},
+		{"Stop", "DONE"},
+		{"SubagentStop", "SUB"},
 		{"unknown_thing", "UNKNOWN_THING"},
 	}
 	for _, tt := range tests {
@@ -65,6 +67,8 @@ func TestNeedsAttention(t *testing.T) {
 		{"working", false},
 		{"SessionStart", false},
 		{"SessionEnd", false},
+		{"Stop", true},
+		{"SubagentStop", false},
 		{"", false},
 	}
 	for _, tt := range tests {
@@ -77,6 +81,28 @@ func TestNeedsAttention(t *testing.T) {
 	}
 }
 
+func TestAttentionRank(t *testing.T) {
+	tests := []struct {
+		event string
+		want  int
+	}{
+		{"permission_prompt", 0},
+		{"elicitation_dialog", 0},
+		{"Stop", 0},
+		{"idle_prompt", 1},
+		{"working", 2},
+		{"SubagentStop", 2},
+		{"SessionStart", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			if got := AttentionRank(tt.event); got != tt.want {
+				t.Errorf("AttentionRank(%q) = %d, want %d", tt.event, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGitBranch_InGitRepo(t *testing.T) {
 	dir := t.TempDir()
 	// Init a git repo with a commit so HEAD exists.
@@ -5,15 +5,122 @@ import (
 	"io"
 )
 
+// HookCommon holds the fields Claude Code sends with every hook event.
+type HookCommon struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	CWD            string `json:"cwd"`
+	// PermissionMode is the session's permission mode
+	// (default, plan, acceptEdits, bypassPermissions).
+	PermissionMode string `json:"permission_mode,omitempty"`
+	HookEventName  string `json:"hook_event_name"`
+}
+
+// NotificationInput is sent when Claude Code needs the user's attention.
+type NotificationInput struct {
+	HookCommon
+	Message          string `json:"message"`
+	NotificationType string `json:"notification_type,omitempty"`
+}
+
+// UserPromptSubmitInput is sent when the user submits a prompt.
+type UserPromptSubmitInput struct {
+	HookCommon
+	Prompt string `json:"prompt"`
+}
+
+// SessionStartInput is sent when a session starts or resumes.
+type SessionStartInput struct {
+	HookCommon
+	// Source is startup, resume, clear or compact.
+	Source string `json:"source"`
+}
+
+// SessionEndInput is sent when a session ends.
+type SessionEndInput struct {
+	HookCommon
+	// Reason is clear, logout, prompt_input_exit or other.
+	Reason string `json:"reason"`
+}
+
+// StopInput is sent when the main agent finishes responding.
+type StopInput struct {
+	HookCommon
+	// StopHookActive is true when Claude Code is already continuing
+	// because of a stop hook.
+	StopHookActive bool `json:"stop_hook_active"`
+}
+
+// SubagentStopInput is sent when a subagent finishes responding.
+type SubagentStopInput struct {
+	HookCommon
+	StopHookActive bool `json:"stop_hook_active"`
+}
+
+// PreToolUseInput is sent before a tool call runs.
+type PreToolUseInput struct {
+	HookCommon
+	ToolName  string          `json:"tool_name"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+}
+
+// PostToolUseInput is sent after a tool call completes.
+type PostToolUseInput struct {
+	HookCommon
+	ToolName     string          `json:"tool_name"`
+	ToolInput    json.RawMessage `json:"tool_input,omitempty"`
+	ToolResponse json.RawMessage `json:"tool_response,omitempty"`
+	ToolUseID    string          `json:"tool_use_id,omitempty"`
+}
+
+// PreCompactInput is sent before the conversation is compacted.
+type PreCompactInput struct {
+	HookCommon
+	// Trigger is manual or auto.
+	Trigger            string `json:"trigger"`
+	CustomInstructions string `json:"custom_instructions,omitempty"`
+}
+
 // HookInput captures common fields from Claude Code hook JSON.
 type HookInput struct {
-	SessionID     string `json:"session_id"`
-	CWD           string `json:"cwd"`
-	HookEventName string `json:"hook_event_name"`
+	HookCommon
+	// Event holds the typed payload for known events: *NotificationInput,
+	// *UserPromptSubmitInput, *SessionStartInput, *SessionEndInput,
+	// *StopInput, *SubagentStopInput, *PreToolUseInput, *PostToolUseInput
+	// or *PreCompactInput. It is nil for unknown events.
+	Event any `json:"-"`
 	// Raw holds the full parsed JSON for extracting event-specific fields.
 	Raw map[string]any `json:"-"`
 }
 
+// newHookEvent returns an empty typed payload for a hook event name,
+// or nil if the event is unknown.
+func newHookEvent(name string) any {
+	switch name {
+	case "Notification":
+		return &NotificationInput{}
+	case "UserPromptSubmit":
+		return &UserPromptSubmitInput{}
+	case "SessionStart":
+		return &SessionStartInput{}
+	case "SessionEnd":
+		return &SessionEndInput{}
+	case "Stop":
+		return &StopInput{}
+	case "SubagentStop":
+		return &SubagentStopInput{}
+	case "PreToolUse":
+		return &PreToolUseInput{}
+	case "PostToolUse":
+		return &PostToolUseInput{}
+	case "PreCompact":
+		return &PreCompactInput{}
+	default:
+		return nil
+	}
+}
+
 // ParseHookInput reads and parses hook JSON from a reader.
 func ParseHookInput(r io.Reader) (*HookInput, error) {
 	data, err := io.ReadAll(r)
@@ -22,10 +129,17 @@ func ParseHookInput(r io.Reader) (*HookInput, error) {
 	}
 
 	var input HookInput
-	if err := json.Unmarshal(data, &input); err != nil {
+	if err := json.Unmarshal(data, &input.HookCommon); err != nil {
 		return nil, err
 	}
 
+	if ev := newHookEvent(input.HookEventName); ev != nil {
+		if err := json.Unmarshal(data, ev); err != nil {
+			return nil, err
+		}
+		input.Event = ev
+	}
+
 	var raw map[string]any
 	json.Unmarshal(data, &raw)
 	input.Raw = raw
@@ -33,13 +147,32 @@ func ParseHookInput(r io.Reader) (*HookInput, error) {
 	return &input, nil
 }
 
-// EventType returns the most specific event type available.
-// It checks known field names in the raw JSON, falling back to HookEventName.
+// EventType returns the notification type of Notification events
+// (permission_prompt, idle_prompt, ...) and the hook event name otherwise.
 func (h *HookInput) EventType() string {
-	for _, key := range []string{"notification_type", "type", "matcher"} {
-		if v, ok := h.Raw[key].(string); ok && v != "" {
-			return v
-		}
+	if n, ok := h.Event.(*NotificationInput); ok && n.NotificationType != "" {
+		return n.NotificationType
 	}
 	return h.HookEventName
 }
+
+// Message returns the notification message, or "" for other events.
+func (h *HookInput) Message() string {
+	if n, ok := h.Event.(*NotificationInput); ok {
+		return n.Message
+	}
+	return ""
+}
+
+// ApplyTo copies the session metadata carried by the hook onto e:
+// transcript path, permission mode, and the start source or end reason.
+func (h *HookInput) ApplyTo(e *Entry) {
+	e.TranscriptPath = h.TranscriptPath
+	e.PermissionMode = h.PermissionMode
+	switch ev := h.Event.(type) {
+	case *SessionStartInput:
+		e.Source = ev.Source
+	case *SessionEndInput:
+		e.EndReason = ev.Reason
+	}
+}
@@ -53,14 +53,173 @@ func TestEventType_FallbackToHookEventName(t *testing.T) {
 	}
 }
 
-func TestEventType_TypeField(t *testing.T) {
+func TestEventType_IgnoresUntypedFields(t *testing.T) {
+	tests := map[string]string{
+		// "type" is no field of the Notification event.
+		`{"hook_event_name": "Notification", "type": "idle_prompt"}`: "Notification",
+		// matcher is a tool name pattern, not an event type.
+		`{"hook_event_name": "PreToolUse", "matcher": "Bash"}`: "PreToolUse",
+		// notification_type only means something on Notification.
+		`{"hook_event_name": "Stop", "notification_type": "idle_prompt"}`: "Stop",
+	}
+	for input, want := range tests {
+		hi, err := ParseHookInput(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ParseHookInput(%s): %v", input, err)
+		}
+		if got := hi.EventType(); got != want {
+			t.Errorf("EventType() of %s = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseHookInput_CommonFields(t *testing.T) {
 	input := `{
-		"session_id": "s3",
-		"hook_event_name": "Notification",
-		"type": "idle_prompt"
+		"session_id": "s1",
+		"transcript_path": "/tmp/t.jsonl",
+		"cwd": "/p",
+		"permission_mode": "plan",
+		"hook_event_name": "Stop",
+		"stop_hook_active": true
 	}`
-	hi, _ := ParseHookInput(strings.NewReader(input))
-	if got := hi.EventType(); got != "idle_prompt" {
-		t.Errorf("EventType() = %q, want %q", got, "idle_prompt")
+	hi, err := ParseHookInput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput: %v", err)
+	}
+	if hi.TranscriptPath != "/tmp/t.jsonl" || hi.PermissionMode != "plan" {
+		t.Errorf("common fields = %+v", hi.HookCommon)
+	}
+	stop, ok := hi.Event.(*StopInput)
+	if !ok {
+		t.Fatalf("Event = %T, want *StopInput", hi.Event)
+	}
+	if !stop.StopHookActive || stop.SessionID != "s1" {
+		t.Errorf("StopInput = %+v", stop)
+	}
+}
+
+func TestParseHookInput_TypedEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		check func(t *testing.T, ev any)
+	}{
+		{
+			name:  "Notification",
+			input: `{"hook_event_name":"Notification","message":"Allow?","notification_type":"permission_prompt"}`,
+			check: func(t *testing.T, ev any) {
+				n := ev.(*NotificationInput)
+				if n.Message != "Allow?" || n.NotificationType != "permission_prompt" {
+					t.Errorf("got %+v", n)
+				}
+			},
+		},
+		{
+			name:  "UserPromptSubmit",
+			input: `{"hook_event_name":"UserPromptSubmit","prompt":"fix it"}`,
+			check: func(t *testing.T, ev any) {
+				if p := ev.(*UserPromptSubmitInput); p.Prompt != "fix it" {
+					t.Errorf("got %+v", p)
+				}
+			},
+		},
+		{
+			name:  "SessionStart",
+			input: `{"hook_event_name":"SessionStart","source":"resume"}`,
+			check: func(t *testing.T, ev any) {
+				if s := ev.(*SessionStartInput); s.Source != "resume" {
+					t.Errorf("got %+v", s)
+				}
+			},
+		},
+		{
+			name:  "SessionEnd",
+			input: `{"hook_event_name":"SessionEnd","reason":"logout"}`,
+			check: func(t *testing.T, ev any) {
+				if s := ev.(*SessionEndInput); s.Reason != "logout" {
+					t.Errorf("got %+v", s)
+				}
+			},
+		},
+		{
+			name:  "SubagentStop",
+			input: `{"hook_event_name":"SubagentStop","stop_hook_active":false}`,
+			check: func(t *testing.T, ev any) {
+				if _, ok := ev.(*SubagentStopInput); !ok {
+					t.Errorf("got %T", ev)
+				}
+			},
+		},
+		{
+			name:  "PreToolUse",
+			input: `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"ls"},"tool_use_id":"tu1"}`,
+			check: func(t *testing.T, ev any) {
+				p := ev.(*PreToolUseInput)
+				if p.ToolName != "Bash" || p.ToolUseID != "tu1" || string(p.ToolInput) != `{"command":"ls"}` {
+					t.Errorf("got %+v", p)
+				}
+			},
+		},
+		{
+			name:  "PostToolUse",
+			input: `{"hook_event_name":"PostToolUse","tool_name":"Write","tool_response":{"success":true}}`,
+			check: func(t *testing.T, ev any) {
+				p := ev.(*PostToolUseInput)
+				if p.ToolName != "Write" || string(p.ToolResponse) != `{"success":true}` {
+					t.Errorf("got %+v", p)
+				}
+			},
+		},
+		{
+			name:  "PreCompact",
+			input: `{"hook_event_name":"PreCompact","trigger":"manual","custom_instructions":"keep tests"}`,
+			check: func(t *testing.T, ev any) {
+				p := ev.(*PreCompactInput)
+				if p.Trigger != "manual" || p.CustomInstructions != "keep tests" {
+					t.Errorf("got %+v", p)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hi, err := ParseHookInput(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseHookInput: %v", err)
+			}
+			if hi.Event == nil {
+				t.Fatal("Event is nil")
+			}
+			tt.check(t, hi.Event)
+		})
+	}
+}
+
+func TestParseHookInput_UnknownEvent(t *testing.T) {
+	hi, err := ParseHookInput(strings.NewReader(`{"hook_event_name":"SomethingNew","foo":"bar"}`))
+	if err != nil {
+		t.Fatalf("ParseHookInput: %v", err)
+	}
+	if hi.Event != nil {
+		t.Errorf("Event = %T, want nil", hi.Event)
+	}
+	if hi.Raw["foo"] != "bar" {
+		t.Errorf("Raw not populated: %v", hi.Raw)
+	}
+}
+
+func TestHookInput_ApplyTo(t *testing.T) {
+	start, _ := ParseHookInput(strings.NewReader(`{"hook_event_name":"SessionStart","source":"clear","permission_mode":"acceptEdits","transcript_path":"/t.jsonl"}`))
+	var e Entry
+	start.ApplyTo(&e)
+	if e.Source != "clear" || e.PermissionMode != "acceptEdits" || e.TranscriptPath != "/t.jsonl" {
+		t.Errorf("after SessionStart: %+v", e)
+	}
+
+	end, _ := ParseHookInput(strings.NewReader(`{"hook_event_name":"SessionEnd","reason":"prompt_input_exit"}`))
+	e = Entry{}
+	end.ApplyTo(&e)
+	if e.EndReason != "prompt_input_exit" {
+		t.Errorf("EndReason = %q", e.EndReason)
 	}
 }
@@ -13,6 +13,7 @@ const (
 	pushCommand         = "cc-queue push"
 	popCommand          = "cc-queue pop"
 	endCommand          = "cc-queue end"
+	toolCommand         = "cc-queue tool"
 	notificationMatcher = "permission_prompt|idle_prompt|elicitation_dialog"
 )
 
@@ -63,6 +64,8 @@ func InstallHooks(target SettingsTarget) error {
 	addUserPromptSubmitHook(hooks)
 	addSessionStartHook(hooks)
 	addSessionEndHook(hooks)
+	addPreToolUseHook(hooks)
+	addStopHooks(hooks)
 
 	settings["hooks"] = hooks
 	return writeSettings(path, settings)
@@ -90,6 +93,9 @@ func UninstallHooks(target SettingsTarget) error {
 	removeHookCommand(hooks, "UserPromptSubmit", popCommand)
 	removeHookCommand(hooks, "SessionStart", pushCommand)
 	removeHookCommand(hooks, "SessionEnd", endCommand)
+	removeHookCommand(hooks, "PreToolUse", toolCommand)
+	removeHookCommand(hooks, "Stop", pushCommand)
+	removeHookCommand(hooks, "SubagentStop", pushCommand)
 
 	settings["hooks"] = hooks
 	return writeSettings(path, settings)
@@ -245,31 +251,32 @@ func addUserPromptSubmitHook(hooks map[string]any) {
 
 // addSessionStartHook adds the push hook for SessionStart events.
 func addSessionStartHook(hooks map[string]any) {
-	eventKey := "SessionStart"
-	matchers := getOrCreateArray(hooks, eventKey)
-
-	if hasHookCommand(matchers, pushCommand) {
-		return
-	}
-
-	entry := map[string]any{
-		"matcher": "",
-		"hooks": []any{
-			map[string]any{
-				"type":    "command",
-				"command": pushCommand,
-			},
-		},
-	}
-	hooks[eventKey] = append(matchers, entry)
+	addCatchAllHook(hooks, "SessionStart", pushCommand)
 }
 
 // addSessionEndHook adds the end hook for SessionEnd events.
 func addSessionEndHook(hooks map[string]any) {
-	eventKey := "SessionEnd"
+	addCatchAllHook(hooks, "SessionEnd", endCommand)
+}
+
+// addPreToolUseHook adds the tool hook for PreToolUse events on every tool.
+func addPreToolUseHook(hooks map[string]any) {
+	addCatchAllHook(hooks, "PreToolUse", toolCommand)
+}
+
+// addStopHooks adds the push hook for Stop and SubagentStop events, so
+// finished turns are queued without waiting for the idle notification.
+func addStopHooks(hooks map[string]any) {
+	addCatchAllHook(hooks, "Stop", pushCommand)
+	addCatchAllHook(hooks, "SubagentStop", pushCommand)
+}
+
+// addCatchAllHook adds command under an empty matcher for eventKey, unless
+// it is already installed.
+func addCatchAllHook(hooks map[string]any, eventKey, command string) {
 	matchers := getOrCreateArray(hooks, eventKey)
 
-	if hasHookCommand(matchers, endCommand) {
+	if hasHookCommand(matchers, command) {
 		return
 	}
 
@@ -278,7 +285,7 @@ func addSessionEndHook(hooks map[string]any) {
 		"hooks": []any{
 			map[string]any{
 				"type":    "command",
-				"command": endCommand,
+				"command": command,
 			},
 		},
 	}
@@ -296,6 +303,8 @@ func getOrCreateArray(m map[string]any, key string) []any {
 type KittyShortcuts struct {
 	Picker     string // shortcut for the fzf picker overlay (e.g. "kitty_mod+shift+q")
 	First      string // shortcut for jump-to-first (e.g. "kitty_mod+shift+u")
+	Next       string // shortcut for cycling to the next session (e.g. "kitty_mod+shift+j")
+	Prev       string // shortcut for cycling to the previous session (e.g. "kitty_mod+shift+k")
 	BinaryPath string // absolute path to cc-queue binary
 	Shell      string // user's login shell for overlay wrapper
 }
@@ -324,7 +333,7 @@ func BuildKittyConfig(shortcuts KittyShortcuts) string {
 	b.WriteString("# Enable remote control for cross-window jumping\n")
 	b.WriteString("allow_remote_control socket-only\n")
 	b.WriteString("listen_on unix:/tmp/kitty-{kitty_pid}\n")
-	if shortcuts.Picker != "" || shortcuts.First != "" {
+	if shortcuts.Picker != "" || shortcuts.First != "" || shortcuts.Next != "" || shortcuts.Prev != "" {
 		b.WriteString("\n# Keyboard shortcuts\n")
 		shell := shortcuts.Shell
 		if shell == "" {
@@ -336,13 +345,21 @@ func BuildKittyConfig(shortcuts KittyShortcuts) string {
 		if shortcuts.First != "" {
 			fmt.Fprintf(&b, "map %s launch --type=overlay --title cc-queue %s -il -c 'exec %s --full-tab first'\n", shortcuts.First, shell, bin)
 		}
+		// next/prev run in the background, stepping from the active window,
+		// whose ID kitty substitutes for @active-kitty-window-id.
+		if shortcuts.Next != "" {
+			fmt.Fprintf(&b, "map %s launch --type=background %s -lc 'exec %s next --from \"$1\"' cc-queue @active-kitty-window-id\n", shortcuts.Next, shell, bin)
+		}
+		if shortcuts.Prev != "" {
+			fmt.Fprintf(&b, "map %s launch --type=background %s -lc 'exec %s prev --from \"$1\"' cc-queue @active-kitty-window-id\n", shortcuts.Prev, shell, bin)
+		}
 	}
 	return b.String()
 }
 
 // ParseKittyShortcuts extracts shortcut keys from existing cc-queue.conf content.
-// It looks for "map <key> ... cc-queue" lines and distinguishes picker vs first
-// by whether the line ends with the "first" subcommand.
+// It looks for "map <key> ... cc-queue" lines and tells them apart by the
+// subcommand they run: first, next, prev, or none for the picker.
 func ParseKittyShortcuts(content string) KittyShortcuts {
 	var shortcuts KittyShortcuts
 	for _, line := range strings.Split(content, "\n") {
@@ -355,9 +372,14 @@ func ParseKittyShortcuts(content string) KittyShortcuts {
 			continue
 		}
 		key := fields[1]
-		if strings.HasSuffix(line, "first'") {
+		switch {
+		case strings.HasSuffix(line, "first'"):
 			shortcuts.First = key
-		} else {
+		case strings.Contains(line, " next --from"):
+			shortcuts.Next = key
+		case strings.Contains(line, " prev --from"):
+			shortcuts.Prev = key
+		default:
 			shortcuts.Picker = key
 		}
 	}
@@ -433,16 +455,21 @@ type HookStatus struct {
 	UserPromptSubmit bool
 	SessionStart     bool
 	SessionEnd       bool
+	PreToolUse       bool
+	Stop             bool
+	SubagentStop     bool
 }
 
-// AllInstalled returns true if all four hooks are installed.
+// AllInstalled returns true if all hooks are installed.
 func (s *HookStatus) AllInstalled() bool {
-	return s.Notification && s.UserPromptSubmit && s.SessionStart && s.SessionEnd
+	return s.Notification && s.UserPromptSubmit && s.SessionStart && s.SessionEnd && s.PreToolUse &&
+		s.Stop && s.SubagentStop
 }
 
 // AnyInstalled returns true if at least one hook is installed.
 func (s *HookStatus) AnyInstalled() bool {
-	return s.Notification || s.UserPromptSubmit || s.SessionStart || s.SessionEnd
+	return s.Notification || s.UserPromptSubmit || s.SessionStart || s.SessionEnd || s.PreToolUse ||
+		s.Stop || s.SubagentStop
 }
 
 // CheckHooks reads the settings file for the given target and checks which
@@ -468,6 +495,9 @@ func CheckHooks(target SettingsTarget) (*HookStatus, string, error) {
 		UserPromptSubmit: hasHookCommand(getOrCreateArray(hooks, "UserPromptSubmit"), popCommand),
 		SessionStart:     hasHookCommand(getOrCreateArray(hooks, "SessionStart"), pushCommand),
 		SessionEnd:       hasHookCommand(getOrCreateArray(hooks, "SessionEnd"), endCommand),
+		PreToolUse:       hasHookCommand(getOrCreateArray(hooks, "PreToolUse"), toolCommand),
+		Stop:             hasHookCommand(getOrCreateArray(hooks, "Stop"), pushCommand),
+		SubagentStop:     hasHookCommand(getOrCreateArray(hooks, "SubagentStop"), pushCommand),
 	}, path, nil
 }
 
@@ -77,6 +77,27 @@ func TestBuildKittyConfig_NoShortcuts(t *testing.T) {
 	}
 }
 
+func TestBuildKittyConfig_NextPrev(t *testing.T) {
+	content := BuildKittyConfig(KittyShortcuts{Next: "kitty_mod+shift+j", Prev: "kitty_mod+shift+k", Shell: "/bin/zsh"})
+
+	for _, want := range []string{
+		`map kitty_mod+shift+j launch --type=background /bin/zsh -lc 'exec cc-queue next --from "$1"' cc-queue @active-kitty-window-id`,
+		`map kitty_mod+shift+k launch --type=background /bin/zsh -lc 'exec cc-queue prev --from "$1"' cc-queue @active-kitty-window-id`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing line %q in:\n%s", want, content)
+		}
+	}
+}
+
+func TestParseKittyShortcuts_AllShortcuts(t *testing.T) {
+	want := KittyShortcuts{Picker: "a", First: "b", Next: "c", Prev: "d"}
+	got := ParseKittyShortcuts(BuildKittyConfig(want))
+	if got != want {
+		t.Errorf("ParseKittyShortcuts = %+v, want %+v", got, want)
+	}
+}
+
 func TestParseKittyShortcuts_BothShortcuts(t *testing.T) {
 	content := BuildKittyConfig(testShortcuts)
 	got := ParseKittyShortcuts(content)
@@ -224,6 +224,31 @@ func TestInstallHooks_SessionStartAndEndHooks(t *testing.T) {
 	}
 }
 
+func TestInstallHooks_PreToolUseAndStopHooks(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+
+	if err := InstallHooks(TargetUser); err != nil {
+		t.Fatalf("InstallHooks: %v", err)
+	}
+
+	status, _, err := CheckHooks(TargetUser)
+	if err != nil {
+		t.Fatalf("CheckHooks: %v", err)
+	}
+	if !status.PreToolUse || !status.Stop || !status.SubagentStop || !status.AllInstalled() {
+		t.Errorf("status = %+v, want PreToolUse installed", status)
+	}
+
+	if err := UninstallHooks(TargetUser); err != nil {
+		t.Fatalf("UninstallHooks: %v", err)
+	}
+	status, _, _ = CheckHooks(TargetUser)
+	if status.PreToolUse || status.Stop || status.SubagentStop || status.AnyInstalled() {
+		t.Errorf("status = %+v, want nothing installed", status)
+	}
+}
+
 func TestInstallHooks_ProjectTarget(t *testing.T) {
 	tmp := t.TempDir()
 	// Change to temp dir to test project-level install.
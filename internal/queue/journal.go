@@ -0,0 +1,309 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Journal operations.
+const (
+	OpPush   = "push"   // an event was recorded for a session
+	OpPop    = "pop"    // the user responded and the session went back to work
+	OpTouch  = "touch"  // the session was deprioritized without changing state
+	OpJump   = "jump"   // the user jumped to the session
+	OpRemove = "remove" // the session was removed from the queue
+)
+
+// Removal reasons recorded with OpRemove.
+const (
+	ReasonEnd         = "end"          // SessionEnd hook fired
+	ReasonClear       = "clear"        // cc-queue clear
+	ReasonDedup       = "dedup"        // superseded by a newer session in the same window
+	ReasonStale       = "stale"        // Claude Code process died
+	ReasonStaleWindow = "stale-window" // terminal window closed
+	ReasonDismiss     = "dismiss"      // dismissed from the picker
+)
+
+// JournalRecord is one line of the journal.
+type JournalRecord struct {
+	Time      time.Time `json:"time"`
+	Op        string    `json:"op"`
+	SessionID string    `json:"session_id"`
+	CWD       string    `json:"cwd,omitempty"`
+	Event     string    `json:"event,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Default journal rotation limits.
+const (
+	DefaultJournalMaxSize  = 5 << 20 // bytes
+	DefaultJournalMaxFiles = 3       // rotated files kept besides the active one
+)
+
+// Journal is an append-only JSONL log of queue operations. Unlike session
+// history, it survives session removal. The active file is rotated to
+// <path>.1, <path>.2, ... once it grows past MaxSize.
+type Journal struct {
+	path string
+	// MaxSize is the size in bytes past which the active file is rotated.
+	MaxSize int64
+	// MaxFiles is the number of rotated files kept.
+	MaxFiles int
+}
+
+// NewJournal returns a Journal writing to path with default rotation limits.
+// An empty path means journal.jsonl in Dir(), resolved on every call.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path, MaxSize: DefaultJournalMaxSize, MaxFiles: DefaultJournalMaxFiles}
+}
+
+// Path returns the active journal file path.
+func (j *Journal) Path() string {
+	if j.path != "" {
+		return j.path
+	}
+	return filepath.Join(Dir(), "journal.jsonl")
+}
+
+// Append writes r as one line to the journal, rotating first if needed.
+// A zero r.Time is set to the current time.
+func (j *Journal) Append(r JournalRecord) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	path := j.Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	lf, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+	if err := DefaultLocker.Lock(int(lf.Fd())); err != nil {
+		return err
+	}
+	defer DefaultLocker.Unlock(int(lf.Fd()))
+
+	if fi, err := os.Stat(path); err == nil && j.MaxSize > 0 && fi.Size()+int64(len(line)) > j.MaxSize {
+		j.rotate(path)
+	}
+
+	counts, err := j.readCounts()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(line); err != nil {
+		return err
+	}
+	counts.add(r)
+	return j.writeCounts(counts)
+}
+
+// JournalCounts are running totals of journal records. Unlike the records
+// themselves, they survive rotation, so they only ever go up.
+type JournalCounts struct {
+	// Ops counts records by operation (push, pop, jump, ...).
+	Ops map[string]int `json:"ops"`
+	// Removals counts remove records by reason (end, stale, ...).
+	Removals map[string]int `json:"removals"`
+}
+
+// add counts r.
+func (c *JournalCounts) add(r JournalRecord) {
+	c.Ops[r.Op]++
+	if r.Op == OpRemove {
+		c.Removals[r.Reason]++
+	}
+}
+
+// countsPath is the file holding the running totals, next to the journal.
+func (j *Journal) countsPath() string {
+	return j.Path() + ".counts"
+}
+
+// Counts returns the running totals of the records ever appended. A nil
+// Journal has none.
+func (j *Journal) Counts() (JournalCounts, error) {
+	if j == nil {
+		return JournalCounts{Ops: map[string]int{}, Removals: map[string]int{}}, nil
+	}
+	return j.readCounts()
+}
+
+// readCounts loads the running totals. Journals written before they were
+// kept start from the records still retained.
+func (j *Journal) readCounts() (JournalCounts, error) {
+	c := JournalCounts{Ops: map[string]int{}, Removals: map[string]int{}}
+	data, err := os.ReadFile(j.countsPath())
+	if os.IsNotExist(err) {
+		records, err := j.Read(JournalFilter{})
+		for _, r := range records {
+			c.add(r)
+		}
+		return c, err
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parsing %s: %w", j.countsPath(), err)
+	}
+	if c.Ops == nil {
+		c.Ops = map[string]int{}
+	}
+	if c.Removals == nil {
+		c.Removals = map[string]int{}
+	}
+	return c, nil
+}
+
+// writeCounts atomically replaces the running totals with c.
+func (j *Journal) writeCounts(c JournalCounts) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(j.countsPath(), data, 0644)
+}
+
+// rotate shifts path.N-1 to path.N, ..., path to path.1, dropping the oldest.
+func (j *Journal) rotate(path string) {
+	if j.MaxFiles <= 0 {
+		os.Remove(path)
+		return
+	}
+	os.Remove(fmt.Sprintf("%s.%d", path, j.MaxFiles))
+	for i := j.MaxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	os.Rename(path, path+".1")
+}
+
+// JournalFilter selects journal records. Zero fields match everything.
+type JournalFilter struct {
+	// SessionID matches records whose session ID starts with it.
+	SessionID string
+	// CWD matches records in that directory or below it.
+	CWD string
+	// Event matches the operation (push, pop, ...), the raw event type
+	// (permission_prompt, ...) or its label (PERM, ...), case-insensitively.
+	Event string
+	// Since and Until bound the record time (inclusive, exclusive).
+	Since, Until time.Time
+}
+
+// Match reports whether r passes the filter.
+func (f JournalFilter) Match(r JournalRecord) bool {
+	if f.SessionID != "" && !strings.HasPrefix(r.SessionID, f.SessionID) {
+		return false
+	}
+	if f.CWD != "" {
+		cwd := strings.TrimSuffix(f.CWD, "/")
+		if r.CWD != cwd && !strings.HasPrefix(r.CWD, cwd+"/") {
+			return false
+		}
+	}
+	if f.Event != "" &&
+		!strings.EqualFold(f.Event, r.Op) &&
+		!strings.EqualFold(f.Event, r.Event) &&
+		!(r.Event != "" && strings.EqualFold(f.Event, EventLabel(r.Event))) {
+		return false
+	}
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !r.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Read returns the records matching f, oldest first, across rotated files.
+// Malformed lines are skipped. A nil Journal has no records.
+func (j *Journal) Read(f JournalFilter) ([]JournalRecord, error) {
+	if j == nil {
+		return nil, nil
+	}
+	path := j.Path()
+	files := []string{path}
+	for i := 1; ; i++ {
+		p := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(p); err != nil {
+			break
+		}
+		files = append([]string{p}, files...)
+	}
+
+	var records []JournalRecord
+	for _, p := range files {
+		fh, err := os.Open(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(fh)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var r JournalRecord
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				continue
+			}
+			if f.Match(r) {
+				records = append(records, r)
+			}
+		}
+		err = scanner.Err()
+		fh.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// Record appends an op for e to the journal, stamped at now. For an entry
+// without a message, its end reason is recorded as the message. Failures
+// are logged via Debugf and otherwise ignored so that journaling never
+// breaks a hook. Recording to a nil Journal is a no-op.
+func (j *Journal) Record(op string, e *Entry, reason string, now time.Time) {
+	if j == nil {
+		return
+	}
+	message := e.Message
+	if message == "" {
+		message = e.EndReason
+	}
+	r := JournalRecord{
+		Time:      now,
+		Op:        op,
+		SessionID: e.SessionID,
+		CWD:       e.CWD,
+		Event:     e.Event,
+		Message:   message,
+		Reason:    reason,
+	}
+	if err := j.Append(r); err != nil {
+		Debugf("JOURNAL error op=%s session=%s: %v", op, e.SessionID, err)
+	}
+}
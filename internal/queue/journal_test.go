@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal_AppendAndRead(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	j.Append(JournalRecord{Time: t0, Op: OpPush, SessionID: "s1", CWD: "/p", Event: "permission_prompt"})
+	j.Append(JournalRecord{Time: t0.Add(time.Minute), Op: OpPop, SessionID: "s1", CWD: "/p", Event: "working"})
+
+	records, err := j.Read(JournalFilter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Op != OpPush || records[1].Op != OpPop {
+		t.Errorf("ops = [%s %s], want [push pop]", records[0].Op, records[1].Op)
+	}
+}
+
+func TestJournal_ReadMissingFile(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	records, err := j.Read(JournalFilter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("got %d records, want 0", len(records))
+	}
+}
+
+func TestJournal_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	os.WriteFile(path, []byte("{broken\n"+`{"op":"push","session_id":"s1"}`+"\n"), 0644)
+
+	records, err := NewJournal(path).Read(JournalFilter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) != 1 || records[0].SessionID != "s1" {
+		t.Errorf("records = %v, want only s1", records)
+	}
+}
+
+func TestJournal_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := NewJournal(path)
+	j.MaxSize = 200
+	j.MaxFiles = 2
+
+	for i := 0; i < 20; i++ {
+		j.Append(JournalRecord{Op: OpPush, SessionID: fmt.Sprintf("s%02d", i)})
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected %s.1 to exist: %v", path, err)
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Errorf("expected at most %d rotated files", j.MaxFiles)
+	}
+
+	records, err := j.Read(JournalFilter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) == 0 || len(records) >= 20 {
+		t.Fatalf("got %d records, want some but not all after rotation", len(records))
+	}
+	// Oldest first across rotated files, newest record last.
+	if last := records[len(records)-1].SessionID; last != "s19" {
+		t.Errorf("last record = %q, want s19", last)
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].SessionID < records[i-1].SessionID {
+			t.Errorf("records out of order: %s before %s", records[i-1].SessionID, records[i].SessionID)
+		}
+	}
+}
+
+func TestJournal_CountsSurviveRotation(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	j.MaxSize = 200
+	j.MaxFiles = 1
+
+	for i := 0; i < 20; i++ {
+		j.Append(JournalRecord{Op: OpPush, SessionID: fmt.Sprintf("s%02d", i)})
+	}
+	j.Append(JournalRecord{Op: OpRemove, SessionID: "s00", Reason: ReasonStale})
+
+	c, err := j.Counts()
+	if err != nil {
+		t.Fatalf("Counts: %v", err)
+	}
+	if c.Ops[OpPush] != 20 || c.Ops[OpRemove] != 1 || c.Removals[ReasonStale] != 1 {
+		t.Errorf("Counts = %+v, want 20 pushes and 1 stale removal", c)
+	}
+}
+
+func TestJournal_CountsSeededFromRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	line := `{"op":"push","session_id":"s1"}` + "\n"
+	if err := os.WriteFile(path, []byte(line+line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	j := NewJournal(path)
+
+	j.Append(JournalRecord{Op: OpPush, SessionID: "s2"})
+
+	c, err := j.Counts()
+	if err != nil {
+		t.Fatalf("Counts: %v", err)
+	}
+	if c.Ops[OpPush] != 3 {
+		t.Errorf("pushes = %d, want 3", c.Ops[OpPush])
+	}
+}
+
+func TestJournalFilter_Match(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	r := JournalRecord{Time: t0, Op: OpPush, SessionID: "abc-123", CWD: "/home/u/git/infra", Event: "permission_prompt"}
+
+	tests := []struct {
+		name   string
+		filter JournalFilter
+		want   bool
+	}{
+		{"empty", JournalFilter{}, true},
+		{"session prefix", JournalFilter{SessionID: "abc"}, true},
+		{"other session", JournalFilter{SessionID: "xyz"}, false},
+		{"cwd exact", JournalFilter{CWD: "/home/u/git/infra"}, true},
+		{"cwd parent", JournalFilter{CWD: "/home/u/git/"}, true},
+		{"cwd sibling prefix", JournalFilter{CWD: "/home/u/git/inf"}, false},
+		{"op", JournalFilter{Event: "push"}, true},
+		{"event type", JournalFilter{Event: "permission_prompt"}, true},
+		{"label", JournalFilter{Event: "perm"}, true},
+		{"other label", JournalFilter{Event: "IDLE"}, false},
+		{"since before", JournalFilter{Since: t0.Add(-time.Minute)}, true},
+		{"since after", JournalFilter{Since: t0.Add(time.Minute)}, false},
+		{"until after", JournalFilter{Until: t0.Add(time.Minute)}, true},
+		{"until equal", JournalFilter{Until: t0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(r); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournal_Record(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	j.Record(OpRemove, &Entry{SessionID: "s1", CWD: "/p"}, ReasonEnd, time.Now())
+
+	records, err := j.Read(JournalFilter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) != 1 || records[0].Reason != ReasonEnd {
+		t.Errorf("records = %v, want one end removal", records)
+	}
+}
+
+func TestJournal_NilIsNoop(t *testing.T) {
+	var j *Journal
+	j.Record(OpRemove, &Entry{SessionID: "s1"}, ReasonEnd, time.Now())
+	if records, err := j.Read(JournalFilter{}); err != nil || records != nil {
+		t.Errorf("Read = %v, %v, want nothing", records, err)
+	}
+}
+
+func TestFileStoreList_JournalsDedup(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	s := NewFileStore("")
+	s.Journal = NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	older := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	s.Write(&Entry{SessionID: "old", WindowID: "1", CWD: "/p", Timestamp: older})
+	s.Write(&Entry{SessionID: "new", WindowID: "1", CWD: "/p", Timestamp: older.Add(time.Minute)})
+	s.List()
+
+	records, _ := s.Journal.Read(JournalFilter{Event: OpRemove})
+	if len(records) != 1 || records[0].SessionID != "old" || records[0].Reason != ReasonDedup {
+		t.Errorf("records = %v, want dedup removal of old", records)
+	}
+}
+
+func TestMemStoreList_JournalsDedup(t *testing.T) {
+	s := NewMemStore()
+	s.Journal = NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	older := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	s.Write(&Entry{SessionID: "old", WindowID: "1", CWD: "/p", Timestamp: older})
+	s.Write(&Entry{SessionID: "new", WindowID: "1", CWD: "/p", Timestamp: older.Add(time.Minute)})
+	s.List()
+
+	records, _ := s.Journal.Read(JournalFilter{Event: OpRemove})
+	if len(records) != 1 || records[0].SessionID != "old" || records[0].Reason != ReasonDedup {
+		t.Errorf("records = %v, want dedup removal of old", records)
+	}
+}
@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemStore is an in-memory Store, mainly useful for tests.
+// Entries are copied on the way in and out so callers cannot alias them.
+type MemStore struct {
+	mu       sync.Mutex
+	sessions map[string]*SessionFile
+	// Journal records the duplicates List removes. Nil to skip.
+	Journal *Journal
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{sessions: make(map[string]*SessionFile)}
+}
+
+// Write stores a copy of e as the current entry of its session.
+func (m *MemStore) Write(e *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sf, ok := m.sessions[e.SessionID]
+	if !ok {
+		sf = &SessionFile{}
+		m.sessions[e.SessionID] = sf
+	}
+	c := *e
+	pushCurrent(sf, &c)
+	return nil
+}
+
+// Touch updates the timestamp of an existing session entry.
+func (m *MemStore) Touch(sessionID string, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sf, ok := m.sessions[sessionID]
+	if !ok || sf.Current == nil {
+		return notFound(sessionID)
+	}
+	sf.Current.Timestamp = now
+	return nil
+}
+
+// Update applies fn to a copy of the current entry and keeps it on success.
+func (m *MemStore) Update(sessionID string, fn func(e *Entry) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sf, ok := m.sessions[sessionID]
+	if !ok || sf.Current == nil {
+		return notFound(sessionID)
+	}
+	c := *sf.Current
+	if err := fn(&c); err != nil {
+		return err
+	}
+	sf.Current = &c
+	return nil
+}
+
+// ReadSession returns a copy of the session with the given ID.
+func (m *MemStore) ReadSession(sessionID string) (*SessionFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sf, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, notFound(sessionID)
+	}
+	out := &SessionFile{}
+	if sf.Current != nil {
+		c := *sf.Current
+		out.Current = &c
+	}
+	for _, h := range sf.History {
+		c := *h
+		out.History = append(out.History, &c)
+	}
+	return out, nil
+}
+
+// List returns copies of all current entries, ordered by session ID,
+// deduplicated the same way as FileStore.List.
+func (m *MemStore) List() ([]*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var entries []*Entry
+	for _, id := range ids {
+		if cur := m.sessions[id].Current; cur != nil {
+			c := *cur
+			entries = append(entries, &c)
+		}
+	}
+
+	result, dupes := dedupEntries(entries)
+	for _, d := range dupes {
+		delete(m.sessions, d.stale.SessionID)
+		m.Journal.Record(OpRemove, d.stale, ReasonDedup, time.Now())
+	}
+	return result, nil
+}
+
+// Remove deletes the session with the given ID.
+func (m *MemStore) Remove(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[sessionID]; !ok {
+		return notFound(sessionID)
+	}
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+// RemoveAll deletes every session.
+func (m *MemStore) RemoveAll() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions = make(map[string]*SessionFile)
+	return nil
+}
+
+// notFound returns an error matching fs.ErrNotExist, like FileStore returns
+// for a missing session file.
+func notFound(sessionID string) error {
+	return fmt.Errorf("session %s: %w", sessionID, fs.ErrNotExist)
+}
@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemStore_WriteAndList(t *testing.T) {
+	s := NewMemStore()
+
+	s.Write(&Entry{SessionID: "b", Event: "idle_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "a", Event: "permission_prompt", Timestamp: time.Now()})
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].SessionID != "a" || entries[1].SessionID != "b" {
+		t.Errorf("order = [%s %s], want [a b]", entries[0].SessionID, entries[1].SessionID)
+	}
+}
+
+func TestMemStore_WritePreservesHistory(t *testing.T) {
+	s := NewMemStore()
+
+	s.Write(&Entry{SessionID: "s1", Event: "SessionStart", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s1", Event: "permission_prompt", Timestamp: time.Now()})
+
+	sf, err := s.ReadSession("s1")
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	if sf.Current.Event != "permission_prompt" {
+		t.Errorf("Current.Event = %q, want %q", sf.Current.Event, "permission_prompt")
+	}
+	if len(sf.History) != 1 || sf.History[0].Event != "SessionStart" {
+		t.Errorf("History = %v, want [SessionStart]", sf.History)
+	}
+}
+
+func TestMemStore_ReturnsCopies(t *testing.T) {
+	s := NewMemStore()
+
+	e := &Entry{SessionID: "s1", Event: "idle_prompt", Timestamp: time.Now()}
+	s.Write(e)
+	e.Event = "mutated"
+
+	entries, _ := s.List()
+	entries[0].Event = "also-mutated"
+
+	sf, _ := s.ReadSession("s1")
+	if sf.Current.Event != "idle_prompt" {
+		t.Errorf("Current.Event = %q, want %q", sf.Current.Event, "idle_prompt")
+	}
+}
+
+func TestMemStore_ListDeduplicates(t *testing.T) {
+	s := NewMemStore()
+
+	older := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Minute)
+	s.Write(&Entry{SessionID: "old", WindowID: "1", CWD: "/p", Timestamp: older})
+	s.Write(&Entry{SessionID: "new", WindowID: "1", CWD: "/p", Timestamp: newer})
+
+	entries, _ := s.List()
+	if len(entries) != 1 || entries[0].SessionID != "new" {
+		t.Fatalf("entries = %v, want only new", entries)
+	}
+	if _, err := s.ReadSession("old"); err == nil {
+		t.Error("expected superseded session to be removed")
+	}
+}
+
+func TestMemStore_TouchAndRemoveMissing(t *testing.T) {
+	s := NewMemStore()
+
+	if err := s.Touch("nope", time.Now()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Touch error = %v, want fs.ErrNotExist", err)
+	}
+	if err := s.Remove("nope"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Remove error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestMemStore_Update(t *testing.T) {
+	s := NewMemStore()
+	s.Write(&Entry{SessionID: "a", Event: "working"})
+
+	wantErr := errors.New("boom")
+	if err := s.Update("a", func(e *Entry) error {
+		e.Event = "changed"
+		return wantErr
+	}); err != wantErr {
+		t.Fatalf("Update error = %v, want %v", err, wantErr)
+	}
+	sf, _ := s.ReadSession("a")
+	if sf.Current.Event != "working" {
+		t.Errorf("failed Update leaked change: Event = %q", sf.Current.Event)
+	}
+
+	if err := s.Update("a", func(e *Entry) error {
+		e.Tool = &ToolCall{Name: "Read"}
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	sf, _ = s.ReadSession("a")
+	if sf.Current.Tool == nil || sf.Current.Tool.Name != "Read" {
+		t.Errorf("Tool = %+v, want Read", sf.Current.Tool)
+	}
+
+	if err := s.Update("nope", func(*Entry) error { return nil }); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Update error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestMemStore_RemoveAll(t *testing.T) {
+	s := NewMemStore()
+
+	s.Write(&Entry{SessionID: "s1", Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "s2", Timestamp: time.Now()})
+	if err := s.RemoveAll(); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	entries, _ := s.List()
+	if len(entries) != 0 {
+		t.Errorf("got %d entries after RemoveAll, want 0", len(entries))
+	}
+}
+
+func TestCleanStale_MemStore(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir()) // isolate the journal
+	s := NewMemStore()
+
+	s.Write(&Entry{SessionID: "alive", PID: os.Getpid(), Timestamp: time.Now()})
+	s.Write(&Entry{SessionID: "dead", PID: 999999999, Timestamp: time.Now()})
+
+	removed, err := CleanStale(s, nil)
+	if err != nil {
+		t.Fatalf("CleanStale: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, err := s.ReadSession("alive"); err != nil {
+		t.Errorf("alive session removed: %v", err)
+	}
+}
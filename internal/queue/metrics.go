@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metrics is a snapshot of queue state for monitoring.
+type Metrics struct {
+	// Pending counts the current entries by event label (PERM, IDLE, ...).
+	Pending map[string]int
+	// OldestWait is the age of the oldest entry that needs attention,
+	// or zero when nothing is waiting.
+	OldestWait time.Duration
+	// Sockets counts sessions by terminal socket (kitty listen_on, tmux
+	// server). Sessions without a known socket are counted under "".
+	Sockets map[string]int
+	// Ops counts journal records by operation (push, pop, jump, ...).
+	Ops map[string]int
+	// StaleCleanups counts removals of dead sessions by reason
+	// (stale, stale-window).
+	StaleCleanups map[string]int
+}
+
+// CollectMetrics builds a snapshot from the current entries and the
+// journal's running totals, which survive rotation.
+func CollectMetrics(entries []*Entry, counts JournalCounts, now time.Time) Metrics {
+	m := Metrics{
+		Pending:       make(map[string]int),
+		Sockets:       make(map[string]int),
+		Ops:           make(map[string]int),
+		StaleCleanups: make(map[string]int),
+	}
+	for _, e := range entries {
+		m.Pending[EventLabel(e.Event)]++
+		m.Sockets[e.ListenOn]++
+		if NeedsAttention(e.Event) {
+			m.OldestWait = max(m.OldestWait, now.Sub(e.Timestamp))
+		}
+	}
+	for op, n := range counts.Ops {
+		m.Ops[op] = n
+	}
+	for _, reason := range []string{ReasonStale, ReasonStaleWindow} {
+		if n := counts.Removals[reason]; n > 0 {
+			m.StaleCleanups[reason] = n
+		}
+	}
+	return m
+}
+
+// Exposition formats.
+const (
+	// ContentTypeOpenMetrics is the OpenMetrics 1.0 text format.
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	// ContentTypeText is the classic Prometheus text format, as read by the
+	// node_exporter textfile collector.
+	ContentTypeText = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// metricsWriter emits metric families in either exposition format. The two
+// differ only in how counters are named and in the trailing # EOF marker.
+type metricsWriter struct {
+	buf         bytes.Buffer
+	openMetrics bool
+}
+
+// family writes the HELP and TYPE lines and returns the sample name.
+func (w *metricsWriter) family(name, typ, help string) string {
+	sample := name
+	if typ == "counter" {
+		sample = name + "_total"
+		if !w.openMetrics {
+			name = sample
+		}
+	}
+	fmt.Fprintf(&w.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	return sample
+}
+
+// labeled writes one sample per key of values, in key order.
+func (w *metricsWriter) labeled(sample, label string, values map[string]int) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&w.buf, "%s{%s=\"%s\"} %d\n", sample, label, escapeLabel(k), values[k])
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
+
+// WriteMetrics writes m to out in OpenMetrics format when openMetrics is
+// true, and in the classic Prometheus text format otherwise.
+func WriteMetrics(out io.Writer, m Metrics, openMetrics bool) error {
+	w := &metricsWriter{openMetrics: openMetrics}
+
+	s := w.family("cc_queue_pending_entries", "gauge", "Sessions in the queue by event label.")
+	w.labeled(s, "event", m.Pending)
+
+	s = w.family("cc_queue_oldest_wait_seconds", "gauge", "Age of the oldest entry that needs attention.")
+	fmt.Fprintf(&w.buf, "%s %g\n", s, m.OldestWait.Seconds())
+
+	s = w.family("cc_queue_sessions", "gauge", "Sessions in the queue by terminal socket.")
+	w.labeled(s, "socket", m.Sockets)
+
+	for _, c := range []struct{ op, name, help string }{
+		{OpPush, "cc_queue_pushes", "Events recorded by hooks."},
+		{OpPop, "cc_queue_pops", "Responses that sent a session back to work."},
+		{OpJump, "cc_queue_jumps", "Jumps to a session's window."},
+	} {
+		s = w.family(c.name, "counter", c.help)
+		fmt.Fprintf(&w.buf, "%s %d\n", s, m.Ops[c.op])
+	}
+
+	s = w.family("cc_queue_stale_cleanups", "counter", "Dead sessions removed from the queue by reason.")
+	stale := map[string]int{ReasonStale: 0, ReasonStaleWindow: 0}
+	for k, v := range m.StaleCleanups {
+		stale[k] = v
+	}
+	w.labeled(s, "reason", stale)
+
+	if openMetrics {
+		w.buf.WriteString("# EOF\n")
+	}
+	_, err := out.Write(w.buf.Bytes())
+	return err
+}
+
+// WriteMetricsFile atomically replaces path with m in the classic Prometheus
+// text format, so the node_exporter textfile collector never reads a
+// partially written file.
+func WriteMetricsFile(path string, m Metrics) error {
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, m, false); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectMetrics(t *testing.T) {
+	now := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	entries := []*Entry{
+		{SessionID: "a", Event: "permission_prompt", ListenOn: "unix:/tmp/kitty-1", Timestamp: now.Add(-5 * time.Minute)},
+		{SessionID: "b", Event: "idle_prompt", ListenOn: "unix:/tmp/kitty-1", Timestamp: now.Add(-2 * time.Minute)},
+		{SessionID: "c", Event: "working", ListenOn: "unix:/tmp/kitty-2", Timestamp: now.Add(-time.Hour)},
+		{SessionID: "d", Event: "idle_prompt", Timestamp: now.Add(-time.Minute)},
+	}
+	counts := JournalCounts{
+		Ops:      map[string]int{OpPush: 3, OpPop: 1, OpJump: 2, OpRemove: 3},
+		Removals: map[string]int{ReasonStale: 1, ReasonStaleWindow: 1, ReasonEnd: 1},
+	}
+
+	m := CollectMetrics(entries, counts, now)
+
+	if m.Pending["PERM"] != 1 || m.Pending["IDLE"] != 2 || m.Pending["WORK"] != 1 {
+		t.Errorf("Pending = %v", m.Pending)
+	}
+	// The working entry is older but does not need attention.
+	if m.OldestWait != 5*time.Minute {
+		t.Errorf("OldestWait = %v, want 5m", m.OldestWait)
+	}
+	if m.Sockets["unix:/tmp/kitty-1"] != 2 || m.Sockets["unix:/tmp/kitty-2"] != 1 || m.Sockets[""] != 1 {
+		t.Errorf("Sockets = %v", m.Sockets)
+	}
+	if m.Ops[OpPush] != 3 || m.Ops[OpPop] != 1 || m.Ops[OpJump] != 2 {
+		t.Errorf("Ops = %v", m.Ops)
+	}
+	if m.StaleCleanups[ReasonStale] != 1 || m.StaleCleanups[ReasonStaleWindow] != 1 || len(m.StaleCleanups) != 2 {
+		t.Errorf("StaleCleanups = %v", m.StaleCleanups)
+	}
+}
+
+func TestWriteMetrics_OpenMetrics(t *testing.T) {
+	m := Metrics{
+		Pending:    map[string]int{"PERM": 1, "IDLE": 2},
+		OldestWait: 90 * time.Second,
+		Sockets:    map[string]int{`unix:/tmp/a"b`: 3},
+		Ops:        map[string]int{OpPush: 7},
+	}
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, m, true); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# TYPE cc_queue_pending_entries gauge\n",
+		"cc_queue_pending_entries{event=\"IDLE\"} 2\ncc_queue_pending_entries{event=\"PERM\"} 1\n",
+		"cc_queue_oldest_wait_seconds 90\n",
+		`cc_queue_sessions{socket="unix:/tmp/a\"b"} 3` + "\n",
+		"# TYPE cc_queue_pushes counter\ncc_queue_pushes_total 7\n",
+		"cc_queue_pops_total 0\n",
+		"cc_queue_stale_cleanups_total{reason=\"stale\"} 0\n",
+		"cc_queue_stale_cleanups_total{reason=\"stale-window\"} 0\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "# EOF\n") {
+		t.Errorf("OpenMetrics output must end with # EOF:\n%s", got)
+	}
+}
+
+func TestWriteMetrics_PrometheusText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, Metrics{Ops: map[string]int{OpJump: 4}}, false); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "# TYPE cc_queue_jumps_total counter\ncc_queue_jumps_total 4\n") {
+		t.Errorf("counter family should carry the _total suffix:\n%s", got)
+	}
+	if strings.Contains(got, "# EOF") {
+		t.Errorf("text format must not contain # EOF:\n%s", got)
+	}
+}
+
+func TestWriteMetricsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "textfile", "cc-queue.prom")
+
+	if err := WriteMetricsFile(path, Metrics{Ops: map[string]int{OpPush: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "cc_queue_pushes_total 1\n") {
+		t.Errorf("file content:\n%s", data)
+	}
+	files, _ := os.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("expected only the .prom file, got %d entries", len(files))
+	}
+}
@@ -0,0 +1,59 @@
+package queue
+
+import "fmt"
+
+// Priority levels, from highest to lowest. Entries store "" for normal.
+const (
+	PriorityPinned = "pinned"
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Priorities lists the priority levels from highest to lowest.
+var Priorities = []string{PriorityPinned, PriorityHigh, PriorityNormal, PriorityLow}
+
+// priorityMarks are the one-cell markers shown in front of rows.
+var priorityMarks = map[string]string{
+	PriorityPinned: "⇈",
+	PriorityHigh:   "↑",
+	PriorityLow:    "↓",
+}
+
+// ParsePriority validates a priority level and normalizes normal to "".
+func ParsePriority(s string) (string, error) {
+	switch s {
+	case PriorityPinned, PriorityHigh, PriorityLow:
+		return s, nil
+	case PriorityNormal, "":
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid priority %q (use pinned, high, normal or low)", s)
+}
+
+// PriorityRank orders priorities for the picker: pinned (0) first, low (3)
+// last. Empty and unknown levels count as normal.
+func PriorityRank(p string) int {
+	for i, level := range Priorities {
+		if p == level {
+			return i
+		}
+	}
+	return 2
+}
+
+// ShiftPriority moves a priority up (delta < 0) or down (delta > 0) by one
+// level per step, stopping at pinned and low.
+func ShiftPriority(p string, delta int) string {
+	i := max(0, min(len(Priorities)-1, PriorityRank(p)+delta))
+	level, _ := ParsePriority(Priorities[i])
+	return level
+}
+
+// PriorityMark returns the marker shown for a priority, or " " for normal.
+func PriorityMark(p string) string {
+	if m, ok := priorityMarks[p]; ok {
+		return m
+	}
+	return " "
+}
@@ -0,0 +1,79 @@
+package queue
+
+import "testing"
+
+func TestParsePriority(t *testing.T) {
+	for in, want := range map[string]string{
+		"pinned": "pinned", "high": "high", "normal": "", "": "", "low": "low",
+	} {
+		got, err := ParsePriority(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected error for unknown priority")
+	}
+}
+
+func TestPriorityRank(t *testing.T) {
+	order := []string{"pinned", "high", "", "low"}
+	for i := 1; i < len(order); i++ {
+		if PriorityRank(order[i-1]) >= PriorityRank(order[i]) {
+			t.Errorf("%q should rank before %q", order[i-1], order[i])
+		}
+	}
+	if PriorityRank("bogus") != PriorityRank("") {
+		t.Error("unknown priorities should rank as normal")
+	}
+}
+
+func TestShiftPriority(t *testing.T) {
+	tests := []struct {
+		p     string
+		delta int
+		want  string
+	}{
+		{"", -1, "high"},
+		{"high", -1, "pinned"},
+		{"pinned", -1, "pinned"},
+		{"", 1, "low"},
+		{"low", 1, "low"},
+		{"high", 1, ""},
+	}
+	for _, tt := range tests {
+		if got := ShiftPriority(tt.p, tt.delta); got != tt.want {
+			t.Errorf("ShiftPriority(%q, %d) = %q, want %q", tt.p, tt.delta, got, tt.want)
+		}
+	}
+}
+
+func TestPriorityMark(t *testing.T) {
+	if PriorityMark("") != " " || PriorityMark("pinned") == " " || PriorityMark("low") == " " {
+		t.Error("only normal priority should have a blank mark")
+	}
+}
+
+func TestWriteCarriesOverPriority(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	store := NewFileStore("")
+	if err := store.Write(&Entry{SessionID: "s1", Event: "idle_prompt"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update("s1", func(e *Entry) error {
+		e.Priority = PriorityPinned
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Write(&Entry{SessionID: "s1", Event: "permission_prompt"}); err != nil {
+		t.Fatal(err)
+	}
+	sf, err := store.ReadSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.Current.Priority != PriorityPinned {
+		t.Errorf("Priority = %q, want pinned", sf.Current.Priority)
+	}
+}
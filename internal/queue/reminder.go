@@ -0,0 +1,149 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Reminder is a policy for reminding about sessions that keep waiting on an
+// event, e.g. at 5m and 15m, then every 30m.
+type Reminder struct {
+	// Event is the event type or label (PERM) the policy applies to; empty
+	// applies it to every event needing attention.
+	Event string `json:"event,omitempty"`
+	// At lists the waits to remind at, in increasing order ("5m", "15m").
+	At []string `json:"at,omitempty"`
+	// Every repeats the reminder after the last of At ("30m").
+	Every string `json:"every,omitempty"`
+}
+
+// ReminderSet is a compiled list of reminder policies.
+type ReminderSet struct {
+	policies []reminderPolicy
+}
+
+type reminderPolicy struct {
+	event string
+	at    []time.Duration
+	every time.Duration
+}
+
+// CompileReminders validates reminder policies and parses their durations.
+func CompileReminders(reminders []Reminder) (*ReminderSet, error) {
+	rs := &ReminderSet{}
+	for i, r := range reminders {
+		p := reminderPolicy{event: r.Event}
+		for _, s := range r.At {
+			d, err := ParseDuration(s)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("reminder %d: invalid at %q", i+1, s)
+			}
+			if len(p.at) > 0 && d <= p.at[len(p.at)-1] {
+				return nil, fmt.Errorf("reminder %d: at must be increasing", i+1)
+			}
+			p.at = append(p.at, d)
+		}
+		if r.Every != "" {
+			d, err := ParseDuration(r.Every)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("reminder %d: invalid every %q", i+1, r.Every)
+			}
+			p.every = d
+		}
+		if len(p.at) == 0 && p.every == 0 {
+			return nil, fmt.Errorf("reminder %d: needs at or every", i+1)
+		}
+		rs.policies = append(rs.policies, p)
+	}
+	return rs, nil
+}
+
+// policy returns the first policy for event, or nil.
+func (rs *ReminderSet) policy(event string) *reminderPolicy {
+	if rs == nil || !NeedsAttention(event) {
+		return nil
+	}
+	for i, p := range rs.policies {
+		if p.event == "" || matchEvent(p.event, event) {
+			return &rs.policies[i]
+		}
+	}
+	return nil
+}
+
+// Applies reports whether a policy reminds about sessions waiting on event.
+func (rs *ReminderSet) Applies(event string) bool {
+	return rs.policy(event) != nil
+}
+
+// Step returns how many reminders are due for a session that has waited on
+// event for waited, and the wait at which the next one falls due. next is 0
+// when no policy applies or no reminder is left.
+func (rs *ReminderSet) Step(event string, waited time.Duration) (step int, next time.Duration) {
+	p := rs.policy(event)
+	if p == nil {
+		return 0, 0
+	}
+	for step < len(p.at) && p.at[step] <= waited {
+		step++
+	}
+	if step < len(p.at) {
+		return step, p.at[step]
+	}
+	if p.every == 0 {
+		return step, 0
+	}
+	var last time.Duration
+	if len(p.at) > 0 {
+		last = p.at[len(p.at)-1]
+	}
+	if waited >= last {
+		n := int((waited - last) / p.every)
+		step += n
+		return step, last + time.Duration(n+1)*p.every
+	}
+	return step, last + p.every
+}
+
+// ReminderAck records the last reminder sent for a session's wait, which
+// starts at the entry's WaitingSince.
+type ReminderAck struct {
+	Since time.Time `json:"since"`
+	Step  int       `json:"step"`
+}
+
+// ReminderAcksPath is the file keeping the reminders sent, by session ID.
+// It is not a .json file, so it is not mistaken for a session.
+func ReminderAcksPath() string {
+	return filepath.Join(Dir(), "reminders.state")
+}
+
+// ReadReminderAcks loads the reminders sent. A missing file means none.
+func ReadReminderAcks() (map[string]ReminderAck, error) {
+	acks := make(map[string]ReminderAck)
+	data, err := os.ReadFile(ReminderAcksPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return acks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &acks); err != nil {
+		return nil, err
+	}
+	return acks, nil
+}
+
+// WriteReminderAcks replaces the reminders sent.
+func WriteReminderAcks(acks map[string]ReminderAck) error {
+	data, err := json.Marshal(acks)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(ReminderAcksPath(), data, 0644)
+}
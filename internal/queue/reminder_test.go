@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompileReminders_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Reminder
+	}{
+		{"nothing to remind at", Reminder{Event: "PERM"}},
+		{"bad at", Reminder{At: []string{"soon"}}},
+		{"zero at", Reminder{At: []string{"0m"}}},
+		{"decreasing at", Reminder{At: []string{"15m", "5m"}}},
+		{"bad every", Reminder{Every: "often"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CompileReminders([]Reminder{tt.r}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestReminderSet_Step(t *testing.T) {
+	rs, err := CompileReminders([]Reminder{
+		{Event: "PERM", At: []string{"5m", "15m"}, Every: "30m"},
+		{Event: "ASK", At: []string{"10m"}},
+		{Event: "IDLE", Every: "1h"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := time.Minute
+	tests := []struct {
+		event    string
+		waited   time.Duration
+		wantStep int
+		wantNext time.Duration
+	}{
+		{"permission_prompt", 0, 0, 5 * m},
+		{"permission_prompt", 5 * m, 1, 15 * m},
+		{"permission_prompt", 14 * m, 1, 15 * m},
+		{"permission_prompt", 15 * m, 2, 45 * m},
+		{"permission_prompt", 44 * m, 2, 45 * m},
+		{"permission_prompt", 45 * m, 3, 75 * m},
+		{"permission_prompt", 80 * m, 4, 105 * m},
+		{"elicitation_dialog", 9 * m, 0, 10 * m},
+		{"elicitation_dialog", 3 * time.Hour, 1, 0}, // no more reminders
+		{"idle_prompt", 30 * m, 0, 60 * m},
+		{"idle_prompt", 150 * m, 2, 180 * m},
+		{"Stop", time.Hour, 0, 0},    // no policy
+		{"working", time.Hour, 0, 0}, // not waiting
+	}
+	for _, tt := range tests {
+		step, next := rs.Step(tt.event, tt.waited)
+		if step != tt.wantStep || next != tt.wantNext {
+			t.Errorf("Step(%s, %v) = %d, %v; want %d, %v", tt.event, tt.waited, step, next, tt.wantStep, tt.wantNext)
+		}
+	}
+}
+
+func TestReminderSet_Applies(t *testing.T) {
+	rs, err := CompileReminders([]Reminder{{Event: "PERM", At: []string{"5m"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Applies("permission_prompt") || rs.Applies("idle_prompt") {
+		t.Error("Applies() should follow the policy event")
+	}
+
+	all, err := CompileReminders([]Reminder{{Every: "1h"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !all.Applies("idle_prompt") || !all.Applies("Stop") || all.Applies("working") {
+		t.Error("a policy without event should apply to every event needing attention")
+	}
+
+	var none *ReminderSet
+	if none.Applies("permission_prompt") {
+		t.Error("a nil set applies to nothing")
+	}
+}
+
+func TestReminderAcks_RoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	if err := EnsureDir(); err != nil {
+		t.Fatal(err)
+	}
+
+	acks, err := ReadReminderAcks()
+	if err != nil || len(acks) != 0 {
+		t.Fatalf("ReadReminderAcks() without a file = %v, %v", acks, err)
+	}
+
+	since := time.Date(2026, 2, 18, 14, 0, 0, 0, time.UTC)
+	if err := WriteReminderAcks(map[string]ReminderAck{"a": {Since: since, Step: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	acks, err = ReadReminderAcks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := acks["a"]; !got.Since.Equal(since) || got.Step != 2 {
+		t.Errorf("acks[a] = %+v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Rule adjusts the sort position of the entries it matches. Every condition
+// that is set must match; a rule without conditions matches everything.
+type Rule struct {
+	// Name identifies the rule in `cc-queue rules test`.
+	Name string `json:"name,omitempty"`
+	// Event matches the event type (permission_prompt) or its label (PERM),
+	// case-insensitively.
+	Event string `json:"event,omitempty"`
+	// CWD is a glob on the session's directory. A leading ~ is the home
+	// directory and ** matches any number of directories.
+	CWD string `json:"cwd,omitempty"`
+	// Branch is a glob on the git branch of the session's directory.
+	Branch string `json:"branch,omitempty"`
+	// OlderThan and NewerThan bound the entry's age (90m, 2h, 1d).
+	OlderThan string `json:"older_than,omitempty"`
+	NewerThan string `json:"newer_than,omitempty"`
+	// Message is a regular expression matched against the entry's message.
+	Message string `json:"message,omitempty"`
+	// Rank is the sort key of matching entries: lower sorts first.
+	// Entries no rule matches have rank 0.
+	Rank int `json:"rank"`
+}
+
+// compiledRule is a Rule with its patterns parsed.
+type compiledRule struct {
+	Rule
+	cwd       string
+	olderThan time.Duration
+	newerThan time.Duration
+	message   *regexp.Regexp
+}
+
+// RuleSet is an ordered list of compiled rules. The first matching rule
+// gives an entry its rank.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+// CompileRules validates rules and prepares them for matching.
+func CompileRules(rules []Rule) (*RuleSet, error) {
+	rs := &RuleSet{}
+	for i, r := range rules {
+		c := compiledRule{Rule: r, cwd: expandTilde(r.CWD)}
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		var err error
+		if r.OlderThan != "" {
+			if c.olderThan, err = ParseDuration(r.OlderThan); err != nil {
+				return nil, fmt.Errorf("rule %s: older_than: %w", name, err)
+			}
+		}
+		if r.NewerThan != "" {
+			if c.newerThan, err = ParseDuration(r.NewerThan); err != nil {
+				return nil, fmt.Errorf("rule %s: newer_than: %w", name, err)
+			}
+		}
+		if r.Message != "" {
+			if c.message, err = regexp.Compile(r.Message); err != nil {
+				return nil, fmt.Errorf("rule %s: message: %w", name, err)
+			}
+		}
+		for field, pattern := range map[string]string{"cwd": c.cwd, "branch": r.Branch} {
+			if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+				return nil, fmt.Errorf("rule %s: %s: %w", name, field, err)
+			}
+		}
+		c.Name = name
+		rs.rules = append(rs.rules, c)
+	}
+	return rs, nil
+}
+
+// Rank returns the rank of e at now and the name of the rule that set it,
+// or 0 and "" when no rule matches. branch is called at most once, only if
+// a rule needs the git branch.
+func (rs *RuleSet) Rank(e *Entry, now time.Time, branch func() string) (int, string) {
+	if rs == nil {
+		return 0, ""
+	}
+	var br *string
+	for _, r := range rs.rules {
+		if r.Event != "" && !matchEvent(r.Event, e.Event) {
+			continue
+		}
+		if r.cwd != "" && !MatchGlob(r.cwd, e.CWD) {
+			continue
+		}
+		if r.Branch != "" {
+			if br == nil {
+				b := branch()
+				br = &b
+			}
+			if !MatchGlob(r.Branch, *br) {
+				continue
+			}
+		}
+		age := now.Sub(e.Timestamp)
+		if r.olderThan > 0 && age <= r.olderThan {
+			continue
+		}
+		if r.newerThan > 0 && age >= r.newerThan {
+			continue
+		}
+		if r.message != nil && !r.message.MatchString(e.Message) {
+			continue
+		}
+		return r.Rank, r.Name
+	}
+	return 0, ""
+}
+
+// matchEvent reports whether pattern names event, by type
+// (permission_prompt) or label (PERM), ignoring case.
+func matchEvent(pattern, event string) bool {
+	return strings.EqualFold(pattern, event) || strings.EqualFold(pattern, EventLabel(event))
+}
+
+// MatchGlob reports whether name matches a slash-separated glob pattern.
+// Besides path.Match syntax, a ** segment matches zero or more segments,
+// so ~/git/infra/** matches ~/git/infra and everything below it.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// expandTilde replaces a leading ~ with the user's home directory.
+func expandTilde(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + p[1:]
+		}
+	}
+	return p
+}
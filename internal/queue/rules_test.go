@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"/home/me/git/infra/**", "/home/me/git/infra", true},
+		{"/home/me/git/infra/**", "/home/me/git/infra/gcp/prod", true},
+		{"/home/me/git/infra/**", "/home/me/git/infra-old", false},
+		{"/home/me/git/*", "/home/me/git/api", true},
+		{"/home/me/git/*", "/home/me/git/api/sub", false},
+		{"/home/**/prod", "/home/me/git/infra/prod", true},
+		{"/home/**/prod", "/home/me/git/infra/staging", false},
+		{"feature/*", "feature/login", true},
+		{"main", "master", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompileRules_Errors(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{Name: "bad age", OlderThan: "soon"}, "rule bad age: older_than"},
+		{Rule{NewerThan: "x"}, "rule #1: newer_than"},
+		{Rule{Message: "("}, "rule #1: message"},
+		{Rule{CWD: "/tmp/["}, "rule #1: cwd"},
+	}
+	for _, tt := range tests {
+		_, err := CompileRules([]Rule{tt.rule})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("CompileRules(%+v) err = %v, want %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestRuleSet_Rank(t *testing.T) {
+	now := time.Date(2026, 2, 18, 14, 0, 0, 0, time.UTC)
+	rs, err := CompileRules([]Rule{
+		{Name: "infra perms", Event: "PERM", CWD: "/git/infra/**", Rank: -10},
+		{Name: "stale idle", Event: "idle_prompt", OlderThan: "2h", Rank: 10},
+		{Name: "release", Branch: "release/*", Rank: -5},
+		{Name: "fresh errors", Message: "(?i)error", NewerThan: "10m", Rank: -1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		e        Entry
+		branch   string
+		wantRank int
+		wantRule string
+	}{
+		{"perm in infra", Entry{Event: "permission_prompt", CWD: "/git/infra/prod", Timestamp: now}, "main", -10, "infra perms"},
+		{"perm elsewhere", Entry{Event: "permission_prompt", CWD: "/git/api", Timestamp: now}, "main", 0, ""},
+		{"old idle", Entry{Event: "idle_prompt", CWD: "/git/api", Timestamp: now.Add(-3 * time.Hour)}, "main", 10, "stale idle"},
+		{"recent idle", Entry{Event: "idle_prompt", CWD: "/git/api", Timestamp: now.Add(-time.Hour)}, "main", 0, ""},
+		{"release branch", Entry{Event: "idle_prompt", CWD: "/git/api", Timestamp: now}, "release/1.2", -5, "release"},
+		{"fresh error", Entry{Event: "Stop", Message: "Build ERROR", Timestamp: now.Add(-time.Minute)}, "", -1, "fresh errors"},
+		{"old error", Entry{Event: "Stop", Message: "Build ERROR", Timestamp: now.Add(-time.Hour)}, "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, rule := rs.Rank(&tt.e, now, func() string { return tt.branch })
+			if rank != tt.wantRank || rule != tt.wantRule {
+				t.Errorf("Rank = %d, %q, want %d, %q", rank, rule, tt.wantRank, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestRuleSet_BranchLookedUpOnlyWhenNeeded(t *testing.T) {
+	rs, err := CompileRules([]Rule{{Event: "PERM", Rank: -1}, {Branch: "main", Rank: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	branch := func() string { calls++; return "main" }
+
+	rs.Rank(&Entry{Event: "permission_prompt"}, time.Now(), branch)
+	if calls != 0 {
+		t.Errorf("branch looked up %d times for a rule without branch", calls)
+	}
+	rs.Rank(&Entry{Event: "idle_prompt"}, time.Now(), branch)
+	if calls != 1 {
+		t.Errorf("branch looked up %d times, want 1", calls)
+	}
+}
+
+func TestRuleSet_Nil(t *testing.T) {
+	var rs *RuleSet
+	if rank, rule := rs.Rank(&Entry{}, time.Now(), nil); rank != 0 || rule != "" {
+		t.Errorf("nil RuleSet Rank = %d, %q", rank, rule)
+	}
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"sort"
+	"time"
+)
+
+// Wait is one stretch of time a session spent waiting for a human: from the
+// first attention-needing push until the user responded (pop).
+type Wait struct {
+	SessionID string
+	CWD       string
+	Event     string
+	Start     time.Time
+	End       time.Time
+	Duration  time.Duration
+}
+
+// Waits reconstructs completed waits from journal records, which must be in
+// chronological order. A wait opens on a push whose event needs attention
+// and is attributed to that first event, even if later pushes change the
+// event type (a PERM prompt turning IDLE is still the same wait). It closes
+// on a pop. Waits that end because the session was removed or restarted
+// never got a human response and are dropped.
+func Waits(records []JournalRecord) []Wait {
+	open := make(map[string]*Wait)
+	var waits []Wait
+	for _, r := range records {
+		switch r.Op {
+		case OpPush:
+			if !NeedsAttention(r.Event) {
+				delete(open, r.SessionID)
+				continue
+			}
+			if _, ok := open[r.SessionID]; !ok {
+				open[r.SessionID] = &Wait{SessionID: r.SessionID, CWD: r.CWD, Event: r.Event, Start: r.Time}
+			}
+		case OpPop:
+			if w, ok := open[r.SessionID]; ok {
+				w.End = r.Time
+				w.Duration = r.Time.Sub(w.Start)
+				waits = append(waits, *w)
+				delete(open, r.SessionID)
+			}
+		case OpRemove:
+			delete(open, r.SessionID)
+		}
+	}
+	return waits
+}
+
+// WaitStats aggregates the waits sharing a key.
+type WaitStats struct {
+	Key   string
+	Count int
+	Total time.Duration
+	P50   time.Duration
+	P95   time.Duration
+}
+
+// SummarizeWaits groups waits by key and returns per-group statistics,
+// costliest (largest total) first.
+func SummarizeWaits(waits []Wait, key func(Wait) string) []WaitStats {
+	groups := make(map[string][]time.Duration)
+	for _, w := range waits {
+		k := key(w)
+		groups[k] = append(groups[k], w.Duration)
+	}
+
+	stats := make([]WaitStats, 0, len(groups))
+	for k, ds := range groups {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		var total time.Duration
+		for _, d := range ds {
+			total += d
+		}
+		stats = append(stats, WaitStats{
+			Key:   k,
+			Count: len(ds),
+			Total: total,
+			P50:   percentile(ds, 50),
+			P95:   percentile(ds, 95),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
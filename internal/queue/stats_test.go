@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaits(t *testing.T) {
+	t0 := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return t0.Add(time.Duration(m) * time.Minute) }
+
+	records := []JournalRecord{
+		{Time: at(0), Op: OpPush, SessionID: "a", CWD: "/p", Event: "SessionStart"},
+		{Time: at(1), Op: OpPush, SessionID: "a", CWD: "/p", Event: "permission_prompt"},
+		{Time: at(2), Op: OpPush, SessionID: "a", CWD: "/p", Event: "idle_prompt"},
+		{Time: at(4), Op: OpJump, SessionID: "a", CWD: "/p", Event: "idle_prompt"},
+		{Time: at(5), Op: OpPop, SessionID: "a", CWD: "/p", Event: "working"},
+		// Pop without an open wait is ignored.
+		{Time: at(6), Op: OpPop, SessionID: "b", CWD: "/q", Event: "working"},
+		// Removed before anyone answered: dropped.
+		{Time: at(7), Op: OpPush, SessionID: "c", CWD: "/q", Event: "elicitation_dialog"},
+		{Time: at(9), Op: OpRemove, SessionID: "c", CWD: "/q", Reason: ReasonEnd},
+		{Time: at(10), Op: OpPush, SessionID: "b", CWD: "/q", Event: "idle_prompt"},
+		{Time: at(13), Op: OpPop, SessionID: "b", CWD: "/q", Event: "working"},
+	}
+
+	waits := Waits(records)
+	if len(waits) != 2 {
+		t.Fatalf("got %d waits, want 2: %+v", len(waits), waits)
+	}
+	if w := waits[0]; w.SessionID != "a" || w.Event != "permission_prompt" || w.Duration != 4*time.Minute {
+		t.Errorf("waits[0] = %+v, want a/permission_prompt/4m", w)
+	}
+	if w := waits[1]; w.SessionID != "b" || w.Event != "idle_prompt" || w.Duration != 3*time.Minute {
+		t.Errorf("waits[1] = %+v, want b/idle_prompt/3m", w)
+	}
+}
+
+func TestSummarizeWaits(t *testing.T) {
+	var waits []Wait
+	for _, m := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+		waits = append(waits, Wait{CWD: "/big", Duration: time.Duration(m) * time.Minute})
+	}
+	waits = append(waits, Wait{CWD: "/small", Duration: 30 * time.Second})
+
+	stats := SummarizeWaits(waits, func(w Wait) string { return w.CWD })
+	if len(stats) != 2 {
+		t.Fatalf("got %d groups, want 2", len(stats))
+	}
+	big := stats[0]
+	if big.Key != "/big" {
+		t.Fatalf("first group = %q, want costliest /big", big.Key)
+	}
+	if big.Count != 10 || big.Total != 55*time.Minute {
+		t.Errorf("count/total = %d/%v, want 10/55m", big.Count, big.Total)
+	}
+	if big.P50 != 5*time.Minute {
+		t.Errorf("p50 = %v, want 5m", big.P50)
+	}
+	if big.P95 != 10*time.Minute {
+		t.Errorf("p95 = %v, want 10m", big.P95)
+	}
+	if small := stats[1]; small.P50 != 30*time.Second || small.P95 != 30*time.Second {
+		t.Errorf("single-sample percentiles = %v/%v, want 30s", small.P50, small.P95)
+	}
+}
@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"time"
+)
+
+// Store persists queue entries, one session per key.
+// FileStore is the production implementation; MemStore is an in-memory
+// implementation for tests.
+type Store interface {
+	// Write persists an entry keyed by session ID. The previous current entry
+	// of the session is pushed into history.
+	Write(e *Entry) error
+	// Touch updates the timestamp of an existing session entry without
+	// modifying event, message, or history.
+	Touch(sessionID string, now time.Time) error
+	// Update applies fn to the current entry of an existing session in place,
+	// without pushing history. The change is discarded if fn returns an error.
+	Update(sessionID string, fn func(e *Entry) error) error
+	// ReadSession loads the full session (current + history) by session ID.
+	ReadSession(sessionID string) (*SessionFile, error)
+	// List returns the current entry of every session. When multiple entries
+	// share the same (terminal, window, cwd) tuple, only the most recent one
+	// is returned and the older duplicates are removed.
+	List() ([]*Entry, error)
+	// Remove deletes the session with the given ID.
+	Remove(sessionID string) error
+	// RemoveAll deletes every session.
+	RemoveAll() error
+}
+
+// TouchWhere updates the timestamp of entries match reports true for. This
+// deprioritizes the current session when jumping to another. It is a no-op
+// if no entry matches. Touches are recorded to j.
+func TouchWhere(s Store, j *Journal, match func(e *Entry) bool, now time.Time) error {
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if match(e) {
+			if err := s.Touch(e.SessionID, now); err != nil {
+				return err
+			}
+			j.Record(OpTouch, e, "", now)
+		}
+	}
+	return nil
+}
+
+// CleanStale removes entries whose PID is no longer running, recording the
+// removals to j. Returns the number of entries removed.
+func CleanStale(s Store, j *Journal) (int, error) {
+	entries, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	Debugf("CLEAN_STALE found %d entries", len(entries))
+	removed := 0
+	for _, e := range entries {
+		alive := IsProcessAlive(e.PID)
+		Debugf("CLEAN_STALE session=%s pid=%d alive=%v", e.SessionID, e.PID, alive)
+		if !alive {
+			if err := s.Remove(e.SessionID); err == nil {
+				j.Record(OpRemove, e, ReasonStale, time.Now())
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// CleanStaleWindows removes entries whose window alive reports gone,
+// recording the removals to j. Entries with an empty WindowID are skipped.
+// Returns the number of entries removed.
+func CleanStaleWindows(s Store, j *Journal, alive func(e *Entry) bool) (int, error) {
+	entries, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.WindowID == "" {
+			continue
+		}
+		if !alive(e) {
+			Debugf("CLEAN_STALE_WINDOW session=%s wid=%s", e.SessionID, e.WindowID)
+			if err := s.Remove(e.SessionID); err == nil {
+				j.Record(OpRemove, e, ReasonStaleWindow, time.Now())
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ToolCall describes a tool call reported by the PreToolUse hook.
+type ToolCall struct {
+	Name string `json:"name"`
+	// Summary is the most telling part of the tool input: the Bash command,
+	// the file path, the URL, ...
+	Summary string `json:"summary,omitempty"`
+}
+
+// toolSummaryFields lists, per tool, the input fields tried in order to
+// summarize a call.
+var toolSummaryFields = map[string][]string{
+	"Bash":         {"command"},
+	"Edit":         {"file_path"},
+	"MultiEdit":    {"file_path"},
+	"Write":        {"file_path"},
+	"Read":         {"file_path"},
+	"NotebookEdit": {"notebook_path"},
+	"WebFetch":     {"url"},
+	"WebSearch":    {"query"},
+	"Glob":         {"pattern"},
+	"Grep":         {"pattern"},
+	"Task":         {"description", "prompt"},
+}
+
+// genericSummaryFields are tried for tools not in toolSummaryFields,
+// such as MCP tools.
+var genericSummaryFields = []string{"command", "file_path", "path", "url", "query", "pattern"}
+
+// SummarizeToolInput returns a short description of a tool call's input.
+// Unknown tools fall back to common field names, then to the raw JSON.
+func SummarizeToolInput(name string, input json.RawMessage) string {
+	var fields map[string]any
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	keys, ok := toolSummaryFields[name]
+	if !ok {
+		keys = genericSummaryFields
+	}
+	for _, k := range keys {
+		if v, ok := fields[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	if ok || len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(string(input))
+}
+
+// NewToolCall builds a ToolCall from a PreToolUse payload.
+func NewToolCall(in *PreToolUseInput) *ToolCall {
+	return &ToolCall{Name: in.ToolName, Summary: SummarizeToolInput(in.ToolName, in.ToolInput)}
+}
+
+// OneLine returns "Name: summary" on a single line, truncated to max runes
+// (no limit if max <= 0).
+func (t *ToolCall) OneLine(max int) string {
+	s := t.Name
+	if t.Summary != "" {
+		s += ": " + strings.Join(strings.Fields(t.Summary), " ")
+	}
+	if r := []rune(s); max > 0 && len(r) > max {
+		s = string(r[:max-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSummarizeToolInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		tool  string
+		input string
+		want  string
+	}{
+		{"bash command", "Bash", `{"command":"go test ./...","description":"run tests"}`, "go test ./..."},
+		{"edit path", "Edit", `{"file_path":"/src/main.go","old_string":"a","new_string":"b"}`, "/src/main.go"},
+		{"write path", "Write", `{"file_path":"/src/new.go","content":"package x"}`, "/src/new.go"},
+		{"webfetch url", "WebFetch", `{"url":"https://go.dev","prompt":"summarize"}`, "https://go.dev"},
+		{"notebook", "NotebookEdit", `{"notebook_path":"/n.ipynb"}`, "/n.ipynb"},
+		{"task falls back to prompt", "Task", `{"prompt":"explore"}`, "explore"},
+		{"known tool missing field", "Bash", `{}`, ""},
+		{"mcp generic field", "mcp__gh__get_file", `{"path":"README.md","repo":"x"}`, "README.md"},
+		{"mcp raw json", "mcp__db__query", `{"sql":"select 1"}`, `{"sql":"select 1"}`},
+		{"invalid json", "Bash", `not json`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SummarizeToolInput(tt.tool, json.RawMessage(tt.input)); got != tt.want {
+				t.Errorf("SummarizeToolInput(%s, %s) = %q, want %q", tt.tool, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolCallOneLine(t *testing.T) {
+	tc := &ToolCall{Name: "Bash", Summary: "for f in *; do\n  echo $f\ndone"}
+	if got, want := tc.OneLine(0), "Bash: for f in *; do echo $f done"; got != want {
+		t.Errorf("OneLine(0) = %q, want %q", got, want)
+	}
+	if got, want := tc.OneLine(12), "Bash: for f…"; got != want {
+		t.Errorf("OneLine(12) = %q, want %q", got, want)
+	}
+	if got := (&ToolCall{Name: "Read"}).OneLine(0); got != "Read" {
+		t.Errorf("OneLine without summary = %q, want %q", got, "Read")
+	}
+}
@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// Watch reports changes to the session files in dir until ctx is done.
+// Bursts of changes are coalesced: the channel holds at most one pending
+// signal, so a slow reader reloads once for many writes. The lock file and
+// in-flight temp files are ignored, so listing the store does not trigger
+// a change.
+func Watch(ctx context.Context, dir string) (<-chan struct{}, error) {
+	ch := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	if err := watchDir(ctx, dir, notify); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// isSessionFile reports whether a file name in the store directory is a
+// session file, as opposed to the lock, a temp file or the journal.
+func isSessionFile(name string) bool {
+	return !strings.HasPrefix(name, ".") && filepath.Ext(name) == ".json"
+}
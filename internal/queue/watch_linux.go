@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// watchEvents are the inotify events that change the set or content of
+// session files. FileStore replaces files by rename, so IN_MOVED_TO is what
+// a write looks like.
+const watchEvents = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// watchDir calls notify for every inotify event on a session file in dir.
+func watchDir(ctx context.Context, dir string, notify func()) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, watchEvents); err != nil {
+		syscall.Close(fd)
+		return &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	// A non-blocking fd goes through the runtime poller, so Close unblocks Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+				ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				nameStart := off + syscall.SizeofInotifyEvent
+				off = nameStart + int(ev.Len)
+				if ev.Mask&syscall.IN_Q_OVERFLOW != 0 {
+					notify()
+					continue
+				}
+				name := string(buf[nameStart:min(off, n)])
+				// Names are NUL-padded to the event length.
+				for len(name) > 0 && name[len(name)-1] == 0 {
+					name = name[:len(name)-1]
+				}
+				if isSessionFile(name) {
+					Debugf("WATCH %s mask=%#x", name, ev.Mask)
+					notify()
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build !linux
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// pollInterval is how often watchDir rescans dir where inotify is missing.
+const pollInterval = time.Second
+
+// watchDir polls dir and calls notify when the session files change.
+func watchDir(ctx context.Context, dir string, notify func()) error {
+	last, err := dirSignature(dir)
+	if err != nil {
+		return err
+	}
+	go func() {
+		tick := time.NewTicker(pollInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				sig, err := dirSignature(dir)
+				if err == nil && sig != last {
+					last = sig
+					notify()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// dirSignature summarizes the names, sizes and modification times of the
+// session files in dir.
+func dirSignature(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, de := range entries {
+		if !isSessionFile(de.Name()) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", de.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitSignal(ch <-chan struct{}, d time.Duration) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func TestWatch_SessionWrites(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := Watch(ctx, dir)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	store := NewFileStore(dir)
+	if err := store.Write(&Entry{SessionID: "s1", Event: "permission_prompt"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !waitSignal(ch, 3*time.Second) {
+		t.Fatal("no signal after writing a session")
+	}
+
+	if err := store.Remove("s1"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if !waitSignal(ch, 3*time.Second) {
+		t.Fatal("no signal after removing a session")
+	}
+}
+
+func TestWatch_IgnoresLockAndOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := Watch(ctx, dir)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	store := NewFileStore(dir)
+	if _, err := store.List(); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, lockFileName), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "journal.jsonl"), []byte("{}\n"), 0644)
+	if waitSignal(ch, 1500*time.Millisecond) {
+		t.Fatal("unexpected signal for non-session files")
+	}
+}
+
+func TestWatch_MissingDir(t *testing.T) {
+	if _, err := Watch(context.Background(), filepath.Join(t.TempDir(), "nope")); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
+
+func TestIsSessionFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"abc.json":             true,
+		".lock":                false,
+		".abc.json.tmp-123":    false,
+		"journal.jsonl":        false,
+		"cc-queue.prom":        false,
+		"session_with_id.json": true,
+	} {
+		if got := isSessionFile(name); got != want {
+			t.Errorf("isSessionFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/duboisf/cc-queue/internal/kitty"
+)
+
+// KittyName is the kitty backend's name.
+const KittyName = "kitty"
+
+// kittyWindowVar is set by kitty in the shells of its windows.
+const kittyWindowVar = "KITTY_WINDOW_ID"
+
+// Kitty drives kitty through its remote control, speaking its protocol over
+// the socket when there is one and shelling out to kitty @ otherwise.
+type Kitty struct {
+	Run Runner
+	// Client returns the remote control client for the socket at addr. Nil
+	// always shells out.
+	Client func(addr string) *kitty.Client
+	// Layout switches the current tab to a full-screen layout.
+	Layout kitty.FullTabber
+}
+
+// NewKitty returns the kitty backend, talking to kitty over its socket.
+func NewKitty() *Kitty {
+	return &Kitty{Run: ExecRun, Client: kitty.NewClient, Layout: kitty.NewLayoutManager()}
+}
+
+// Name returns "kitty".
+func (k *Kitty) Name() string { return KittyName }
+
+// Current returns the kitty window of $KITTY_WINDOW_ID, addressed by
+// $KITTY_LISTEN_ON.
+func (k *Kitty) Current() (Pane, bool) {
+	id := os.Getenv(kittyWindowVar)
+	if id == "" {
+		return Pane{}, false
+	}
+	return Pane{ID: id, Addr: os.Getenv("KITTY_LISTEN_ON")}, true
+}
+
+// remote runs a command against the instance at addr: rc over its socket,
+// or args with kitty @ when the socket cannot be reached.
+func (k *Kitty) remote(addr string, rc func(c *kitty.Client) ([]byte, error), args ...string) ([]byte, error) {
+	if k.Client != nil {
+		out, err := rc(k.Client(addr))
+		if !errors.Is(err, kitty.ErrUnreachable) {
+			return out, err
+		}
+	}
+	full := []string{"@"}
+	if addr != "" {
+		full = append(full, "--to", addr)
+	}
+	return k.Run("kitty", append(full, args...)...)
+}
+
+// Focus focuses the window of p.
+func (k *Kitty) Focus(p Pane) error {
+	focus := func(c *kitty.Client) ([]byte, error) { return nil, c.FocusWindow("id:" + p.ID) }
+	if _, err := k.remote(p.Addr, focus, "focus-window", "--match", "id:"+p.ID); err != nil {
+		return fmt.Errorf("kitty focus-window failed: %w", err)
+	}
+	return nil
+}
+
+// LaunchShell opens a window in cwd in the tab of p and focuses it.
+func (k *Kitty) LaunchShell(p Pane, cwd string) error {
+	launch := func(c *kitty.Client) ([]byte, error) {
+		id, err := c.Launch(kitty.LaunchOptions{Type: "window", CWD: cwd, Match: "id:" + p.ID})
+		return []byte(id), err
+	}
+	out, err := k.remote(p.Addr, launch, "launch", "--type=window", "--cwd="+cwd, "--match", "id:"+p.ID)
+	if err != nil {
+		return fmt.Errorf("kitty launch failed: %w", err)
+	}
+	// launch returns the new window ID — focus it.
+	if id := strings.TrimSpace(string(out)); id != "" {
+		_ = k.Focus(Pane{ID: id, Addr: p.Addr})
+	}
+	return nil
+}
+
+// OpenTab opens a tab in cwd in the OS window of p, titled after the
+// project.
+func (k *Kitty) OpenTab(p Pane, cwd string) error {
+	title := filepath.Base(cwd)
+	launch := func(c *kitty.Client) ([]byte, error) {
+		_, err := c.Launch(kitty.LaunchOptions{Type: "tab", CWD: cwd, TabTitle: title, Match: "window_id:" + p.ID})
+		return nil, err
+	}
+	_, err := k.remote(p.Addr, launch, "launch", "--type=tab",
+		"--cwd="+cwd,
+		"--tab-title="+title,
+		"--match", "window_id:"+p.ID)
+	if err != nil {
+		return fmt.Errorf("kitty launch failed: %w", err)
+	}
+	return nil
+}
+
+// LivePanes returns the window IDs of the kitty instance at addr. Without a
+// socket there is no telling which instance a window belonged to, so it
+// fails rather than query whichever kitty is at hand.
+func (k *Kitty) LivePanes(addr string) (map[string]bool, error) {
+	if addr == "" {
+		return nil, errors.New("kitty: no socket to query")
+	}
+	out, err := k.remote(addr, (*kitty.Client).Ls, "ls")
+	if err != nil {
+		return nil, err
+	}
+	return kitty.ParseWindowIDs(out)
+}
+
+// Focused returns the window with the keyboard focus in the instance at
+// addr, or "" when another application has it.
+func (k *Kitty) Focused(addr string) (string, error) {
+	out, err := k.remote(addr, (*kitty.Client).Ls, "ls")
+	if err != nil {
+		return "", err
+	}
+	return kitty.FocusedWindowID(out)
+}
+
+// EnterFullScreen switches the current tab to the stack layout.
+func (k *Kitty) EnterFullScreen() (func(), error) {
+	return k.Layout.EnterFullTab()
+}
@@ -0,0 +1,182 @@
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/duboisf/cc-queue/internal/kitty"
+)
+
+// stubLayout is a kitty.FullTabber that counts calls.
+type stubLayout struct{ entered, restored int }
+
+func (s *stubLayout) EnterFullTab() (func(), error) {
+	s.entered++
+	return func() { s.restored++ }, nil
+}
+
+func TestKitty_Focus(t *testing.T) {
+	r := &recorder{}
+	k := &Kitty{Run: r.run}
+	if err := k.Focus(Pane{ID: "7", Addr: "unix:/tmp/kitty-1"}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{"kitty @ --to unix:/tmp/kitty-1 focus-window --match id:7"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+// listenKitty answers every remote control command on a unix socket with
+// success and returns its address and the commands received.
+func listenKitty(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "kitty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ln, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	cmds := make(chan string, 8)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			msg, _ := bufio.NewReader(conn).ReadString('\\')
+			cmds <- msg
+			conn.Write([]byte("\x1bP@kitty-cmd{\"ok\": true}\x1b\\"))
+			conn.Close()
+		}
+	}()
+	return "unix:" + filepath.Join(dir, "sock"), cmds
+}
+
+func TestKitty_FocusOverSocket(t *testing.T) {
+	addr, cmds := listenKitty(t)
+	r := &recorder{}
+	k := &Kitty{Run: r.run, Client: kitty.NewClient}
+	if err := k.Focus(Pane{ID: "7", Addr: addr}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	if len(r.calls) != 0 {
+		t.Errorf("calls = %q, want none", r.calls)
+	}
+	if msg := <-cmds; !strings.Contains(msg, `"cmd":"focus-window"`) || !strings.Contains(msg, `"match":"id:7"`) {
+		t.Errorf("command = %q, want focus-window on id:7", msg)
+	}
+}
+
+func TestKitty_FallsBackWithoutSocket(t *testing.T) {
+	r := &recorder{}
+	k := &Kitty{Run: r.run, Client: kitty.NewClient}
+	addr := "unix:" + filepath.Join(t.TempDir(), "missing")
+	if err := k.Focus(Pane{ID: "7", Addr: addr}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{"kitty @ --to " + addr + " focus-window --match id:7"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestKitty_FocusError(t *testing.T) {
+	r := &recorder{err: errors.New("no matching windows")}
+	k := &Kitty{Run: r.run}
+	if err := k.Focus(Pane{ID: "7"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestKitty_LaunchShell(t *testing.T) {
+	r := &recorder{out: map[string]string{"kitty @ launch": "12\n"}}
+	k := &Kitty{Run: r.run}
+	if err := k.LaunchShell(Pane{ID: "7"}, "/home/me/api"); err != nil {
+		t.Fatalf("LaunchShell: %v", err)
+	}
+	want := []string{
+		"kitty @ launch --type=window --cwd=/home/me/api --match id:7",
+		"kitty @ focus-window --match id:12",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestKitty_OpenTab(t *testing.T) {
+	r := &recorder{}
+	k := &Kitty{Run: r.run}
+	if err := k.OpenTab(Pane{ID: "7", Addr: "unix:/tmp/kitty-1"}, "/home/me/git/api"); err != nil {
+		t.Fatalf("OpenTab: %v", err)
+	}
+	want := []string{"kitty @ --to unix:/tmp/kitty-1 launch --type=tab --cwd=/home/me/git/api --tab-title=api --match window_id:7"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestKitty_LivePanes(t *testing.T) {
+	r := &recorder{out: map[string]string{"kitty @ --to unix:/tmp/kitty-1 ls": `[{"tabs":[{"windows":[{"id":1},{"id":4}]}]}]`}}
+	k := &Kitty{Run: r.run}
+	got, err := k.LivePanes("unix:/tmp/kitty-1")
+	if err != nil {
+		t.Fatalf("LivePanes: %v", err)
+	}
+	if want := map[string]bool{"1": true, "4": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LivePanes = %v, want %v", got, want)
+	}
+}
+
+func TestKitty_LivePanesWithoutSocket(t *testing.T) {
+	r := &recorder{}
+	k := &Kitty{Run: r.run}
+	if _, err := k.LivePanes(""); err == nil {
+		t.Fatal("expected error without a socket, got nil")
+	}
+	if len(r.calls) != 0 {
+		t.Errorf("calls = %q, want none", r.calls)
+	}
+}
+
+func TestKitty_EnterFullScreen(t *testing.T) {
+	l := &stubLayout{}
+	k := &Kitty{Layout: l}
+	restore, err := k.EnterFullScreen()
+	if err != nil {
+		t.Fatalf("EnterFullScreen: %v", err)
+	}
+	restore()
+	if l.entered != 1 || l.restored != 1 {
+		t.Errorf("entered=%d restored=%d, want 1 and 1", l.entered, l.restored)
+	}
+}
+
+func TestKitty_Focused(t *testing.T) {
+	ls := `[
+		{"is_focused":false,"tabs":[{"is_focused":false,"windows":[{"id":1,"is_focused":false}]}]},
+		{"is_focused":true,"tabs":[
+			{"is_focused":false,"windows":[{"id":2,"is_focused":false}]},
+			{"is_focused":true,"windows":[{"id":3,"is_focused":false},{"id":4,"is_focused":true}]}
+		]}
+	]`
+	r := &recorder{out: map[string]string{"kitty @ ls": ls}}
+	k := &Kitty{Run: r.run}
+	got, err := k.Focused("")
+	if err != nil {
+		t.Fatalf("Focused: %v", err)
+	}
+	if got != "4" {
+		t.Errorf("Focused = %q, want 4", got)
+	}
+}
@@ -0,0 +1,125 @@
+// Package terminal abstracts the terminals and multiplexers Claude Code
+// sessions run in, so cc-queue can find, focus and open windows beside them
+// in kitty, WezTerm, tmux and zellij alike.
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Pane is a window or pane in a terminal.
+type Pane struct {
+	// ID identifies the pane within its terminal (kitty window ID, tmux
+	// pane ID).
+	ID string
+	// Addr locates the terminal: its control socket, or the session name
+	// for zellij; empty for the default.
+	Addr string
+}
+
+// Terminal is a backend that can find and drive the panes sessions run in.
+type Terminal interface {
+	// Name is the backend name recorded in queue entries.
+	Name() string
+	// Current returns the pane this process runs in, from its environment.
+	Current() (Pane, bool)
+	// Focus brings p to the front.
+	Focus(p Pane) error
+	// LaunchShell opens a shell in cwd beside p and focuses it.
+	LaunchShell(p Pane, cwd string) error
+	// OpenTab opens a shell in cwd in a new tab next to p's.
+	OpenTab(p Pane, cwd string) error
+	// LivePanes returns the IDs of the panes alive in the terminal at addr.
+	LivePanes(addr string) (map[string]bool, error)
+	// Focused returns the ID of the pane with the focus in the terminal at
+	// addr, or "" when none has it.
+	Focused(addr string) (string, error)
+	// EnterFullScreen makes the current pane cover its tab and returns a
+	// function that restores the previous layout.
+	EnterFullScreen() (restore func(), err error)
+}
+
+// FullTabber enters a full-screen layout and provides a function to restore
+// the previous one.
+type FullTabber interface {
+	EnterFullTab() (restore func(), err error)
+}
+
+// Runner runs a terminal's CLI and returns its standard output.
+type Runner func(name string, args ...string) ([]byte, error)
+
+// ExecRun is the Runner that executes commands, with their standard error
+// in the error when they fail.
+func ExecRun(name string, args ...string) ([]byte, error) {
+	out, err := exec.Command(name, args...).Output()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return out, fmt.Errorf("%s %s: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(string(ee.Stderr)))
+	}
+	return out, err
+}
+
+// All returns the backends, in detection order: a multiplexer runs inside a
+// terminal window, so its pane is the more precise location.
+func All() []Terminal {
+	return []Terminal{NewTmux(), NewZellij(), NewWezterm(), NewKitty()}
+}
+
+// ByName returns the backend recorded as name, or nil if it is unknown.
+// Entries written before other terminals have no name and are kitty's.
+func ByName(name string) Terminal {
+	if name == "" {
+		name = KittyName
+	}
+	for _, t := range All() {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Detect returns the backend and pane this process runs in.
+func Detect() (Terminal, Pane, bool) {
+	for _, t := range All() {
+		if p, ok := t.Current(); ok {
+			return t, p, true
+		}
+	}
+	return nil, Pane{}, false
+}
+
+// CurrentPaneID returns the ID of the pane this process runs in, or "".
+func CurrentPaneID() string {
+	_, p, _ := Detect()
+	return p.ID
+}
+
+// paneVars are the environment variables backends detect their pane by.
+var paneVars = []string{tmuxPaneVar, zellijPaneVar, weztermPaneVar, kittyWindowVar}
+
+// ForgetCurrent clears the variables Detect reads, for background processes
+// that inherit the pane of the hook that spawned them, which is not where
+// the user is.
+func ForgetCurrent() {
+	for _, v := range paneVars {
+		os.Unsetenv(v)
+	}
+}
+
+// FullScreen implements FullTabber with the terminal this process runs in.
+// It is a no-op outside a known terminal.
+type FullScreen struct{}
+
+// EnterFullTab enters the current terminal's full-screen layout.
+func (FullScreen) EnterFullTab() (func(), error) {
+	t, _, ok := Detect()
+	if !ok {
+		return func() {}, nil
+	}
+	return t.EnterFullScreen()
+}
@@ -0,0 +1,123 @@
+package terminal
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// recorder is a Runner that records commands and replies with err, or the
+// output of the first command prefix they match: the next of seq, then out.
+type recorder struct {
+	calls []string
+	seq   map[string][]string
+	out   map[string]string
+	err   error
+}
+
+func (r *recorder) run(name string, args ...string) ([]byte, error) {
+	call := name + " " + strings.Join(args, " ")
+	r.calls = append(r.calls, call)
+	if r.err != nil {
+		return nil, r.err
+	}
+	for prefix, outs := range r.seq {
+		if strings.HasPrefix(call, prefix) && len(outs) > 0 {
+			r.seq[prefix] = outs[1:]
+			return []byte(outs[0]), nil
+		}
+	}
+	for prefix, out := range r.out {
+		if strings.HasPrefix(call, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name, tmuxPane, tmux, zellijPane, weztermPane, kittyWindow string
+		want                                                       string
+		pane                                                       Pane
+	}{
+		{"none", "", "", "", "", "", "", Pane{}},
+		{"kitty", "", "", "", "", "7", KittyName, Pane{ID: "7", Addr: "unix:/tmp/kitty-1"}},
+		{"wezterm", "", "", "", "4", "", WeztermName, Pane{ID: "4", Addr: "/tmp/wezterm-sock"}},
+		{"tmux", "%3", "/tmp/tmux-1000/default,123,0", "", "", "", TmuxName, Pane{ID: "%3", Addr: "/tmp/tmux-1000/default"}},
+		{"tmux in kitty", "%3", "/tmp/tmux-1000/default,123,0", "", "", "7", TmuxName, Pane{ID: "%3", Addr: "/tmp/tmux-1000/default"}},
+		{"tmux in wezterm", "%3", "/tmp/tmux-1000/default,123,0", "", "4", "", TmuxName, Pane{ID: "%3", Addr: "/tmp/tmux-1000/default"}},
+		{"zellij in kitty", "", "", "2", "", "7", ZellijName, Pane{ID: "2", Addr: "api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMUX_PANE", tt.tmuxPane)
+			t.Setenv("TMUX", tt.tmux)
+			t.Setenv("ZELLIJ_PANE_ID", tt.zellijPane)
+			t.Setenv("ZELLIJ_SESSION_NAME", "api")
+			t.Setenv("WEZTERM_PANE", tt.weztermPane)
+			t.Setenv("WEZTERM_UNIX_SOCKET", "/tmp/wezterm-sock")
+			t.Setenv("KITTY_WINDOW_ID", tt.kittyWindow)
+			t.Setenv("KITTY_LISTEN_ON", "unix:/tmp/kitty-1")
+			term, pane, ok := Detect()
+			if ok != (tt.want != "") {
+				t.Fatalf("ok = %v, want %v", ok, tt.want != "")
+			}
+			if ok && term.Name() != tt.want {
+				t.Errorf("terminal = %q, want %q", term.Name(), tt.want)
+			}
+			if pane != tt.pane {
+				t.Errorf("pane = %+v, want %+v", pane, tt.pane)
+			}
+			if got := CurrentPaneID(); got != tt.pane.ID {
+				t.Errorf("CurrentPaneID = %q, want %q", got, tt.pane.ID)
+			}
+		})
+	}
+}
+
+func TestByName(t *testing.T) {
+	for name, want := range map[string]string{"": KittyName, "kitty": KittyName, "tmux": TmuxName, "wezterm": WeztermName, "zellij": ZellijName} {
+		if got := ByName(name); got == nil || got.Name() != want {
+			t.Errorf("ByName(%q) = %v, want %s", name, got, want)
+		}
+	}
+	if got := ByName("teletype"); got != nil {
+		t.Errorf("ByName(teletype) = %v, want nil", got)
+	}
+}
+
+func TestForgetCurrent(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%3")
+	t.Setenv("ZELLIJ_PANE_ID", "2")
+	t.Setenv("WEZTERM_PANE", "4")
+	t.Setenv("KITTY_WINDOW_ID", "7")
+	ForgetCurrent()
+	if _, _, ok := Detect(); ok {
+		t.Error("Detect found a pane after ForgetCurrent")
+	}
+}
+
+func TestFullScreen_OutsideTerminal(t *testing.T) {
+	t.Setenv("TMUX_PANE", "")
+	t.Setenv("ZELLIJ_PANE_ID", "")
+	t.Setenv("WEZTERM_PANE", "")
+	t.Setenv("KITTY_WINDOW_ID", "")
+	restore, err := FullScreen{}.EnterFullTab()
+	if err != nil {
+		t.Fatalf("EnterFullTab: %v", err)
+	}
+	restore()
+}
+
+func TestExecRun_IncludesStderr(t *testing.T) {
+	_, err := ExecRun("sh", "-c", "echo boom >&2; exit 3")
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("err = %v, want stderr in it", err)
+	}
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) {
+		t.Errorf("err = %v, want the exit error wrapped", err)
+	}
+}
@@ -0,0 +1,143 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TmuxName is the tmux backend's name.
+const TmuxName = "tmux"
+
+// tmuxPaneVar is set by tmux in the shells of its panes.
+const tmuxPaneVar = "TMUX_PANE"
+
+// Tmux drives a tmux server through its CLI.
+type Tmux struct {
+	Run Runner
+}
+
+// NewTmux returns the tmux backend, shelling out to tmux.
+func NewTmux() *Tmux {
+	return &Tmux{Run: ExecRun}
+}
+
+// Name returns "tmux".
+func (t *Tmux) Name() string { return TmuxName }
+
+// Current returns the pane of $TMUX_PANE, on the server whose socket is the
+// first field of $TMUX.
+func (t *Tmux) Current() (Pane, bool) {
+	id := os.Getenv(tmuxPaneVar)
+	if id == "" {
+		return Pane{}, false
+	}
+	sock, _, _ := strings.Cut(os.Getenv("TMUX"), ",")
+	return Pane{ID: id, Addr: sock}, true
+}
+
+// tmux runs a tmux command against the server at addr.
+func (t *Tmux) tmux(addr string, args ...string) ([]byte, error) {
+	if addr != "" {
+		args = append([]string{"-S", addr}, args...)
+	}
+	return t.Run("tmux", args...)
+}
+
+// Focus selects the window and pane of p, then switches the attached client
+// to its session. A server without clients has nothing to switch, which is
+// not an error.
+func (t *Tmux) Focus(p Pane) error {
+	if _, err := t.tmux(p.Addr, "select-window", "-t", p.ID, ";", "select-pane", "-t", p.ID); err != nil {
+		return fmt.Errorf("tmux select-pane failed: %w", err)
+	}
+	_, _ = t.tmux(p.Addr, "switch-client", "-t", p.ID)
+	return nil
+}
+
+// LaunchShell splits the pane of p with a shell in cwd and focuses it.
+func (t *Tmux) LaunchShell(p Pane, cwd string) error {
+	out, err := t.tmux(p.Addr, "split-window", "-t", p.ID, "-c", cwd, "-P", "-F", "#{pane_id}")
+	if err != nil {
+		return fmt.Errorf("tmux split-window failed: %w", err)
+	}
+	if id := strings.TrimSpace(string(out)); id != "" {
+		_ = t.Focus(Pane{ID: id, Addr: p.Addr})
+	}
+	return nil
+}
+
+// OpenTab opens a window in cwd right after the window of p, named after
+// the project. new-window takes a window, not a pane, as its target.
+func (t *Tmux) OpenTab(p Pane, cwd string) error {
+	out, err := t.tmux(p.Addr, "display-message", "-p", "-t", p.ID, "#{window_id}")
+	if err != nil {
+		return fmt.Errorf("tmux display-message failed: %w", err)
+	}
+	// An empty target would open the tab in the client's current window.
+	win := strings.TrimSpace(string(out))
+	if win == "" {
+		return fmt.Errorf("tmux: no window for pane %s", p.ID)
+	}
+	if _, err := t.tmux(p.Addr, "new-window", "-a", "-t", win, "-c", cwd, "-n", filepath.Base(cwd)); err != nil {
+		return fmt.Errorf("tmux new-window failed: %w", err)
+	}
+	return nil
+}
+
+// LivePanes returns the pane IDs of every session on the server at addr.
+func (t *Tmux) LivePanes(addr string) (map[string]bool, error) {
+	out, err := t.tmux(addr, "list-panes", "-a", "-F", "#{pane_id}")
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, id := range strings.Fields(string(out)) {
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// Focused returns the current pane of the most recently active client of
+// the server at addr, or "" when no client is attached. Without a target,
+// tmux would answer for $TMUX_PANE instead.
+func (t *Tmux) Focused(addr string) (string, error) {
+	out, err := t.tmux(addr, "list-clients", "-F", "#{client_activity} #{pane_id}")
+	if err != nil {
+		return "", err
+	}
+	var pane string
+	latest := -1
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		activity, id, ok := strings.Cut(line, " ")
+		if n, err := strconv.Atoi(activity); ok && err == nil && n > latest {
+			latest, pane = n, id
+		}
+	}
+	return pane, nil
+}
+
+// EnterFullScreen zooms the current pane, unless it already is. The
+// returned function unzooms it.
+func (t *Tmux) EnterFullScreen() (func(), error) {
+	noop := func() {}
+	p, ok := t.Current()
+	if !ok {
+		return noop, nil
+	}
+	out, err := t.tmux(p.Addr, "display-message", "-p", "-t", p.ID, "#{window_zoomed_flag}")
+	if err != nil {
+		return noop, err
+	}
+	if strings.TrimSpace(string(out)) == "1" {
+		return noop, nil
+	}
+	if _, err := t.tmux(p.Addr, "resize-pane", "-Z", "-t", p.ID); err != nil {
+		return noop, err
+	}
+	return func() {
+		t.tmux(p.Addr, "resize-pane", "-Z", "-t", p.ID)
+	}, nil
+}
@@ -0,0 +1,153 @@
+package terminal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTmux_Focus(t *testing.T) {
+	r := &recorder{}
+	tm := &Tmux{Run: r.run}
+	if err := tm.Focus(Pane{ID: "%3", Addr: "/tmp/tmux-1000/default"}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{
+		"tmux -S /tmp/tmux-1000/default select-window -t %3 ; select-pane -t %3",
+		"tmux -S /tmp/tmux-1000/default switch-client -t %3",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestTmux_FocusGonePane(t *testing.T) {
+	r := &recorder{err: errors.New("can't find pane: %3")}
+	tm := &Tmux{Run: r.run}
+	if err := tm.Focus(Pane{ID: "%3"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(r.calls) != 1 {
+		t.Errorf("calls = %q, want no switch-client after a failed select", r.calls)
+	}
+}
+
+func TestTmux_LaunchShell(t *testing.T) {
+	r := &recorder{out: map[string]string{"tmux split-window": "%9\n"}}
+	tm := &Tmux{Run: r.run}
+	if err := tm.LaunchShell(Pane{ID: "%3"}, "/home/me/api"); err != nil {
+		t.Fatalf("LaunchShell: %v", err)
+	}
+	want := []string{
+		"tmux split-window -t %3 -c /home/me/api -P -F #{pane_id}",
+		"tmux select-window -t %9 ; select-pane -t %9",
+		"tmux switch-client -t %9",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestTmux_OpenTab(t *testing.T) {
+	r := &recorder{out: map[string]string{"tmux display-message": "@2\n"}}
+	tm := &Tmux{Run: r.run}
+	if err := tm.OpenTab(Pane{ID: "%3"}, "/home/me/git/api"); err != nil {
+		t.Fatalf("OpenTab: %v", err)
+	}
+	want := []string{
+		"tmux display-message -p -t %3 #{window_id}",
+		"tmux new-window -a -t @2 -c /home/me/git/api -n api",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestTmux_OpenTabWithoutWindow(t *testing.T) {
+	r := &recorder{}
+	tm := &Tmux{Run: r.run}
+	if err := tm.OpenTab(Pane{ID: "%3"}, "/home/me/git/api"); err == nil {
+		t.Fatal("expected error without a window ID, got nil")
+	}
+	if want := []string{"tmux display-message -p -t %3 #{window_id}"}; !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestTmux_LivePanes(t *testing.T) {
+	r := &recorder{out: map[string]string{"tmux -S /tmp/s list-panes": "%0\n%3\n%12\n"}}
+	tm := &Tmux{Run: r.run}
+	got, err := tm.LivePanes("/tmp/s")
+	if err != nil {
+		t.Fatalf("LivePanes: %v", err)
+	}
+	if want := map[string]bool{"%0": true, "%3": true, "%12": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LivePanes = %v, want %v", got, want)
+	}
+	if want := "tmux -S /tmp/s list-panes -a -F #{pane_id}"; r.calls[0] != want {
+		t.Errorf("call = %q, want %q", r.calls[0], want)
+	}
+}
+
+func TestTmux_LivePanesError(t *testing.T) {
+	r := &recorder{err: errors.New("no server running")}
+	tm := &Tmux{Run: r.run}
+	if _, err := tm.LivePanes(""); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestTmux_EnterFullScreen(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%3")
+	t.Setenv("TMUX", "")
+	r := &recorder{out: map[string]string{"tmux display-message": "0\n"}}
+	tm := &Tmux{Run: r.run}
+	restore, err := tm.EnterFullScreen()
+	if err != nil {
+		t.Fatalf("EnterFullScreen: %v", err)
+	}
+	restore()
+	want := []string{
+		"tmux display-message -p -t %3 #{window_zoomed_flag}",
+		"tmux resize-pane -Z -t %3",
+		"tmux resize-pane -Z -t %3",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestTmux_EnterFullScreenAlreadyZoomed(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%3")
+	t.Setenv("TMUX", "")
+	r := &recorder{out: map[string]string{"tmux display-message": "1\n"}}
+	tm := &Tmux{Run: r.run}
+	restore, err := tm.EnterFullScreen()
+	if err != nil {
+		t.Fatalf("EnterFullScreen: %v", err)
+	}
+	restore()
+	if len(r.calls) != 1 {
+		t.Errorf("calls = %q, want only the zoom check", r.calls)
+	}
+}
+
+func TestTmux_Focused(t *testing.T) {
+	r := &recorder{out: map[string]string{"tmux -S /tmp/s list-clients": "1700000100 %3\n1700000200 %7\n1700000050 %1\n"}}
+	tm := &Tmux{Run: r.run}
+	got, err := tm.Focused("/tmp/s")
+	if err != nil {
+		t.Fatalf("Focused: %v", err)
+	}
+	if got != "%7" {
+		t.Errorf("Focused = %q, want the most recently active client's %%7", got)
+	}
+}
+
+func TestTmux_FocusedWithoutClients(t *testing.T) {
+	r := &recorder{}
+	tm := &Tmux{Run: r.run}
+	if got, err := tm.Focused(""); err != nil || got != "" {
+		t.Errorf("Focused = %q, %v, want nothing", got, err)
+	}
+}
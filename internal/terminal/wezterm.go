@@ -0,0 +1,171 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeztermName is the WezTerm backend's name.
+const WeztermName = "wezterm"
+
+// weztermPaneVar is set by WezTerm in the shells of its panes.
+const weztermPaneVar = "WEZTERM_PANE"
+
+// Wezterm drives WezTerm through wezterm cli.
+type Wezterm struct {
+	Run Runner
+}
+
+// NewWezterm returns the WezTerm backend, shelling out to wezterm cli.
+func NewWezterm() *Wezterm {
+	return &Wezterm{Run: ExecRun}
+}
+
+// Name returns "wezterm".
+func (w *Wezterm) Name() string { return WeztermName }
+
+// Current returns the pane of $WEZTERM_PANE, on the mux server of
+// $WEZTERM_UNIX_SOCKET.
+func (w *Wezterm) Current() (Pane, bool) {
+	id := os.Getenv(weztermPaneVar)
+	if id == "" {
+		return Pane{}, false
+	}
+	return Pane{ID: id, Addr: os.Getenv("WEZTERM_UNIX_SOCKET")}, true
+}
+
+// cli runs a wezterm cli command against the server at addr. wezterm cli
+// takes its socket from the environment only.
+func (w *Wezterm) cli(addr string, args ...string) ([]byte, error) {
+	args = append([]string{"cli"}, args...)
+	if addr == "" {
+		return w.Run("wezterm", args...)
+	}
+	return w.Run("env", append([]string{"WEZTERM_UNIX_SOCKET=" + addr, "wezterm"}, args...)...)
+}
+
+// Focus activates the pane of p.
+func (w *Wezterm) Focus(p Pane) error {
+	if _, err := w.cli(p.Addr, "activate-pane", "--pane-id", p.ID); err != nil {
+		return fmt.Errorf("wezterm activate-pane failed: %w", err)
+	}
+	return nil
+}
+
+// LaunchShell splits the pane of p with a shell in cwd and focuses it.
+func (w *Wezterm) LaunchShell(p Pane, cwd string) error {
+	out, err := w.cli(p.Addr, "split-pane", "--pane-id", p.ID, "--cwd", cwd)
+	if err != nil {
+		return fmt.Errorf("wezterm split-pane failed: %w", err)
+	}
+	// split-pane prints the new pane ID — focus it.
+	if id := strings.TrimSpace(string(out)); id != "" {
+		_ = w.Focus(Pane{ID: id, Addr: p.Addr})
+	}
+	return nil
+}
+
+// OpenTab spawns a tab in cwd in the window of p, titled after the project.
+func (w *Wezterm) OpenTab(p Pane, cwd string) error {
+	out, err := w.cli(p.Addr, "spawn", "--pane-id", p.ID, "--cwd", cwd)
+	if err != nil {
+		return fmt.Errorf("wezterm spawn failed: %w", err)
+	}
+	if id := strings.TrimSpace(string(out)); id != "" {
+		_, _ = w.cli(p.Addr, "set-tab-title", "--pane-id", id, filepath.Base(cwd))
+	}
+	return nil
+}
+
+// weztermPane is a pane in wezterm cli list --format json.
+type weztermPane struct {
+	PaneID   int  `json:"pane_id"`
+	IsZoomed bool `json:"is_zoomed"`
+}
+
+// list returns the panes of the server at addr.
+func (w *Wezterm) list(addr string) ([]weztermPane, error) {
+	out, err := w.cli(addr, "list", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	var panes []weztermPane
+	if err := json.Unmarshal(out, &panes); err != nil {
+		return nil, fmt.Errorf("parsing wezterm cli list output: %w", err)
+	}
+	return panes, nil
+}
+
+// LivePanes returns the pane IDs of the server at addr.
+func (w *Wezterm) LivePanes(addr string) (map[string]bool, error) {
+	panes, err := w.list(addr)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, p := range panes {
+		ids[strconv.Itoa(p.PaneID)] = true
+	}
+	return ids, nil
+}
+
+// weztermClient is a client in wezterm cli list-clients --format json.
+type weztermClient struct {
+	FocusedPaneID int `json:"focused_pane_id"`
+	IdleTime      struct {
+		Secs  int64 `json:"secs"`
+		Nanos int64 `json:"nanos"`
+	} `json:"idle_time"`
+}
+
+// Focused returns the focused pane of the most recently active client of
+// the server at addr, or "" when no client is connected.
+func (w *Wezterm) Focused(addr string) (string, error) {
+	out, err := w.cli(addr, "list-clients", "--format", "json")
+	if err != nil {
+		return "", err
+	}
+	var clients []weztermClient
+	if err := json.Unmarshal(out, &clients); err != nil {
+		return "", fmt.Errorf("parsing wezterm cli list-clients output: %w", err)
+	}
+	var pane string
+	var idle time.Duration
+	for i, c := range clients {
+		d := time.Duration(c.IdleTime.Secs)*time.Second + time.Duration(c.IdleTime.Nanos)
+		if i == 0 || d < idle {
+			pane, idle = strconv.Itoa(c.FocusedPaneID), d
+		}
+	}
+	return pane, nil
+}
+
+// EnterFullScreen zooms the current pane, unless it already is. The
+// returned function unzooms it.
+func (w *Wezterm) EnterFullScreen() (func(), error) {
+	noop := func() {}
+	p, ok := w.Current()
+	if !ok {
+		return noop, nil
+	}
+	panes, err := w.list(p.Addr)
+	if err != nil {
+		return noop, err
+	}
+	for _, wp := range panes {
+		if strconv.Itoa(wp.PaneID) == p.ID && wp.IsZoomed {
+			return noop, nil
+		}
+	}
+	if _, err := w.cli(p.Addr, "zoom-pane", "--pane-id", p.ID, "--zoom"); err != nil {
+		return noop, err
+	}
+	return func() {
+		w.cli(p.Addr, "zoom-pane", "--pane-id", p.ID, "--unzoom")
+	}, nil
+}
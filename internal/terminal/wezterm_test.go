@@ -0,0 +1,146 @@
+package terminal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// weztermList is recorded wezterm cli list --format json output.
+const weztermList = `[
+  {"window_id":0,"tab_id":0,"pane_id":0,"workspace":"default","size":{"rows":48,"cols":160,"pixel_width":1600,"pixel_height":960,"dpi":96},"title":"claude","cwd":"file:///home/me/git/api","cursor_x":0,"cursor_y":12,"cursor_shape":"Default","cursor_visibility":"Visible","left_col":0,"top_row":0,"tab_title":"","window_title":"wezterm","is_active":true,"is_zoomed":false,"tty_name":"/dev/pts/3"},
+  {"window_id":0,"tab_id":1,"pane_id":4,"workspace":"default","size":{"rows":48,"cols":80,"pixel_width":800,"pixel_height":960,"dpi":96},"title":"zsh","cwd":"file:///home/me","cursor_x":2,"cursor_y":0,"cursor_shape":"Default","cursor_visibility":"Visible","left_col":0,"top_row":0,"tab_title":"","window_title":"wezterm","is_active":false,"is_zoomed":true,"tty_name":"/dev/pts/5"}
+]`
+
+func TestWezterm_Focus(t *testing.T) {
+	r := &recorder{}
+	w := &Wezterm{Run: r.run}
+	if err := w.Focus(Pane{ID: "4"}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{"wezterm cli activate-pane --pane-id 4"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestWezterm_FocusWithSocket(t *testing.T) {
+	r := &recorder{}
+	w := &Wezterm{Run: r.run}
+	if err := w.Focus(Pane{ID: "4", Addr: "/run/user/1000/wezterm/gui-sock-1"}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{"env WEZTERM_UNIX_SOCKET=/run/user/1000/wezterm/gui-sock-1 wezterm cli activate-pane --pane-id 4"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestWezterm_FocusGonePane(t *testing.T) {
+	r := &recorder{err: errors.New("pane 9 not found")}
+	w := &Wezterm{Run: r.run}
+	if err := w.Focus(Pane{ID: "9"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestWezterm_LaunchShell(t *testing.T) {
+	r := &recorder{out: map[string]string{"wezterm cli split-pane": "7\n"}}
+	w := &Wezterm{Run: r.run}
+	if err := w.LaunchShell(Pane{ID: "0"}, "/home/me/git/api"); err != nil {
+		t.Fatalf("LaunchShell: %v", err)
+	}
+	want := []string{
+		"wezterm cli split-pane --pane-id 0 --cwd /home/me/git/api",
+		"wezterm cli activate-pane --pane-id 7",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestWezterm_OpenTab(t *testing.T) {
+	r := &recorder{out: map[string]string{"wezterm cli spawn": "8\n"}}
+	w := &Wezterm{Run: r.run}
+	if err := w.OpenTab(Pane{ID: "0"}, "/home/me/git/api"); err != nil {
+		t.Fatalf("OpenTab: %v", err)
+	}
+	want := []string{
+		"wezterm cli spawn --pane-id 0 --cwd /home/me/git/api",
+		"wezterm cli set-tab-title --pane-id 8 api",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestWezterm_LivePanes(t *testing.T) {
+	r := &recorder{out: map[string]string{"wezterm cli list": weztermList}}
+	w := &Wezterm{Run: r.run}
+	got, err := w.LivePanes("")
+	if err != nil {
+		t.Fatalf("LivePanes: %v", err)
+	}
+	if want := map[string]bool{"0": true, "4": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LivePanes = %v, want %v", got, want)
+	}
+}
+
+func TestWezterm_LivePanesInvalidJSON(t *testing.T) {
+	r := &recorder{out: map[string]string{"wezterm cli list": "not json"}}
+	w := &Wezterm{Run: r.run}
+	if _, err := w.LivePanes(""); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestWezterm_EnterFullScreen(t *testing.T) {
+	t.Setenv("WEZTERM_PANE", "0")
+	t.Setenv("WEZTERM_UNIX_SOCKET", "")
+	r := &recorder{out: map[string]string{"wezterm cli list": weztermList}}
+	w := &Wezterm{Run: r.run}
+	restore, err := w.EnterFullScreen()
+	if err != nil {
+		t.Fatalf("EnterFullScreen: %v", err)
+	}
+	restore()
+	want := []string{
+		"wezterm cli list --format json",
+		"wezterm cli zoom-pane --pane-id 0 --zoom",
+		"wezterm cli zoom-pane --pane-id 0 --unzoom",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestWezterm_EnterFullScreenAlreadyZoomed(t *testing.T) {
+	t.Setenv("WEZTERM_PANE", "4")
+	t.Setenv("WEZTERM_UNIX_SOCKET", "")
+	r := &recorder{out: map[string]string{"wezterm cli list": weztermList}}
+	w := &Wezterm{Run: r.run}
+	restore, err := w.EnterFullScreen()
+	if err != nil {
+		t.Fatalf("EnterFullScreen: %v", err)
+	}
+	restore()
+	if len(r.calls) != 1 {
+		t.Errorf("calls = %q, want only the list", r.calls)
+	}
+}
+
+func TestWezterm_Focused(t *testing.T) {
+	clients := `[
+		{"pid":1,"idle_time":{"secs":120,"nanos":0},"focused_pane_id":2},
+		{"pid":2,"idle_time":{"secs":0,"nanos":5000},"focused_pane_id":5}
+	]`
+	r := &recorder{out: map[string]string{"wezterm cli list-clients": clients}}
+	w := &Wezterm{Run: r.run}
+	got, err := w.Focused("")
+	if err != nil {
+		t.Fatalf("Focused: %v", err)
+	}
+	if got != "5" {
+		t.Errorf("Focused = %q, want the least idle client's 5", got)
+	}
+}
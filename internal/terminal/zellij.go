@@ -0,0 +1,236 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ZellijName is the zellij backend's name.
+const ZellijName = "zellij"
+
+// zellijPaneVar is set by zellij in the shells of its panes.
+const zellijPaneVar = "ZELLIJ_PANE_ID"
+
+// zellijMaxPanes bounds the panes Focus cycles through in a tab.
+const zellijMaxPanes = 64
+
+// zellijSessionVar is set by zellij in the shells of its panes.
+const zellijSessionVar = "ZELLIJ_SESSION_NAME"
+
+// errPanesUnknown is returned by LivePanes when the session is up but its
+// panes cannot be listed, so none of them can be told stale.
+var errPanesUnknown = errors.New("zellij: panes cannot be listed")
+
+// Zellij drives zellij sessions through zellij action. A Pane's Addr is its
+// session name.
+type Zellij struct {
+	Run Runner
+	// Proc is the process table the live panes are found in, /proc. Empty
+	// when there is none to read.
+	Proc string
+}
+
+// NewZellij returns the zellij backend, shelling out to zellij.
+func NewZellij() *Zellij {
+	return &Zellij{Run: ExecRun, Proc: "/proc"}
+}
+
+// Name returns "zellij".
+func (z *Zellij) Name() string { return ZellijName }
+
+// Current returns the pane of $ZELLIJ_PANE_ID in the session of
+// $ZELLIJ_SESSION_NAME.
+func (z *Zellij) Current() (Pane, bool) {
+	id := os.Getenv(zellijPaneVar)
+	if id == "" {
+		return Pane{}, false
+	}
+	return Pane{ID: id, Addr: os.Getenv(zellijSessionVar)}, true
+}
+
+// action runs a zellij action in session.
+func (z *Zellij) action(session string, args ...string) ([]byte, error) {
+	args = append([]string{"action"}, args...)
+	if session != "" {
+		args = append([]string{"--session", session}, args...)
+	}
+	return z.Run("zellij", args...)
+}
+
+// focused returns the pane the session's first client has focused, as
+// list-clients shows it (terminal_3).
+func (z *Zellij) focused(session string) (string, error) {
+	out, err := z.action(session, "list-clients")
+	if err != nil {
+		return "", err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Scan() // CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND
+	for sc.Scan() {
+		if f := strings.Fields(sc.Text()); len(f) >= 2 {
+			return f[1], nil
+		}
+	}
+	return "", errors.New("zellij: no client attached")
+}
+
+// Focus walks the session's tabs and their panes until the pane of p has
+// the focus. zellij action cannot focus a pane by ID, so this is the only
+// way there.
+func (z *Zellij) Focus(p Pane) error {
+	if err := z.focus(p); err != nil {
+		return fmt.Errorf("zellij focus failed: %w", err)
+	}
+	return nil
+}
+
+func (z *Zellij) focus(p Pane) error {
+	want := "terminal_" + p.ID
+	if cur, err := z.focused(p.Addr); err != nil || cur == want {
+		return err
+	}
+	out, err := z.action(p.Addr, "query-tab-names")
+	if err != nil {
+		return err
+	}
+	tabs := strings.Count(strings.TrimSpace(string(out)), "\n") + 1
+	for i := 1; i <= tabs; i++ {
+		if _, err := z.action(p.Addr, "go-to-tab", strconv.Itoa(i)); err != nil {
+			return err
+		}
+		first, err := z.focused(p.Addr)
+		if err != nil || first == want {
+			return err
+		}
+		for range zellijMaxPanes {
+			if _, err := z.action(p.Addr, "focus-next-pane"); err != nil {
+				return err
+			}
+			cur, err := z.focused(p.Addr)
+			if err != nil || cur == want {
+				return err
+			}
+			if cur == first {
+				break
+			}
+		}
+	}
+	return fmt.Errorf("pane %s not found in session %s", p.ID, p.Addr)
+}
+
+// LaunchShell focuses the pane of p, then opens a pane beside it in cwd.
+func (z *Zellij) LaunchShell(p Pane, cwd string) error {
+	if err := z.Focus(p); err != nil {
+		return err
+	}
+	if _, err := z.action(p.Addr, "new-pane", "--cwd", cwd); err != nil {
+		return fmt.Errorf("zellij new-pane failed: %w", err)
+	}
+	return nil
+}
+
+// OpenTab opens a tab in cwd in the session of p, named after the project.
+func (z *Zellij) OpenTab(p Pane, cwd string) error {
+	if _, err := z.action(p.Addr, "new-tab", "--cwd", cwd, "--name", filepath.Base(cwd)); err != nil {
+		return fmt.Errorf("zellij new-tab failed: %w", err)
+	}
+	return nil
+}
+
+// LivePanes returns the panes of session. zellij cannot list them, so they
+// are the panes the processes in Proc were started in; when session exited
+// or is gone, every pane of it is, and the set is empty.
+func (z *Zellij) LivePanes(session string) (map[string]bool, error) {
+	if session == "" {
+		return nil, errors.New("zellij: no session to query")
+	}
+	out, err := z.Run("zellij", "list-sessions", "--no-formatting")
+	if err != nil && strings.Contains(err.Error(), "No active zellij sessions") {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		f := strings.Fields(line)
+		if len(f) > 0 && f[0] == session && !strings.Contains(line, "EXITED") {
+			return z.procPanes(session)
+		}
+	}
+	return map[string]bool{}, nil
+}
+
+// procPanes returns the panes of session that processes in Proc run in,
+// read from their $ZELLIJ_PANE_ID. A running session has a process in at
+// least one pane, so finding none means the processes cannot be read.
+func (z *Zellij) procPanes(session string) (map[string]bool, error) {
+	if z.Proc == "" {
+		return nil, errPanesUnknown
+	}
+	dirs, err := os.ReadDir(z.Proc)
+	if err != nil {
+		return nil, errPanesUnknown
+	}
+	ids := make(map[string]bool)
+	for _, d := range dirs {
+		if _, err := strconv.Atoi(d.Name()); err != nil {
+			continue
+		}
+		environ, err := os.ReadFile(filepath.Join(z.Proc, d.Name(), "environ"))
+		if err != nil {
+			continue // exited, or another user's
+		}
+		var id, name string
+		for _, kv := range bytes.Split(environ, []byte{0}) {
+			switch k, v, _ := strings.Cut(string(kv), "="); k {
+			case zellijPaneVar:
+				id = v
+			case zellijSessionVar:
+				name = v
+			}
+		}
+		if id != "" && name == session {
+			ids[id] = true
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errPanesUnknown
+	}
+	return ids, nil
+}
+
+// Focused returns the pane the session's client has focused, or "" when it
+// is a plugin pane.
+func (z *Zellij) Focused(session string) (string, error) {
+	cur, err := z.focused(session)
+	if err != nil {
+		return "", err
+	}
+	id, ok := strings.CutPrefix(cur, "terminal_")
+	if !ok {
+		return "", nil
+	}
+	return id, nil
+}
+
+// EnterFullScreen makes the focused pane full-screen. zellij does not tell
+// whether it already is, so the returned function simply toggles it back.
+func (z *Zellij) EnterFullScreen() (func(), error) {
+	noop := func() {}
+	p, ok := z.Current()
+	if !ok {
+		return noop, nil
+	}
+	if _, err := z.action(p.Addr, "toggle-fullscreen"); err != nil {
+		return noop, err
+	}
+	return func() {
+		z.action(p.Addr, "toggle-fullscreen")
+	}, nil
+}
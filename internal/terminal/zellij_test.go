@@ -0,0 +1,224 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// zellijClients is list-clients output with the first client on pane id.
+func zellijClients(id string) string {
+	return "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n1         " + id + "     claude\n"
+}
+
+func TestZellij_FocusAlreadyFocused(t *testing.T) {
+	r := &recorder{out: map[string]string{"zellij --session api action list-clients": zellijClients("terminal_3")}}
+	z := &Zellij{Run: r.run}
+	if err := z.Focus(Pane{ID: "3", Addr: "api"}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{"zellij --session api action list-clients"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestZellij_FocusWalksTabsAndPanes(t *testing.T) {
+	r := &recorder{
+		out: map[string]string{"zellij action query-tab-names": "Tab #1\nTab #2\n"},
+		seq: map[string][]string{"zellij action list-clients": {
+			zellijClients("terminal_0"), // where the user is
+			zellijClients("terminal_0"), // tab 1
+			zellijClients("terminal_1"),
+			zellijClients("terminal_0"), // back to the first pane
+			zellijClients("terminal_2"), // tab 2
+			zellijClients("terminal_5"),
+		}},
+	}
+	z := &Zellij{Run: r.run}
+	if err := z.Focus(Pane{ID: "5"}); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	want := []string{
+		"zellij action list-clients",
+		"zellij action query-tab-names",
+		"zellij action go-to-tab 1",
+		"zellij action list-clients",
+		"zellij action focus-next-pane",
+		"zellij action list-clients",
+		"zellij action focus-next-pane",
+		"zellij action list-clients",
+		"zellij action go-to-tab 2",
+		"zellij action list-clients",
+		"zellij action focus-next-pane",
+		"zellij action list-clients",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q\nwant %q", r.calls, want)
+	}
+}
+
+func TestZellij_FocusGonePane(t *testing.T) {
+	r := &recorder{out: map[string]string{
+		"zellij action query-tab-names": "Tab #1\n",
+		"zellij action list-clients":    zellijClients("terminal_0"),
+	}}
+	z := &Zellij{Run: r.run}
+	if err := z.Focus(Pane{ID: "9"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestZellij_FocusNoClient(t *testing.T) {
+	r := &recorder{out: map[string]string{"zellij action list-clients": "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n"}}
+	z := &Zellij{Run: r.run}
+	if err := z.Focus(Pane{ID: "3"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestZellij_LaunchShell(t *testing.T) {
+	r := &recorder{out: map[string]string{"zellij --session api action list-clients": zellijClients("terminal_3")}}
+	z := &Zellij{Run: r.run}
+	if err := z.LaunchShell(Pane{ID: "3", Addr: "api"}, "/home/me/git/api"); err != nil {
+		t.Fatalf("LaunchShell: %v", err)
+	}
+	want := []string{
+		"zellij --session api action list-clients",
+		"zellij --session api action new-pane --cwd /home/me/git/api",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestZellij_OpenTab(t *testing.T) {
+	r := &recorder{}
+	z := &Zellij{Run: r.run}
+	if err := z.OpenTab(Pane{ID: "3", Addr: "api"}, "/home/me/git/api"); err != nil {
+		t.Fatalf("OpenTab: %v", err)
+	}
+	want := []string{"zellij --session api action new-tab --cwd /home/me/git/api --name api"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestZellij_LivePanes(t *testing.T) {
+	sessions := "api [Created 2h ago]\nold [Created 3d ago] (EXITED - attach to resurrect)\n"
+	tests := []struct {
+		session string
+		out     string
+		err     error
+		gone    bool
+	}{
+		{"api", sessions, nil, false},
+		{"old", sessions, nil, true},
+		{"web", sessions, nil, true},
+		{"api", "", errors.New("zellij list-sessions: exit status 1\nNo active zellij sessions found."), true},
+		{"api", "", errors.New("zellij: command not found"), false},
+		{"", sessions, nil, false},
+	}
+	for _, tt := range tests {
+		r := &recorder{out: map[string]string{"zellij list-sessions": tt.out}, err: tt.err}
+		z := &Zellij{Run: r.run}
+		ids, err := z.LivePanes(tt.session)
+		if tt.gone && (err != nil || ids == nil || len(ids) != 0) {
+			t.Errorf("LivePanes(%q) = %v, %v; want an empty set", tt.session, ids, err)
+		}
+		if !tt.gone && err == nil {
+			t.Errorf("LivePanes(%q) = %v; want an error, the panes are unknown", tt.session, ids)
+		}
+	}
+}
+
+// fakeProc writes a process table with the given environments, one
+// process each, and returns its root.
+func fakeProc(t *testing.T, environs ...[]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for i, env := range environs {
+		dir := filepath.Join(root, strconv.Itoa(100+i))
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		data := strings.Join(env, "\x00") + "\x00"
+		if err := os.WriteFile(filepath.Join(dir, "environ"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not a process.
+	if err := os.Mkdir(filepath.Join(root, "self"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestZellij_LivePanesFromProc(t *testing.T) {
+	r := &recorder{out: map[string]string{"zellij list-sessions": "api [Created 2h ago]\nweb [Created 1h ago]\n"}}
+	proc := fakeProc(t,
+		[]string{"HOME=/home/me", "ZELLIJ_SESSION_NAME=api", "ZELLIJ_PANE_ID=0"},
+		[]string{"ZELLIJ_PANE_ID=3", "ZELLIJ_SESSION_NAME=api"},
+		[]string{"ZELLIJ_PANE_ID=3", "ZELLIJ_SESSION_NAME=api"}, // claude in the shell of pane 3
+		[]string{"ZELLIJ_PANE_ID=5", "ZELLIJ_SESSION_NAME=web"},
+		[]string{"HOME=/home/me"},
+	)
+	z := &Zellij{Run: r.run, Proc: proc}
+
+	ids, err := z.LivePanes("api")
+	if err != nil {
+		t.Fatalf("LivePanes: %v", err)
+	}
+	if want := map[string]bool{"0": true, "3": true}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("LivePanes = %v, want %v", ids, want)
+	}
+}
+
+func TestZellij_LivePanesUnreadable(t *testing.T) {
+	r := &recorder{out: map[string]string{"zellij list-sessions": "api [Created 2h ago]\n"}}
+	for name, proc := range map[string]string{
+		"no process table": filepath.Join(t.TempDir(), "proc"),
+		"no process of it": fakeProc(t, []string{"ZELLIJ_PANE_ID=1", "ZELLIJ_SESSION_NAME=web"}),
+	} {
+		z := &Zellij{Run: r.run, Proc: proc}
+		if ids, err := z.LivePanes("api"); err == nil {
+			t.Errorf("%s: LivePanes = %v; want an error, the panes are unknown", name, ids)
+		}
+	}
+}
+
+func TestZellij_EnterFullScreen(t *testing.T) {
+	t.Setenv("ZELLIJ_PANE_ID", "3")
+	t.Setenv("ZELLIJ_SESSION_NAME", "api")
+	r := &recorder{}
+	z := &Zellij{Run: r.run}
+	restore, err := z.EnterFullScreen()
+	if err != nil {
+		t.Fatalf("EnterFullScreen: %v", err)
+	}
+	restore()
+	want := []string{
+		"zellij --session api action toggle-fullscreen",
+		"zellij --session api action toggle-fullscreen",
+	}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+}
+
+func TestZellij_Focused(t *testing.T) {
+	r := &recorder{out: map[string]string{"zellij --session api action list-clients": zellijClients("terminal_3")}}
+	z := &Zellij{Run: r.run}
+	if got, err := z.Focused("api"); err != nil || got != "3" {
+		t.Errorf("Focused = %q, %v, want 3", got, err)
+	}
+
+	r.out["zellij --session api action list-clients"] = zellijClients("plugin_1")
+	if got, err := z.Focused("api"); err != nil || got != "" {
+		t.Errorf("Focused on a plugin = %q, %v, want nothing", got, err)
+	}
+}
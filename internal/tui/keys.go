@@ -0,0 +1,100 @@
+package tui
+
+import "unicode/utf8"
+
+// escapeKeys maps escape sequences to key names.
+var escapeKeys = map[string]string{
+	"\x1b[A":  "up",
+	"\x1b[B":  "down",
+	"\x1b[C":  "right",
+	"\x1b[D":  "left",
+	"\x1bOA":  "up",
+	"\x1bOB":  "down",
+	"\x1bOC":  "right",
+	"\x1bOD":  "left",
+	"\x1b[H":  "home",
+	"\x1b[F":  "end",
+	"\x1bOH":  "home",
+	"\x1bOF":  "end",
+	"\x1b[1~": "home",
+	"\x1b[4~": "end",
+	"\x1b[3~": "del",
+	"\x1b[5~": "pgup",
+	"\x1b[6~": "pgdn",
+	"\x1b[Z":  "btab",
+}
+
+// ParseKeys decodes raw terminal input into key names using fzf's naming:
+// "enter", "tab", "esc", "backspace", "up", "ctrl-r", ... Printable input
+// is returned one character per key. Unknown escape sequences are dropped.
+func ParseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		c := b[0]
+		switch {
+		case c == 0x1b:
+			if len(b) == 1 {
+				return append(keys, "esc")
+			}
+			n := escapeLen(b)
+			if k, ok := escapeKeys[string(b[:n])]; ok {
+				keys = append(keys, k)
+			} else if n == 2 {
+				keys = append(keys, "alt-"+string(b[1]))
+			}
+			b = b[n:]
+			continue
+		case c == '\r':
+			// Raw mode leaves ICRNL off, so Enter is CR and ctrl-j stays LF.
+			keys = append(keys, "enter")
+		case c == '\t':
+			keys = append(keys, "tab")
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, "backspace")
+		case c == 0x00:
+			keys = append(keys, "ctrl-space")
+		case c <= 0x1a:
+			keys = append(keys, "ctrl-"+string(rune('a'+c-1)))
+		case c < 0x20:
+			// ctrl-\, ctrl-], ... have no use here.
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r != utf8.RuneError || size > 1 {
+				keys = append(keys, string(r))
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeLen returns the length of the escape sequence at the start of b.
+// CSI sequences (ESC [) end at a byte in 0x40..0x7e; SS3 sequences (ESC O)
+// are three bytes; anything else is ESC plus one byte.
+func escapeLen(b []byte) int {
+	if len(b) < 2 {
+		return len(b)
+	}
+	switch b[1] {
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(b)
+	case 'O':
+		return min(3, len(b))
+	default:
+		return 2
+	}
+}
+
+// IsPrintable reports whether key is a single printable character rather
+// than a named key.
+func IsPrintable(key string) bool {
+	r, size := utf8.DecodeRuneInString(key)
+	return size > 0 && size == len(key) && r >= ' ' && r != 0x7f && r != utf8.RuneError
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"printable", "ab", []string{"a", "b"}},
+		{"utf8", "é", []string{"é"}},
+		{"enter", "\r", []string{"enter"}},
+		{"ctrl-j is not enter", "\n", []string{"ctrl-j"}},
+		{"tab", "\t", []string{"tab"}},
+		{"backspace", "\x7f", []string{"backspace"}},
+		{"ctrl-r", "\x12", []string{"ctrl-r"}},
+		{"lone esc", "\x1b", []string{"esc"}},
+		{"arrows", "\x1b[A\x1b[B", []string{"up", "down"}},
+		{"ss3 arrows", "\x1bOA", []string{"up"}},
+		{"page keys", "\x1b[5~\x1b[6~", []string{"pgup", "pgdn"}},
+		{"alt", "\x1bx", []string{"alt-x"}},
+		{"unknown csi dropped", "\x1b[99;5Xa", []string{"a"}},
+		{"mixed", "q\x1b[Bz\r", []string{"q", "down", "z", "enter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeys(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPrintable(t *testing.T) {
+	for key, want := range map[string]bool{
+		"a": true, "é": true, " ": true,
+		"enter": false, "ctrl-r": false, "up": false, "\x7f": false, "": false,
+	} {
+		if got := IsPrintable(key); got != want {
+			t.Errorf("IsPrintable(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Item is one selectable line of the picker.
+type Item struct {
+	// ID identifies the item across reloads (e.g. a session ID).
+	ID string
+	// Text is the display line, also used for filtering.
+	Text string
+}
+
+// Picker is the state of a filterable list with a header and a preview
+// pane, laid out like fzf --layout=reverse --preview-window=down:
+//
+//	prompt> query               2/5 (1)
+//	header or status
+//	column header
+//	>•current, marked item
+//	  other item
+//	──────────────
+//	preview
+//
+// Items can be marked for bulk actions, as with fzf --multi. It does no I/O:
+// callers feed it keys, items and preview text and draw the result of Render.
+type Picker struct {
+	// Prompt is shown before the query.
+	Prompt string
+	// Header is the help shown under the prompt, one or more lines.
+	Header string
+	// Columns is a non-selectable line above the items.
+	Columns string
+	// Status replaces Header when set, e.g. to report an error inline.
+	Status string
+	// PreviewPercent is the share of the screen given to the preview pane.
+	PreviewPercent int
+
+	items    []Item
+	filtered []int // indexes into items matching the query
+	cursor   int   // index into filtered
+	offset   int   // first visible index into filtered
+	query    []rune
+	preview  []string
+	marked   map[string]bool // marked item IDs
+}
+
+// NewPicker returns an empty picker.
+func NewPicker(prompt, header string) *Picker {
+	return &Picker{Prompt: prompt, Header: header, PreviewPercent: 70}
+}
+
+// SetItems replaces the items, keeping the cursor and marks on the same IDs
+// if they are still present.
+func (p *Picker) SetItems(items []Item) {
+	sel, hadSel := p.Selected()
+	p.items = items
+	present := make(map[string]bool, len(items))
+	for _, it := range items {
+		present[it.ID] = true
+	}
+	for id := range p.marked {
+		if !present[id] {
+			delete(p.marked, id)
+		}
+	}
+	p.refilter()
+	if hadSel {
+		for i, idx := range p.filtered {
+			if p.items[idx].ID == sel.ID {
+				p.cursor = i
+				return
+			}
+		}
+	}
+}
+
+// Selected returns the item under the cursor.
+func (p *Picker) Selected() (Item, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.filtered) {
+		return Item{}, false
+	}
+	return p.items[p.filtered[p.cursor]], true
+}
+
+// Marked returns the marked items in list order, or the item under the
+// cursor when none are marked, like fzf's {+} placeholder.
+func (p *Picker) Marked() []Item {
+	var out []Item
+	for _, it := range p.items {
+		if p.marked[it.ID] {
+			out = append(out, it)
+		}
+	}
+	if len(out) == 0 {
+		if sel, ok := p.Selected(); ok {
+			out = append(out, sel)
+		}
+	}
+	return out
+}
+
+// Toggle flips the mark on the item under the cursor.
+func (p *Picker) Toggle() {
+	if sel, ok := p.Selected(); ok {
+		p.toggle(sel.ID)
+	}
+}
+
+// ToggleAll flips the mark on every item matching the query.
+func (p *Picker) ToggleAll() {
+	for _, idx := range p.filtered {
+		p.toggle(p.items[idx].ID)
+	}
+}
+
+func (p *Picker) toggle(id string) {
+	if p.marked == nil {
+		p.marked = make(map[string]bool)
+	}
+	if p.marked[id] {
+		delete(p.marked, id)
+	} else {
+		p.marked[id] = true
+	}
+}
+
+// ClearMarks unmarks every item.
+func (p *Picker) ClearMarks() {
+	p.marked = nil
+}
+
+// Query returns the current filter text.
+func (p *Picker) Query() string {
+	return string(p.query)
+}
+
+// SetPreview sets the preview pane content.
+func (p *Picker) SetPreview(text string) {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		p.preview = nil
+		return
+	}
+	p.preview = strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+}
+
+// HandleKey applies navigation and query editing keys. It returns false for
+// keys it does not handle, which the caller can bind to actions.
+func (p *Picker) HandleKey(key string) bool {
+	switch key {
+	case "up", "ctrl-k", "ctrl-p":
+		p.move(-1)
+	case "down", "ctrl-j", "ctrl-n":
+		p.move(1)
+	case "pgup":
+		p.move(-10)
+	case "pgdn":
+		p.move(10)
+	case "home":
+		p.move(-len(p.filtered))
+	case "end":
+		p.move(len(p.filtered))
+	case "ctrl-space":
+		p.Toggle()
+		p.move(1)
+	case "btab":
+		p.Toggle()
+		p.move(-1)
+	case "backspace":
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.refilter()
+		}
+	case "ctrl-u":
+		p.query = nil
+		p.refilter()
+	case "ctrl-w":
+		q := strings.TrimRight(string(p.query), " ")
+		if i := strings.LastIndex(q, " "); i >= 0 {
+			p.query = []rune(q[:i+1])
+		} else {
+			p.query = nil
+		}
+		p.refilter()
+	default:
+		if !IsPrintable(key) {
+			return false
+		}
+		p.query = append(p.query, []rune(key)...)
+		p.refilter()
+	}
+	return true
+}
+
+func (p *Picker) move(delta int) {
+	p.cursor = max(0, min(len(p.filtered)-1, p.cursor+delta))
+}
+
+// refilter recomputes the visible items. Every space-separated term of the
+// query must appear in the item text, case-insensitively.
+func (p *Picker) refilter() {
+	terms := strings.Fields(strings.ToLower(string(p.query)))
+	p.filtered = p.filtered[:0]
+	for i, it := range p.items {
+		text := strings.ToLower(it.Text)
+		ok := true
+		for _, t := range terms {
+			if !strings.Contains(text, t) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			p.filtered = append(p.filtered, i)
+		}
+	}
+	p.move(0)
+}
+
+// Render returns the full screen for a terminal of the given size, as
+// ANSI text that starts at the top-left corner.
+func (p *Picker) Render(width, height int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	var lines []string
+	line := func(s string) {
+		lines = append(lines, s+"\x1b[K")
+	}
+
+	// Prompt with a block cursor and the match count on the right.
+	count := fmt.Sprintf("%d/%d", len(p.filtered), len(p.items))
+	if len(p.marked) > 0 {
+		count += fmt.Sprintf(" (%d)", len(p.marked))
+	}
+	prompt := Truncate(p.Prompt+string(p.query), width-len(count)-2)
+	pad := width - StringWidth(prompt) - 1 - len(count)
+	line(prompt + "\x1b[7m \x1b[0m" + strings.Repeat(" ", max(pad, 1)) + "\x1b[2m" + count + "\x1b[0m")
+
+	used := 1
+	if p.Status != "" {
+		line("\x1b[33m" + Truncate(p.Status, width) + "\x1b[0m")
+		used++
+	} else {
+		for _, h := range strings.Split(p.Header, "\n") {
+			line("\x1b[2m" + Truncate(h, width) + "\x1b[0m")
+			used++
+		}
+	}
+	if p.Columns != "" {
+		line("\x1b[1m  " + Truncate(p.Columns, width-2) + "\x1b[0m")
+		used++
+	}
+
+	previewHeight := 0
+	if p.PreviewPercent > 0 {
+		previewHeight = (height - used) * p.PreviewPercent / 100
+	}
+	listHeight := max(height-used-previewHeight-1, 1)
+	previewHeight = max(height-used-listHeight-1, 0)
+
+	// Keep the cursor visible.
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+listHeight {
+		p.offset = p.cursor - listHeight + 1
+	}
+	for i := 0; i < listHeight; i++ {
+		idx := p.offset + i
+		if idx >= len(p.filtered) {
+			line("")
+			continue
+		}
+		it := p.items[p.filtered[idx]]
+		text := Truncate(it.Text, width-2)
+		mark := " "
+		if p.marked[it.ID] {
+			mark = "•"
+		}
+		if idx == p.cursor {
+			line("\x1b[1;7m>" + mark + "\x1b[0;7m" + text + strings.Repeat(" ", max(width-2-StringWidth(text), 0)) + "\x1b[0m")
+		} else {
+			line(" \x1b[1m" + mark + "\x1b[0m" + text)
+		}
+	}
+
+	if previewHeight > 0 {
+		line("\x1b[2m" + strings.Repeat("─", width) + "\x1b[0m")
+		shown := 0
+		for _, l := range p.preview {
+			for _, w := range Wrap(l, width) {
+				if shown == previewHeight {
+					break
+				}
+				line(w)
+				shown++
+			}
+		}
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	// No newline after the last line: it would scroll the screen.
+	return "\x1b[H" + strings.Join(lines, "\r\n") + "\x1b[J"
+}
+
+// RuneWidth returns the number of terminal cells used by r: 2 for wide
+// East Asian characters and emoji, 0 for control characters, 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r < ' ' || r == 0x7f:
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f,
+		r >= 0x1f900 && r <= 0x1f9ff:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// StringWidth returns the number of terminal cells used by s.
+func StringWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += RuneWidth(r)
+	}
+	return w
+}
+
+// Truncate cuts s to at most width cells, ending with "…" when cut.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if StringWidth(s) <= width {
+		return s
+	}
+	w := 0
+	for i, r := range s {
+		rw := RuneWidth(r)
+		if w+rw > width-1 {
+			return s[:i] + "…"
+		}
+		w += rw
+	}
+	return s
+}
+
+// Wrap splits s into lines of at most width cells.
+func Wrap(s string, width int) []string {
+	if width <= 0 || s == "" {
+		return []string{s}
+	}
+	var lines []string
+	start, w := 0, 0
+	for i, r := range s {
+		rw := RuneWidth(r)
+		if w+rw > width {
+			lines = append(lines, s[start:i])
+			start, w = i, 0
+		}
+		w += rw
+	}
+	return append(lines, s[start:])
+}
@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// screen renders p and returns its plain-text lines.
+func screen(p *Picker, w, h int) []string {
+	return strings.Split(ansiRe.ReplaceAllString(p.Render(w, h), ""), "\r\n")
+}
+
+func testItems() []Item {
+	return []Item{
+		{ID: "a", Text: "PERM  ~/git/api     main"},
+		{ID: "b", Text: "IDLE  ~/git/web     feature"},
+		{ID: "c", Text: "DONE  ~/git/api-v2  main"},
+	}
+}
+
+func TestPicker_Navigation(t *testing.T) {
+	p := NewPicker("> ", "help")
+	p.SetItems(testItems())
+
+	if sel, _ := p.Selected(); sel.ID != "a" {
+		t.Fatalf("initial selection = %q, want a", sel.ID)
+	}
+	p.HandleKey("down")
+	p.HandleKey("ctrl-n")
+	p.HandleKey("down") // clamped at the end
+	if sel, _ := p.Selected(); sel.ID != "c" {
+		t.Errorf("after down x3 = %q, want c", sel.ID)
+	}
+	p.HandleKey("home")
+	if sel, _ := p.Selected(); sel.ID != "a" {
+		t.Errorf("after home = %q, want a", sel.ID)
+	}
+	if p.HandleKey("enter") {
+		t.Error("enter should be left to the caller")
+	}
+}
+
+func TestPicker_Filter(t *testing.T) {
+	p := NewPicker("> ", "help")
+	p.SetItems(testItems())
+
+	for _, k := range []string{"A", "p", "i", " ", "m", "a", "i", "n"} {
+		p.HandleKey(k)
+	}
+	if got := p.Query(); got != "Api main" {
+		t.Fatalf("query = %q", got)
+	}
+	// Both terms must match, case-insensitively.
+	var ids []string
+	for p.HandleKey("home"); ; p.HandleKey("down") {
+		sel, _ := p.Selected()
+		if len(ids) > 0 && ids[len(ids)-1] == sel.ID {
+			break
+		}
+		ids = append(ids, sel.ID)
+	}
+	if strings.Join(ids, ",") != "a,c" {
+		t.Errorf("filtered = %v, want a,c", ids)
+	}
+
+	p.HandleKey("ctrl-w")
+	if got := p.Query(); got != "Api " {
+		t.Errorf("after ctrl-w query = %q, want %q", got, "Api ")
+	}
+	p.HandleKey("ctrl-u")
+	p.HandleKey("z")
+	p.HandleKey("z")
+	if _, ok := p.Selected(); ok {
+		t.Error("expected no selection when nothing matches")
+	}
+	p.HandleKey("backspace")
+	p.HandleKey("backspace")
+	if _, ok := p.Selected(); !ok {
+		t.Error("expected a selection after clearing the query")
+	}
+}
+
+func TestPicker_SetItemsKeepsSelection(t *testing.T) {
+	p := NewPicker("> ", "help")
+	p.SetItems(testItems())
+	p.HandleKey("down") // b
+
+	items := testItems()
+	items[0], items[2] = items[2], items[0]
+	p.SetItems(append([]Item{{ID: "new", Text: "ASK ~/x"}}, items...))
+	if sel, _ := p.Selected(); sel.ID != "b" {
+		t.Errorf("selection after reload = %q, want b", sel.ID)
+	}
+
+	p.SetItems([]Item{{ID: "x", Text: "x"}})
+	if sel, _ := p.Selected(); sel.ID != "x" {
+		t.Errorf("selection after removal = %q, want x", sel.ID)
+	}
+}
+
+func TestPicker_Render(t *testing.T) {
+	p := NewPicker("Jump> ", "enter=jump")
+	p.Columns = "EVENT PATH"
+	p.SetItems(testItems())
+	p.HandleKey("down")
+	p.SetPreview("line one\nline two")
+
+	lines := screen(p, 40, 12)
+	if len(lines) > 12 {
+		t.Fatalf("rendered %d lines, want at most 12:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	if !strings.HasPrefix(lines[0], "Jump> ") || !strings.HasSuffix(lines[0], "3/3") {
+		t.Errorf("prompt line = %q", lines[0])
+	}
+	if lines[1] != "enter=jump" {
+		t.Errorf("header line = %q", lines[1])
+	}
+	if lines[2] != "  EVENT PATH" {
+		t.Errorf("columns line = %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[4], "> IDLE") {
+		t.Errorf("selected line = %q, want cursor on IDLE", lines[4])
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "line two") {
+		t.Errorf("preview missing:\n%s", strings.Join(lines, "\n"))
+	}
+
+	p.Status = "⚠ boom"
+	if lines := screen(p, 40, 12); lines[1] != "⚠ boom" {
+		t.Errorf("status line = %q", lines[1])
+	}
+}
+
+func TestPicker_RenderScrollsToCursor(t *testing.T) {
+	p := NewPicker("> ", "h")
+	p.PreviewPercent = 0
+	var items []Item
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		items = append(items, Item{ID: id, Text: "item " + id})
+	}
+	p.SetItems(items)
+	p.HandleKey("end")
+
+	lines := screen(p, 20, 5) // prompt + header + 2 rows + separator
+	if !strings.HasPrefix(lines[3], "> item 6") {
+		t.Errorf("last visible row = %q, want cursor on item 6:\n%s", lines[3], strings.Join(lines, "\n"))
+	}
+}
+
+func TestTruncateAndWrap(t *testing.T) {
+	if got := Truncate("hello world", 8); got != "hello w…" {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := Truncate("short", 8); got != "short" {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := StringWidth("🤖 ok"); got != 5 {
+		t.Errorf("StringWidth = %d, want 5", got)
+	}
+	if got := Wrap("abcdefg", 3); strings.Join(got, "|") != "abc|def|g" {
+		t.Errorf("Wrap = %q", got)
+	}
+}
+
+func TestPicker_Marks(t *testing.T) {
+	p := NewPicker("> ", "h")
+	p.SetItems(testItems())
+
+	if got := p.Marked(); len(got) != 1 || got[0].ID != "a" {
+		t.Fatalf("Marked with no marks = %v, want the current item", got)
+	}
+
+	p.HandleKey("down")
+	p.HandleKey("ctrl-space") // marks b, moves to c
+	p.HandleKey("ctrl-space") // marks c
+	ids := func() string {
+		var out []string
+		for _, it := range p.Marked() {
+			out = append(out, it.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(); got != "b,c" {
+		t.Errorf("Marked = %s, want b,c", got)
+	}
+	if lines := screen(p, 40, 10); !strings.HasSuffix(lines[0], "3/3 (2)") {
+		t.Errorf("prompt line = %q, want marked count", lines[0])
+	}
+
+	// Marks survive reloads but not removal.
+	p.SetItems(testItems()[:2])
+	if got := ids(); got != "b" {
+		t.Errorf("Marked after reload = %s, want b", got)
+	}
+
+	p.ToggleAll()
+	if got := ids(); got != "a" {
+		t.Errorf("Marked after ToggleAll = %s, want a", got)
+	}
+	p.ClearMarks()
+	if got := p.Marked(); len(got) != 1 {
+		t.Errorf("Marked after ClearMarks = %v, want the current item", got)
+	}
+}
@@ -0,0 +1,96 @@
+// Package tui implements a small terminal picker without external
+// dependencies: raw-mode TTY handling, key decoding and a list+preview model.
+package tui
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Terminal is the controlling TTY in raw mode.
+type Terminal struct {
+	f    *os.File
+	orig syscall.Termios
+}
+
+// Open opens /dev/tty, switches it to raw mode and to the alternate screen.
+// Close restores the previous state.
+func Open() (*Terminal, error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	t := &Terminal{f: f}
+	if err := ioctl(f.Fd(), ioctlGetTermios, unsafe.Pointer(&t.orig)); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	raw := t.orig
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(f.Fd(), ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// Alternate screen, hidden cursor.
+	f.WriteString("\x1b[?1049h\x1b[?25l")
+	return t, nil
+}
+
+// Close leaves the alternate screen and restores the original TTY mode.
+func (t *Terminal) Close() error {
+	t.f.WriteString("\x1b[?25h\x1b[?1049l")
+	err := ioctl(t.f.Fd(), ioctlSetTermios, unsafe.Pointer(&t.orig))
+	t.f.Close()
+	return err
+}
+
+// Size returns the terminal width and height in cells.
+func (t *Terminal) Size() (width, height int, err error) {
+	var ws struct{ Row, Col, X, Y uint16 }
+	if err := ioctl(t.f.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// Write writes raw bytes to the terminal.
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.f.Write(p)
+}
+
+// Keys starts reading the terminal and returns a channel of key names
+// (see ParseKeys). The channel is closed when reading fails.
+func (t *Terminal) Keys() <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		buf := make([]byte, 256)
+		for {
+			n, err := t.f.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, k := range ParseKeys(buf[:n]) {
+				ch <- k
+			}
+		}
+	}()
+	return ch
+}
+
+func ioctl(fd uintptr, req uint, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(req), uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
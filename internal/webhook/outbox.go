@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// MaxAttempts is how many times a message is sent before it is dropped.
+const MaxAttempts = 8
+
+// maxBackoff caps the wait between two attempts.
+const maxBackoff = 5 * time.Minute
+
+// sendTimeout bounds a single delivery attempt.
+const sendTimeout = 10 * time.Second
+
+// entry is a message in the outbox with its delivery state.
+type entry struct {
+	Message     Message   `json:"message"`
+	Attempts    int       `json:"attempts,omitempty"`
+	NextAttempt time.Time `json:"next_attempt,omitzero"`
+}
+
+// Outbox is a directory of messages waiting to be delivered.
+type Outbox struct {
+	Dir    string
+	Client *http.Client
+	// Backoff returns the wait after a failed attempt, given the number of
+	// attempts so far.
+	Backoff func(attempts int) time.Duration
+}
+
+// NewOutbox returns the outbox in dir, with exponential backoff from one
+// second.
+func NewOutbox(dir string) *Outbox {
+	return &Outbox{
+		Dir:    dir,
+		Client: &http.Client{Timeout: sendTimeout},
+		Backoff: func(attempts int) time.Duration {
+			return min(time.Second<<(attempts-1), maxBackoff)
+		},
+	}
+}
+
+// Add stores messages for delivery.
+func (o *Outbox) Add(msgs ...Message) error {
+	if err := os.MkdirAll(o.Dir, 0755); err != nil {
+		return err
+	}
+	now := time.Now().UnixNano()
+	for i, m := range msgs {
+		data, err := json.Marshal(entry{Message: m})
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%d-%d-%d.json", now, os.Getpid(), i)
+		if err := queue.WriteFileAtomic(filepath.Join(o.Dir, name), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the paths of the messages in the outbox, oldest first.
+func (o *Outbox) Pending() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(o.Dir, "[^.]*.json"))
+	sort.Strings(paths)
+	return paths, err
+}
+
+// Run delivers the messages in the outbox, retrying failures with backoff,
+// until the outbox is empty or ctx is done. If another Run is already
+// delivering, it returns right away and leaves the messages to it.
+func (o *Outbox) Run(ctx context.Context) error {
+	for {
+		unlock, ok, err := o.lock()
+		if err != nil || !ok {
+			return err
+		}
+		err = o.drain(ctx)
+		unlock()
+		if err != nil {
+			return err
+		}
+		// A message added after the last pass, whose own Run gave up on the
+		// lock we held, is ours to deliver.
+		if paths, err := o.Pending(); err != nil || len(paths) == 0 {
+			return err
+		}
+	}
+}
+
+// lock takes the outbox lock without waiting. ok is false when another
+// process holds it.
+func (o *Outbox) lock() (unlock func(), ok bool, err error) {
+	if err := os.MkdirAll(o.Dir, 0755); err != nil {
+		return nil, false, err
+	}
+	return queue.TryLock(filepath.Join(o.Dir, ".lock"))
+}
+
+// drain makes passes over the outbox until it is empty, sleeping until the
+// next retry is due in between.
+func (o *Outbox) drain(ctx context.Context) error {
+	for {
+		paths, err := o.Pending()
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		var next time.Time
+		for _, p := range paths {
+			due, err := o.attempt(ctx, p)
+			if err != nil {
+				return err
+			}
+			if !due.IsZero() && (next.IsZero() || due.Before(next)) {
+				next = due
+			}
+		}
+		if next.IsZero() {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
+
+// attempt sends the message at path if it is due. It returns when the
+// message is due next, or the zero time once it is delivered or dropped.
+func (o *Outbox) attempt(ctx context.Context, path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, nil // delivered and removed meanwhile
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		queue.Debugf("WEBHOOK dropping unreadable %s: %v", filepath.Base(path), err)
+		return time.Time{}, os.Remove(path)
+	}
+	if now := time.Now(); now.Before(e.NextAttempt) {
+		return e.NextAttempt, nil
+	}
+
+	err = Send(ctx, o.Client, e.Message)
+	if err == nil {
+		queue.Debugf("WEBHOOK %s delivered", e.Message.Webhook)
+		return time.Time{}, os.Remove(path)
+	}
+	if ctx.Err() != nil {
+		return time.Time{}, ctx.Err()
+	}
+	e.Attempts++
+	var perm *permanentError
+	if errors.As(err, &perm) || e.Attempts >= MaxAttempts {
+		queue.Debugf("WEBHOOK %s dropped after %d attempts: %v", e.Message.Webhook, e.Attempts, err)
+		return time.Time{}, os.Remove(path)
+	}
+	e.NextAttempt = time.Now().Add(o.Backoff(e.Attempts))
+	queue.Debugf("WEBHOOK %s attempt %d failed, retrying at %s: %v", e.Message.Webhook, e.Attempts, e.NextAttempt.Format(time.TimeOnly), err)
+	if data, err = json.Marshal(e); err != nil {
+		return time.Time{}, err
+	}
+	return e.NextAttempt, queue.WriteFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testOutbox returns an outbox in a temp dir retrying after a millisecond.
+func testOutbox(t *testing.T, srv *httptest.Server) *Outbox {
+	t.Helper()
+	o := NewOutbox(t.TempDir())
+	o.Client = srv.Client()
+	o.Backoff = func(int) time.Duration { return time.Millisecond }
+	return o
+}
+
+// statusServer answers with the given statuses in turn, then 200, and
+// counts requests.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1)) - 1
+		if i < len(statuses) {
+			w.WriteHeader(statuses[i])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func pendingCount(t *testing.T, o *Outbox) int {
+	t.Helper()
+	paths, err := o.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(paths)
+}
+
+func TestOutbox_Delivers(t *testing.T) {
+	srv, requests := statusServer(t)
+	o := testOutbox(t, srv)
+	m := Message{URL: srv.URL, Method: http.MethodPost, Body: "hi"}
+	if err := o.Add(m, m); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if n := pendingCount(t, o); n != 2 {
+		t.Fatalf("pending = %d, want 2", n)
+	}
+
+	if err := o.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+	if n := pendingCount(t, o); n != 0 {
+		t.Errorf("pending after Run = %d, want 0", n)
+	}
+}
+
+func TestOutbox_RetriesWithBackoff(t *testing.T) {
+	srv, requests := statusServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	o := testOutbox(t, srv)
+	var backoffs []int
+	o.Backoff = func(attempts int) time.Duration {
+		backoffs = append(backoffs, attempts)
+		return time.Millisecond
+	}
+	if err := o.Add(Message{URL: srv.URL, Method: http.MethodPost}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if len(backoffs) != 2 || backoffs[0] != 1 || backoffs[1] != 2 {
+		t.Errorf("backoff attempts = %v, want [1 2]", backoffs)
+	}
+	if n := pendingCount(t, o); n != 0 {
+		t.Errorf("pending after Run = %d, want 0", n)
+	}
+}
+
+func TestOutbox_Drops(t *testing.T) {
+	t.Run("permanent failure", func(t *testing.T) {
+		srv, requests := statusServer(t, http.StatusBadRequest)
+		o := testOutbox(t, srv)
+		if err := o.Add(Message{URL: srv.URL, Method: http.MethodPost}); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.Run(context.Background()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("requests = %d, want 1", n)
+		}
+		if n := pendingCount(t, o); n != 0 {
+			t.Errorf("pending = %d, want 0", n)
+		}
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		statuses := make([]int, MaxAttempts+5)
+		for i := range statuses {
+			statuses[i] = http.StatusInternalServerError
+		}
+		srv, requests := statusServer(t, statuses...)
+		o := testOutbox(t, srv)
+		if err := o.Add(Message{URL: srv.URL, Method: http.MethodPost}); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.Run(context.Background()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if n := requests.Load(); n != MaxAttempts {
+			t.Errorf("requests = %d, want %d", n, MaxAttempts)
+		}
+		if n := pendingCount(t, o); n != 0 {
+			t.Errorf("pending = %d, want 0", n)
+		}
+	})
+}
+
+func TestOutbox_LeavesMessagesToLockHolder(t *testing.T) {
+	srv, requests := statusServer(t)
+	o := testOutbox(t, srv)
+	if err := o.Add(Message{URL: srv.URL, Method: http.MethodPost}); err != nil {
+		t.Fatal(err)
+	}
+	unlock, ok, err := o.lock()
+	if err != nil || !ok {
+		t.Fatalf("lock() = %v, %v", ok, err)
+	}
+	defer unlock()
+
+	if err := o.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("requests = %d, want 0 while another Run holds the lock", n)
+	}
+	if n := pendingCount(t, o); n != 1 {
+		t.Errorf("pending = %d, want 1", n)
+	}
+}
+
+func TestOutbox_StopsWithContext(t *testing.T) {
+	srv, _ := statusServer(t, http.StatusInternalServerError)
+	o := testOutbox(t, srv)
+	o.Backoff = func(int) time.Duration { return time.Hour }
+	if err := o.Add(Message{URL: srv.URL, Method: http.MethodPost}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := o.Run(ctx); err == nil {
+		t.Error("expected the context error")
+	}
+	// The message stays for the next run.
+	if n := pendingCount(t, o); n != 1 {
+		t.Errorf("pending = %d, want 1", n)
+	}
+}
+
+func TestNewOutbox_Backoff(t *testing.T) {
+	o := NewOutbox(t.TempDir())
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, w := range want {
+		if got := o.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	if got := o.Backoff(30); got != maxBackoff {
+		t.Errorf("Backoff(30) = %v, want %v", got, maxBackoff)
+	}
+}
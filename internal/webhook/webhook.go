@@ -0,0 +1,128 @@
+// Package webhook renders entries into HTTP requests and delivers them from
+// an on-disk outbox, so hooks never wait on the network.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+// DefaultBody is the body template used when a webhook sets none.
+const DefaultBody = `{{label .Event}} {{shortpath .CWD}}{{with .Message}}: {{.}}{{end}}`
+
+// Message is a rendered webhook request waiting in the outbox.
+type Message struct {
+	// Webhook is the name of the webhook, for logs.
+	Webhook string            `json:"webhook,omitempty"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// funcs are the template functions available to webhook bodies.
+var funcs = template.FuncMap{
+	"label":     queue.EventLabel,
+	"shortpath": queue.ShortenPath,
+	"base":      filepath.Base,
+	// json quotes a value for use inside a JSON body.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Render builds the request of w for entry e.
+func Render(w queue.Webhook, e *queue.Entry) (Message, error) {
+	if w.URL == "" {
+		return Message{}, fmt.Errorf("webhook %q: no url", w.Name)
+	}
+	body := w.Body
+	if body == "" {
+		body = DefaultBody
+	}
+	tmpl, err := template.New(w.Name).Funcs(funcs).Parse(body)
+	if err != nil {
+		return Message{}, fmt.Errorf("webhook %q: %w", w.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return Message{}, fmt.Errorf("webhook %q: %w", w.Name, err)
+	}
+
+	m := Message{
+		Webhook: w.Name,
+		URL:     w.URL,
+		Method:  strings.ToUpper(w.Method),
+		Headers: make(map[string]string, len(w.Headers)+1),
+		Body:    buf.String(),
+	}
+	if m.Method == "" {
+		m.Method = http.MethodPost
+	}
+	for k, v := range w.Headers {
+		m.Headers[k] = v
+	}
+	if !hasHeader(m.Headers, "Content-Type") {
+		if json.Valid(buf.Bytes()) {
+			m.Headers["Content-Type"] = "application/json"
+		} else {
+			m.Headers["Content-Type"] = "text/plain; charset=utf-8"
+		}
+	}
+	return m, nil
+}
+
+// hasHeader reports whether headers sets name, in any case.
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// permanentError is a failure retrying won't fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Send makes the request of m. A 4xx response other than 408 and 429 is a
+// permanent error.
+func Send(ctx context.Context, client *http.Client, m Message) error {
+	req, err := http.NewRequestWithContext(ctx, m.Method, m.URL, strings.NewReader(m.Body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	for k, v := range m.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("%s %s: %s", m.Method, m.URL, resp.Status)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/duboisf/cc-queue/internal/queue"
+)
+
+var testEntry = &queue.Entry{
+	SessionID: "sess-a",
+	CWD:       "/srv/infra",
+	Event:     "permission_prompt",
+	Message:   `Claude needs your permission to use "Bash"`,
+}
+
+func TestRender_DefaultBody(t *testing.T) {
+	m, err := Render(queue.Webhook{Name: "ntfy", URL: "https://ntfy.sh/cc"}, testEntry)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if m.Method != http.MethodPost {
+		t.Errorf("method = %q, want POST", m.Method)
+	}
+	if want := `PERM /srv/infra: Claude needs your permission to use "Bash"`; m.Body != want {
+		t.Errorf("body = %q, want %q", m.Body, want)
+	}
+	if got := m.Headers["Content-Type"]; got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/plain", got)
+	}
+}
+
+func TestRender_Template(t *testing.T) {
+	w := queue.Webhook{
+		Name:    "slack",
+		URL:     "https://hooks.slack.com/services/x",
+		Method:  "put",
+		Headers: map[string]string{"Authorization": "Bearer t"},
+		Body:    `{"text": {{json (printf "%s %s: %s" (label .Event) (base .CWD) .Message)}}}`,
+	}
+	m, err := Render(w, testEntry)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := `{"text": "PERM infra: Claude needs your permission to use \"Bash\""}`; m.Body != want {
+		t.Errorf("body = %s, want %s", m.Body, want)
+	}
+	if m.Method != http.MethodPut {
+		t.Errorf("method = %q, want PUT", m.Method)
+	}
+	if m.Headers["Authorization"] != "Bearer t" || m.Headers["Content-Type"] != "application/json" {
+		t.Errorf("headers = %v", m.Headers)
+	}
+
+	// A Content-Type from the config wins, whatever its case.
+	w.Headers = map[string]string{"content-type": "application/x-custom"}
+	if m, err = Render(w, testEntry); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if len(m.Headers) != 1 || m.Headers["content-type"] != "application/x-custom" {
+		t.Errorf("headers = %v", m.Headers)
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		w    queue.Webhook
+	}{
+		{"no url", queue.Webhook{Name: "x"}},
+		{"bad template", queue.Webhook{URL: "http://x", Body: "{{.Event"}},
+		{"unknown field", queue.Webhook{URL: "http://x", Body: "{{.Nope}}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Render(tt.w, testEntry); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	var gotMethod, gotBody, gotHeader string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Get("X-Token")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	m := Message{URL: srv.URL, Method: http.MethodPost, Headers: map[string]string{"X-Token": "t"}, Body: "hi"}
+
+	if err := Send(context.Background(), srv.Client(), m); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotBody != "hi" || gotHeader != "t" {
+		t.Errorf("request = %s %q X-Token=%q", gotMethod, gotBody, gotHeader)
+	}
+
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusRequestTimeout, false},
+		{http.StatusNotFound, true},
+		{http.StatusUnauthorized, true},
+	}
+	for _, tt := range tests {
+		status = tt.status
+		err := Send(context.Background(), srv.Client(), m)
+		if err == nil {
+			t.Errorf("status %d: expected an error", tt.status)
+			continue
+		}
+		var perm *permanentError
+		if got := errors.As(err, &perm); got != tt.permanent {
+			t.Errorf("status %d: permanent = %v, want %v", tt.status, got, tt.permanent)
+		}
+	}
+}